@@ -1,8 +1,6 @@
 // Package badge provides functions for generating SVG badges.
 package badge
 
-//go:generate go get github.com/golang/freetype/truetype
-//go:generate go get golang.org/x/image/math/fixed
 //go:generate go run gen.go
 
 import (
@@ -10,6 +8,8 @@ import (
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -23,6 +23,8 @@ const (
 	FlatStyle        Style = "flat"
 	PlasticStyle     Style = "plastic"
 	SemaphoreCIStyle Style = "semaphoreci"
+	ForTheBadgeStyle Style = "for-the-badge"
+	SocialStyle      Style = "social"
 )
 
 const (
@@ -32,8 +34,8 @@ const (
 	DefaultStyle Style = ClassicStyle
 )
 
-// SupportedStyles contains a list of all supported badge styles
-var SupportedStyles = [...]Style{ClassicStyle, FlatStyle, PlasticStyle, SemaphoreCIStyle}
+// SupportedStyles contains a list of all built-in badge styles (see `Styles` for registered styles)
+var SupportedStyles = [...]Style{ClassicStyle, FlatStyle, PlasticStyle, SemaphoreCIStyle, ForTheBadgeStyle, SocialStyle}
 
 // Params holds badge parameters
 type Params struct {
@@ -45,231 +47,426 @@ type Params struct {
 	// Valid color values includes CSS color names (up to CSS Color Module Level 3) or HEX values (eg. "coral", "#1bacbf", "1bacbf", "fff", "#fff")
 	Color string
 	// Icon determines whether the badge should include icons or not (eg. "brands/docker", "regular/credit-card", "solid/anchor")
+	// Registered icons are also supported (see `RegisterIcon`).
 	Icon string
+	// Logo determines an ad-hoc icon of the badge as a base64 SVG data URI ("data:image/svg+xml;base64,<BASE64_SVG>"),
+	// which is sanitized (see `SanitizeIcon`) & overrides `Icon`
+	Logo string
 	// Style determines the visual style of the badge
 	Style Style
+	// FontFamily determines the font family of the badge texts, from the built-in & registered font families (see
+	// `Fonts`). Defaults to the font family of the style.
+	FontFamily string
+	// FontSize determines the font size (in px) of the badge texts, between `MinFontSize` & `MaxFontSize`. Defaults
+	// to the font size of the style.
+	FontSize int
+	// Link determines the URL opened by clicking the badge. Only absolute "http" & "https" URLs are supported.
+	//
+	// Links are rendered as `<a>` elements wrapping the groups they apply to: the whole badge for `Link`, & the
+	// group of the subject (or status) for `SubjectLink` (or `StatusLink`), nested in the link of the badge & taking
+	// precedence over it. Each segment of the badge is a group holding its shapes & texts, so that badges render the
+	// same with or without links.
+	Link string
+	// SubjectLink determines the URL opened by clicking the subject of the badge, overriding `Link` (see `Link`).
+	SubjectLink string
+	// StatusLink determines the URL opened by clicking the status of the badge, overriding `Link` (see `Link`).
+	StatusLink string
+}
+
+// HasIcon reports whether an icon with the given name is available (eg. "brands/docker", "custom/our-logo")
+func HasIcon(name string) bool {
+	_, ok := lookupIcon(name)
+	return ok
 }
 
 // badgeDimensions holds dimensions required for generating SVG badge
 type badgeDimensions struct {
-	Style        Style
-	Template     *template.Template
-	Color        string
-	FontFamily   string
-	FontSize     int
-	PaddingInner int
-	PaddingOuter int
-	TotalWidth   int
-
-	Status          string
-	StatusFontColor string
-	StatusOffset    int
-	StatusTextWidth int
-	StatusWidth     int
-
-	Subject          string
-	SubjectFontColor string
-	SubjectOffset    int
-	SubjectTextWidth int
-	SubjectWidth     int
+	Style         Style
+	Template      *template.Template
+	Color         string
+	FontFamily    string
+	FontSize      int
+	LetterSpacing int
+	Uppercase     bool
+	Height        int
+	PaddingInner  int
+	PaddingOuter  int
+	Gap           int
+	TotalWidth    int
+	Link          string
+	Segments      []badgeSegment
+
+	Status           string
+	StatusFontColor  string
+	StatusFontWeight string
+	StatusLink       string
+	StatusOffset     int
+	StatusStart      int
+	StatusTextWidth  int
+	StatusWidth      int
+
+	Subject           string
+	SubjectColor      string
+	SubjectFontColor  string
+	SubjectFontWeight string
+	SubjectLink       string
+	SubjectOffset     int
+	SubjectTextWidth  int
+	SubjectWidth      int
 
 	IconLabel     string
 	IconBase64Str string
 	IconOffset    int
+	// IconSVG holds the SVG of the icon, for rasterizing PNG badges
+	IconSVG string
+	// Logo holds the sanitized logo data URI of the icon, if the icon is a logo
+	Logo string
 }
 
-// generateBadge converts badge parameters into dimensions for generating SVG badge
-func generateBadge(params *Params) (*badgeDimensions, error) {
-	badgeParams := params
-	if badgeParams == nil {
-		badgeParams = &Params{}
-	}
-	badgeColor := parseColor(badgeParams.Color)
-	if badgeColor == "" {
-		badgeColor = DefaultColor
-	}
-	badgeStyle := badgeParams.Style
-	if badgeStyle == Style("") {
-		badgeStyle = DefaultStyle
-	}
+// badgeSegment holds dimensions of a segment of a SVG badge. Label segments are styled like the subject of the
+// badge & other segments like its status.
+type badgeSegment struct {
+	PathID     string
+	TextID     string
+	LinkID     string
+	Label      bool
+	Text       string
+	Color      string
+	FontColor  string
+	FontWeight string
+	Link       string
+	Start      int
+	Width      int
+	TextOffset int
+	TextWidth  int
+}
 
-	var newBadge badgeDimensions
-	switch badgeStyle {
+// styleDimensions returns the dimensions shared by all badges of the given style
+func styleDimensions(style Style) badgeDimensions {
+	switch style {
 	case FlatStyle:
-		newBadge = badgeDimensions{
+		return badgeDimensions{
 			Style:            FlatStyle,
 			Template:         badgeTemplates[FlatStyle],
-			Color:            badgeColor,
 			FontFamily:       "Verdana",
 			FontSize:         11,
+			Height:           20,
 			PaddingInner:     4,
 			PaddingOuter:     6,
-			Status:           badgeParams.Status,
 			StatusFontColor:  "#fff",
-			Subject:          badgeParams.Subject,
+			SubjectColor:     "#555",
 			SubjectFontColor: "#fff",
 		}
 	case PlasticStyle:
-		newBadge = badgeDimensions{
+		return badgeDimensions{
 			Style:            PlasticStyle,
 			Template:         badgeTemplates[PlasticStyle],
-			Color:            badgeColor,
 			FontFamily:       "Verdana",
 			FontSize:         11,
+			Height:           20,
 			PaddingInner:     4,
 			PaddingOuter:     6,
-			Status:           badgeParams.Status,
 			StatusFontColor:  "#fff",
-			Subject:          badgeParams.Subject,
+			SubjectColor:     "#555",
 			SubjectFontColor: "#fff",
 		}
 	case SemaphoreCIStyle:
-		newBadge = badgeDimensions{
+		return badgeDimensions{
 			Style:            SemaphoreCIStyle,
 			Template:         badgeTemplates[SemaphoreCIStyle],
-			Color:            badgeColor,
 			FontFamily:       "Verdana",
 			FontSize:         9,
+			Uppercase:        true,
+			Height:           20,
 			PaddingInner:     10,
 			PaddingOuter:     10,
-			Status:           strings.ToUpper(badgeParams.Status),
 			StatusFontColor:  "#fff",
-			Subject:          strings.ToUpper(badgeParams.Subject),
+			SubjectColor:     "#f1f1f1",
 			SubjectFontColor: "#888",
 		}
+	case ForTheBadgeStyle:
+		return badgeDimensions{
+			Style:             ForTheBadgeStyle,
+			Template:          badgeTemplates[ForTheBadgeStyle],
+			FontFamily:        "Verdana",
+			FontSize:          10,
+			LetterSpacing:     1,
+			Uppercase:         true,
+			Height:            28,
+			PaddingInner:      12,
+			PaddingOuter:      12,
+			StatusFontColor:   "#fff",
+			StatusFontWeight:  "bold",
+			SubjectColor:      "#555",
+			SubjectFontColor:  "#fff",
+			SubjectFontWeight: "bold",
+		}
+	case SocialStyle:
+		return badgeDimensions{
+			Style:             SocialStyle,
+			Template:          badgeTemplates[SocialStyle],
+			FontFamily:        "Verdana",
+			FontSize:          11,
+			Height:            20,
+			PaddingInner:      6,
+			PaddingOuter:      6,
+			Gap:               5,
+			StatusFontColor:   "#fff",
+			SubjectColor:      "#fcfcfc",
+			SubjectFontColor:  "#333",
+			SubjectFontWeight: "bold",
+		}
 	case ClassicStyle:
 		fallthrough
 	default:
-		newBadge = badgeDimensions{
+		newBadge := badgeDimensions{
 			Style:            ClassicStyle,
 			Template:         badgeTemplates[ClassicStyle],
-			Color:            badgeColor,
 			FontFamily:       "Verdana",
 			FontSize:         11,
+			Height:           20,
 			PaddingInner:     4,
 			PaddingOuter:     6,
-			Status:           badgeParams.Status,
 			StatusFontColor:  "#fff",
-			Subject:          badgeParams.Subject,
+			SubjectColor:     "#555",
 			SubjectFontColor: "#fff",
 		}
+		// Registered styles share the layout of the classic style
+		if tmpl := registeredTemplate(style); tmpl != nil {
+			newBadge.Style = style
+			newBadge.Template = tmpl
+		}
+		return newBadge
 	}
+}
 
-	subjectTextWidth, err := computeTextWidth(newBadge.Subject, newBadge.FontSize,
-		newBadge.FontFamily)
-	if err != nil {
-		return nil, err
+// setFont overrides the font family & size of the style with the given font family & size, if they are supported
+func (newBadge *badgeDimensions) setFont(fontFamily string, fontSize int) {
+	if fontFamily != "" {
+		if _, err := loadFontFamily(fontFamily); err == nil {
+			newBadge.FontFamily = fontFamily
+		}
+	}
+	if fontSize >= MinFontSize && fontSize <= MaxFontSize {
+		newBadge.FontSize = fontSize
 	}
+}
 
-	statusTextWidth, err := computeTextWidth(newBadge.Status, newBadge.FontSize,
-		newBadge.FontFamily)
-	if err != nil {
-		return nil, err
+// layout places the segments of the badge side by side, with the logo or icon (if valid) in the first segment.
+// Segments without colors are label segments.
+func (newBadge *badgeDimensions) layout(segments []Segment, icon string, logo string) error {
+	newBadge.Segments = make([]badgeSegment, len(segments))
+	for i, segment := range segments {
+		newSegment := badgeSegment{
+			Text:       segment.Text,
+			Color:      segment.Color,
+			FontColor:  newBadge.StatusFontColor,
+			FontWeight: newBadge.StatusFontWeight,
+			Link:       parseLink(segment.Link),
+		}
+		if newBadge.Uppercase {
+			newSegment.Text = strings.ToUpper(newSegment.Text)
+		}
+		newSegment.Text = isolateText(newSegment.Text)
+		if segment.Color == "" {
+			newSegment.Label = true
+			newSegment.Color = newBadge.SubjectColor
+			newSegment.FontColor = newBadge.SubjectFontColor
+			newSegment.FontWeight = newBadge.SubjectFontWeight
+		}
+		newBadge.Segments[i] = newSegment
 	}
 
-	if badgeParams.Icon != "" {
-		svgIcon, ok := fontAwesomeIcons[badgeParams.Icon]
-		if ok {
-			// Encode icon into a base64 string
-			modifiedSvgIcon := "<svg fill=\"" + newBadge.SubjectFontColor + "\"" + svgIcon[len("<svg"):]
-			newBadge.IconLabel = badgeParams.Icon
-			newBadge.IconBase64Str = base64.StdEncoding.EncodeToString([]byte(modifiedSvgIcon))
-			newBadge.IconOffset = 3 + 13 // IconPadding + IconSize
+	svgIcon, ok := lookupIcon(icon)
+	if logo != "" {
+		// Invalid logos are discarded like unknown icons
+		if logoIcon, err := parseLogo(logo); err == nil {
+			svgIcon, ok, icon = logoIcon, true, ""
+			newBadge.Logo = logoPrefix + base64.StdEncoding.EncodeToString([]byte(logoIcon))
 		}
 	}
+	if ok && len(newBadge.Segments) > 0 {
+		// Encode icon into a base64 string
+		modifiedSvgIcon := "<svg fill=\"" + newBadge.Segments[0].FontColor + "\"" + svgIcon[len("<svg"):]
+		newBadge.IconLabel = icon
+		newBadge.IconBase64Str = base64.StdEncoding.EncodeToString([]byte(modifiedSvgIcon))
+		newBadge.IconOffset = 3 + 13 // IconPadding + IconSize
+		newBadge.IconSVG = svgIcon
+	}
+
+	start := 0
+	for i := range newBadge.Segments {
+		segment := &newBadge.Segments[i]
+		textWidth, err := computeTextWidth(segment.Text, newBadge.FontSize, newBadge.FontFamily, segment.FontWeight)
+		if err != nil {
+			return err
+		}
+		textWidth += letterSpacingWidth(segment.Text, newBadge.LetterSpacing)
 
-	newBadge.SubjectOffset = newBadge.PaddingOuter + newBadge.IconOffset
-	newBadge.SubjectTextWidth = subjectTextWidth
-	newBadge.SubjectWidth = newBadge.SubjectOffset + subjectTextWidth + newBadge.PaddingInner
+		paddingStart, paddingEnd := newBadge.PaddingInner, newBadge.PaddingInner
+		if i == 0 {
+			paddingStart = newBadge.PaddingOuter + newBadge.IconOffset
+		} else {
+			start += newBadge.Gap
+		}
+		if i == len(newBadge.Segments)-1 {
+			paddingEnd = newBadge.PaddingOuter
+		}
 
-	newBadge.StatusOffset = newBadge.SubjectWidth + newBadge.PaddingInner
-	newBadge.StatusTextWidth = statusTextWidth
-	newBadge.StatusWidth = newBadge.PaddingInner + statusTextWidth + newBadge.PaddingOuter
+		segment.Start = start
+		segment.TextOffset = start + paddingStart
+		segment.TextWidth = textWidth
+		segment.Width = paddingStart + textWidth + paddingEnd
+		start += segment.Width
+	}
+	newBadge.TotalWidth = start
 
-	newBadge.TotalWidth = newBadge.SubjectWidth + newBadge.StatusWidth
+	return nil
+}
+
+// generateBadge converts badge parameters into dimensions for generating SVG badge
+func generateBadge(params *Params) (*badgeDimensions, error) {
+	badgeParams := params
+	if badgeParams == nil {
+		badgeParams = &Params{}
+	}
+	badgeColor := parseColor(badgeParams.Color)
+	if badgeColor == "" {
+		badgeColor = DefaultColor
+	}
+	badgeStyle := badgeParams.Style
+	if badgeStyle == Style("") {
+		badgeStyle = DefaultStyle
+	}
 
+	newBadge := styleDimensions(badgeStyle)
 	if newBadge.Template == nil {
-		return nil, fmt.Errorf("Badge template does not exist: %s", params.Style)
+		return nil, fmt.Errorf("Badge template does not exist: %s", badgeStyle)
 	}
+	newBadge.Color = badgeColor
+	newBadge.Link = parseLink(badgeParams.Link)
+	newBadge.setFont(badgeParams.FontFamily, badgeParams.FontSize)
+	if err := newBadge.layout([]Segment{
+		{Text: badgeParams.Subject, Link: badgeParams.SubjectLink},
+		{Text: badgeParams.Status, Color: badgeColor, Link: badgeParams.StatusLink},
+	}, badgeParams.Icon, badgeParams.Logo); err != nil {
+		return nil, err
+	}
+
+	subject, status := &newBadge.Segments[0], &newBadge.Segments[1]
+	subject.TextID, subject.LinkID = "subject", "subject-link"
+	status.PathID, status.TextID, status.LinkID = "fill", "status", "status-link"
+
+	newBadge.Subject = subject.Text
+	newBadge.SubjectLink = subject.Link
+	newBadge.SubjectOffset = subject.TextOffset
+	newBadge.SubjectTextWidth = subject.TextWidth
+	newBadge.SubjectWidth = subject.Width
+
+	newBadge.Status = status.Text
+	newBadge.StatusLink = status.Link
+	newBadge.StatusStart = status.Start
+	newBadge.StatusOffset = status.TextOffset
+	newBadge.StatusTextWidth = status.TextWidth
+	newBadge.StatusWidth = status.Width
 
 	return &newBadge, nil
 }
 
-// Create generates a SVG badge
-func Create(params *Params) (string, error) {
-	newBadge, err := generateBadge(params)
-	if err != nil {
-		return "", err
+// letterSpacingWidth returns the width added by spacing between the characters of the text
+func letterSpacingWidth(text string, letterSpacing int) int {
+	if length := len([]rune(text)); length > 1 {
+		return letterSpacing * (length - 1)
+	}
+	return 0
+}
+
+// escapeXML escapes text for XML character data & attribute values, replacing characters not allowed in XML
+func escapeXML(text string) string {
+	var buf strings.Builder
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// render executes the template of the badge. Text templates don't escape their data, so the strings rendered into
+// the SVG are XML-escaped first, including those already validated (eg. colors & icon labels).
+func (newBadge *badgeDimensions) render() (string, error) {
+	escapedBadge := *newBadge
+	escapedBadge.Color = escapeXML(newBadge.Color)
+	escapedBadge.FontFamily = escapeXML(newBadge.FontFamily)
+	escapedBadge.Subject = escapeXML(newBadge.Subject)
+	escapedBadge.Status = escapeXML(newBadge.Status)
+	escapedBadge.IconLabel = escapeXML(newBadge.IconLabel)
+	escapedBadge.Link = escapeXML(newBadge.Link)
+	escapedBadge.SubjectLink = escapeXML(newBadge.SubjectLink)
+	escapedBadge.StatusLink = escapeXML(newBadge.StatusLink)
+	escapedBadge.Segments = make([]badgeSegment, len(newBadge.Segments))
+	for i, segment := range newBadge.Segments {
+		segment.Text = escapeXML(segment.Text)
+		segment.Color = escapeXML(segment.Color)
+		segment.Link = escapeXML(segment.Link)
+		escapedBadge.Segments[i] = segment
 	}
 
 	var buf bytes.Buffer
-	if err = newBadge.Template.Execute(&buf, newBadge); err != nil {
+	if err := newBadge.Template.Execute(&buf, &escapedBadge); err != nil {
 		return "", err
 	}
 
 	return buf.String(), nil
 }
 
-type imageNode struct {
-	XMLName xml.Name `xml:"image"`
-	ID      string   `xml:"id,attr"`
-	Alt     string   `xml:"alt,attr"`
-	Href    string   `xml:"href,attr"`
-}
+// Create generates a SVG badge
+func Create(params *Params) (string, error) {
+	newBadge, err := generateBadge(params)
+	if err != nil {
+		return "", err
+	}
 
-type pathNode struct {
-	XMLName xml.Name `xml:"path"`
-	ID      string   `xml:"id,attr"`
-	Fill    string   `xml:"fill,attr"`
+	return newBadge.render()
 }
 
-type textNode struct {
-	XMLName  xml.Name `xml:"text"`
-	ID       string   `xml:"id,attr"`
-	CharData string   `xml:",chardata"`
-}
+// Resolve returns the badge parameters as rendered by `Create`, with defaults applied & unsupported values discarded
+func Resolve(params *Params) (*Params, error) {
+	newBadge, err := generateBadge(params)
+	if err != nil {
+		return nil, err
+	}
 
-type svg struct {
-	XMLName xml.Name    `xml:"svg"`
-	ID      string      `xml:"id,attr"`
-	Images  []imageNode `xml:"g>image"`
-	Paths   []pathNode  `xml:"g>path"`
-	Texts   []textNode  `xml:"g>text"`
+	return &Params{
+		Subject:     unisolateText(newBadge.Subject),
+		Status:      unisolateText(newBadge.Status),
+		Color:       newBadge.Color,
+		Icon:        newBadge.IconLabel,
+		Logo:        newBadge.Logo,
+		Style:       newBadge.Style,
+		FontFamily:  newBadge.FontFamily,
+		FontSize:    newBadge.FontSize,
+		Link:        newBadge.Link,
+		SubjectLink: newBadge.SubjectLink,
+		StatusLink:  newBadge.StatusLink,
+	}, nil
 }
 
 // ExtractParams parses a SVG badge generated by `Create` & returns the corresponding badge parameters
 func ExtractParams(badge string) (*Params, error) {
-	svgObj := new(svg)
-	if err := xml.Unmarshal([]byte(badge), svgObj); err != nil {
+	result, err := parseParams(badge)
+	if err != nil {
 		return nil, err
 	}
-
-	result := new(Params)
-	for _, image := range svgObj.Images {
-		if image.ID == "icon" {
-			result.Icon = image.Alt
-		}
-	}
-	for _, path := range svgObj.Paths {
-		if path.ID == "fill" {
-			result.Color = strings.ToLower(path.Fill)
-		}
-	}
-	for _, text := range svgObj.Texts {
-		if text.ID == "subject" {
-			result.Subject = text.CharData
-		}
-		if text.ID == "status" {
-			result.Status = text.CharData
-		}
-	}
-	for _, style := range SupportedStyles {
+	for _, style := range Styles() {
 		newBadge, _ := Create(&Params{
-			Style:   style,
-			Subject: result.Subject,
-			Status:  result.Status,
-			Color:   result.Color,
-			Icon:    result.Icon,
+			Style:       style,
+			Subject:     result.Subject,
+			Status:      result.Status,
+			Color:       result.Color,
+			Icon:        result.Icon,
+			Logo:        result.Logo,
+			FontFamily:  result.FontFamily,
+			FontSize:    result.FontSize,
+			Link:        result.Link,
+			SubjectLink: result.SubjectLink,
+			StatusLink:  result.StatusLink,
 		})
 		if newBadge == badge {
 			result.Style = style
@@ -282,3 +479,97 @@ func ExtractParams(badge string) (*Params, error) {
 
 	return result, nil
 }
+
+// parseParams parses the icon, color, font, subject, status & links of a SVG badge from its elements' ids, wherever they
+// are nested (eg. in the `<a>` elements wrapping the linked groups of the badge, see `Params.Link`)
+func parseParams(badge string) (*Params, error) {
+	decoder := xml.NewDecoder(strings.NewReader(badge))
+	result := new(Params)
+	root := true
+	// textID is the id of the subject or status text being parsed, whose character data is collected in textData
+	textID := ""
+	var textData strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if root && element.Name.Local != "svg" {
+				return nil, fmt.Errorf("expected element type <svg> but have <%s>", element.Name.Local)
+			}
+			root = false
+			attrs := make(map[string]string, len(element.Attr))
+			for _, attr := range element.Attr {
+				attrs[attr.Name.Local] = attr.Value
+			}
+			switch element.Name.Local {
+			case "g":
+				if fontFamily := attrs["font-family"]; fontFamily != "" {
+					// Exclude generic font families (eg. "Verdana,sans-serif")
+					result.FontFamily = strings.TrimSpace(strings.Split(fontFamily, ",")[0])
+					result.FontSize, _ = strconv.Atoi(attrs["font-size"])
+				}
+			case "image":
+				if attrs["id"] == "icon" {
+					result.Icon = attrs["alt"]
+					if result.Icon == "" {
+						result.Logo = parseLogoHref(attrs["href"])
+					}
+				}
+			case "path":
+				if attrs["id"] == "fill" {
+					result.Color = strings.ToLower(attrs["fill"])
+				}
+			case "text":
+				if id := attrs["id"]; id == "subject" || id == "status" {
+					textID = id
+					textData.Reset()
+				}
+			case "a":
+				switch attrs["id"] {
+				case "link":
+					result.Link = attrs["href"]
+				case "subject-link":
+					result.SubjectLink = attrs["href"]
+				case "status-link":
+					result.StatusLink = attrs["href"]
+				}
+			}
+		case xml.CharData:
+			if textID != "" {
+				textData.Write(element)
+			}
+		case xml.EndElement:
+			if element.Name.Local == "text" && textID != "" {
+				if textID == "subject" {
+					result.Subject = unisolateText(textData.String())
+				} else {
+					result.Status = unisolateText(textData.String())
+				}
+				textID = ""
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// parseLogoHref returns the logo data URI of an icon's data URI, without the fill color added by `layout`
+func parseLogoHref(href string) string {
+	svgIcon, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(href, logoPrefix))
+	if err != nil {
+		return ""
+	}
+	if rest, ok := bytes.CutPrefix(svgIcon, []byte(`<svg fill="`)); ok {
+		if _, svgIconAttrs, ok := bytes.Cut(rest, []byte(`"`)); ok {
+			svgIcon = append([]byte("<svg"), svgIconAttrs...)
+		}
+	}
+	return logoPrefix + base64.StdEncoding.EncodeToString(svgIcon)
+}
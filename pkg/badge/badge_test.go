@@ -1,6 +1,8 @@
 package badge
 
 import (
+	"encoding/xml"
+	"io"
 	"strings"
 	"testing"
 
@@ -30,9 +32,13 @@ var testCases = (func() []testCase {
 			expectedStyle = DefaultStyle
 		}
 
-		// Semaphore style badges converts text to uppercase
-		if testStyle == SemaphoreCIStyle {
+		markupSubject, markupStatus := `<script>alert("subject")</script>`, `]]></text><a href='status'>&amp;`
+		expectedMarkupSubject, expectedMarkupStatus := markupSubject, markupStatus
+
+		// Semaphore & for-the-badge style badges converts text to uppercase
+		if testStyle == SemaphoreCIStyle || testStyle == ForTheBadgeStyle {
 			expectedSubject, expectedStatus = "TESTSUBJECT", "TESTSTATUS"
+			expectedMarkupSubject, expectedMarkupStatus = strings.ToUpper(markupSubject), strings.ToUpper(markupStatus)
 		}
 
 		result = append(result, []testCase{
@@ -113,6 +119,43 @@ var testCases = (func() []testCase {
 				input:    Params{Style: testStyle, Icon: "solid/STAR"},
 				expected: Params{Style: expectedStyle, Color: DefaultColor, Icon: ""},
 			},
+			{
+				name:     testNamePrefix + "BadgeWithNonLatinText",
+				input:    Params{Style: testStyle, Subject: "ビルド状態", Status: "成功 🚀"},
+				expected: Params{Style: expectedStyle, Subject: "ビルド状態", Status: "成功 🚀", Color: DefaultColor},
+			},
+			{
+				name:     testNamePrefix + "BadgeWithRightToLeftText",
+				input:    Params{Style: testStyle, Subject: "בנייה", Status: "עבר!"},
+				expected: Params{Style: expectedStyle, Subject: "בנייה", Status: "עבר!", Color: DefaultColor},
+			},
+			{
+				name:     testNamePrefix + "BadgeWithMarkup",
+				input:    Params{Style: testStyle, Subject: markupSubject, Status: markupStatus},
+				expected: Params{Style: expectedStyle, Subject: expectedMarkupSubject, Status: expectedMarkupStatus, Color: DefaultColor},
+			},
+			{
+				name: testNamePrefix + "BadgeWithLinks",
+				input: Params{Style: testStyle, Link: "https://github.com/tohjustin/aegis",
+					SubjectLink: "HTTPS://example.com/subject?a=1&b=2", StatusLink: "javascript:alert(1)"},
+				expected: Params{Style: expectedStyle, Color: DefaultColor, Link: "https://github.com/tohjustin/aegis",
+					SubjectLink: "https://example.com/subject?a=1&b=2"},
+			},
+			{
+				name:     testNamePrefix + "BadgeWithFontSize",
+				input:    Params{Style: testStyle, FontSize: 13},
+				expected: Params{Style: expectedStyle, Color: DefaultColor, FontSize: 13},
+			},
+			{
+				name:     testNamePrefix + "BadgeWithInvalidFontSize",
+				input:    Params{Style: testStyle, FontSize: 40},
+				expected: Params{Style: expectedStyle, Color: DefaultColor},
+			},
+			{
+				name:     testNamePrefix + "BadgeWithInvalidFontFamily",
+				input:    Params{Style: testStyle, FontFamily: "Comic Sans"},
+				expected: Params{Style: expectedStyle, Color: DefaultColor},
+			},
 			{
 				name:     testNamePrefix + "BadgeWithInvalidIcon2",
 				input:    Params{Style: testStyle, Icon: "invalid-icon"},
@@ -121,6 +164,18 @@ var testCases = (func() []testCase {
 		}...)
 	}
 
+	// Badges are rendered with the font of their style by default
+	fontSizes := map[Style]int{ClassicStyle: 11, FlatStyle: 11, PlasticStyle: 11, SemaphoreCIStyle: 9,
+		ForTheBadgeStyle: 10, SocialStyle: 11}
+	for i := range result {
+		if result[i].expected.FontFamily == "" {
+			result[i].expected.FontFamily = DefaultFontFamily
+		}
+		if result[i].expected.FontSize == 0 {
+			result[i].expected.FontSize = fontSizes[result[i].expected.Style]
+		}
+	}
+
 	return result
 })()
 
@@ -158,3 +213,69 @@ func TestBadgeCreate(t *testing.T) {
 		})
 	}
 }
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range testCases {
+		t.Run(spec.name, func(t *testing.T) {
+			resolvedParams, err := Resolve(&spec.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, spec.expected, *resolvedParams)
+		})
+	}
+}
+
+// svgElements returns the names of the elements of a SVG badge, failing if it isn't well-formed XML
+func svgElements(t *testing.T, badge string) []string {
+	var result []string
+	decoder := xml.NewDecoder(strings.NewReader(badge))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatalf("badge is not well-formed XML: %v\n%s", err, badge)
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			result = append(result, element.Name.Local)
+		}
+	}
+}
+
+func FuzzCreate(f *testing.F) {
+	f.Add("testSubject", "testStatus", "#f7b137", "solid/star", "classic", "Verdana", "https://example.com")
+	f.Add(`<script>alert(1)</script>`, `</text><a href="javascript:alert(1)">`, `red"/><script/>`, `"><svg`, "flat", "'",
+		`https://example.com/"><script>alert(1)</script>`)
+	f.Add("]]><!--", "&amp;&lt;&#x0;", "#abc' onload='alert(1)", "brands/github\"", "social", "Verdana,<g>",
+		"https://example.com/?a=1&b='2'")
+	f.Add("\x00\x01\ufffe", "\u202e\u2068", "\n\t\r", "../solid/star", "for-the-badge", "Comic Sans",
+		"javascript:alert(1)")
+	f.Add("\xff\xfe", "<?xml?>", "", "", "semaphoreci", "", "https://\x00")
+
+	f.Fuzz(func(t *testing.T, subject, status, color, icon, style, fontFamily, link string) {
+		params := &Params{Subject: subject, Status: status, Color: color, Icon: icon, Style: Style(style),
+			FontFamily: fontFamily, Link: link, SubjectLink: link, StatusLink: link}
+		result, err := Create(params)
+		if err != nil {
+			t.Skip(err)
+		}
+
+		// User-supplied parameters don't change the structure of the badge
+		resolvedParams, err := Resolve(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := Create(&Params{Subject: "subject", Status: "status", Color: resolvedParams.Color,
+			Icon: resolvedParams.Icon, Style: resolvedParams.Style, FontFamily: resolvedParams.FontFamily,
+			Link: resolvedParams.Link, SubjectLink: resolvedParams.SubjectLink, StatusLink: resolvedParams.StatusLink})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, svgElements(t, expected), svgElements(t, result))
+	})
+}
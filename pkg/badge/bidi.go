@@ -0,0 +1,33 @@
+package badge
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+const (
+	firstStrongIsolate    = "\u2068"
+	popDirectionalIsolate = "\u2069"
+)
+
+// isolateText wraps text containing right-to-left characters in a first strong isolate, so that it is displayed in
+// the direction of its first strong character (eg. with the trailing punctuation of Hebrew text on its left) instead
+// of the left-to-right direction of SVG texts
+func isolateText(text string) string {
+	for _, character := range text {
+		properties, _ := bidi.LookupRune(character)
+		if class := properties.Class(); class == bidi.R || class == bidi.AL {
+			return firstStrongIsolate + text + popDirectionalIsolate
+		}
+	}
+	return text
+}
+
+// unisolateText removes the isolate added by `isolateText` from text
+func unisolateText(text string) string {
+	if strings.HasPrefix(text, firstStrongIsolate) && strings.HasSuffix(text, popDirectionalIsolate) {
+		return strings.TrimSuffix(strings.TrimPrefix(text, firstStrongIsolate), popDirectionalIsolate)
+	}
+	return text
+}
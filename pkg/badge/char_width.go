@@ -2,8 +2,12 @@
 package badge
 
 var (
-	// verdana9CharWidths is an array of character widths from the Verdana font-family with font-size of 9px & indexed by their respective UTF-16 code unit
-	verdana9CharWidths = [...]int{9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 3, 4, 4, 7, 6, 10, 7, 2, 4, 4, 6, 7, 3, 4, 3, 4, 6, 6, 6, 6, 6, 6, 6, 6, 6, 6, 4, 4, 7, 7, 7, 5, 9, 6, 6, 6, 7, 6, 5, 7, 7, 4, 4, 6, 5, 8, 7, 7, 5, 7, 6, 6, 6, 7, 6, 9, 6, 6, 6, 4, 4, 4, 7, 6, 6, 5, 6, 5, 6, 5, 3, 6, 6, 2, 3, 5, 2, 9, 6, 5, 6, 6, 4, 5, 4, 6, 5, 7, 5, 5, 5, 6, 4, 6, 7, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 3, 4, 6, 6, 6, 6, 4, 6, 6, 9, 5, 6, 7, 4, 9, 6, 5, 7, 5, 5, 6, 6, 6, 3, 6, 5, 5, 6, 9, 9, 9, 5, 6, 6, 6, 6, 6, 6, 9, 6, 6, 6, 6, 6, 4, 4, 4, 4, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 6, 5, 6, 5, 5, 5, 5, 5, 5, 9, 5, 5, 5, 5, 5, 2, 2, 2, 2, 6, 6, 5, 5, 5, 5, 5, 7, 5, 6, 6, 6, 6, 5, 6}
-	// verdana11CharWidths is an array of character widths from the Verdana font-family with font-size of 11px & indexed by their respective UTF-16 code unit
-	verdana11CharWidths = [...]int{11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 4, 4, 5, 9, 7, 12, 8, 3, 5, 5, 7, 9, 4, 5, 4, 5, 7, 7, 7, 7, 7, 7, 7, 7, 7, 7, 5, 5, 9, 9, 9, 6, 11, 8, 8, 8, 8, 7, 6, 9, 8, 5, 5, 8, 6, 9, 8, 9, 7, 9, 8, 8, 7, 8, 8, 11, 8, 7, 8, 5, 5, 5, 9, 7, 7, 7, 7, 6, 7, 7, 4, 7, 7, 3, 4, 7, 3, 11, 7, 7, 7, 7, 5, 6, 4, 7, 7, 9, 7, 7, 6, 7, 5, 7, 9, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 11, 4, 4, 7, 7, 7, 7, 5, 7, 7, 11, 6, 7, 9, 5, 11, 7, 6, 9, 6, 6, 7, 7, 7, 4, 7, 6, 6, 7, 11, 11, 11, 6, 8, 8, 8, 8, 8, 8, 11, 8, 7, 7, 7, 7, 5, 5, 5, 5, 9, 8, 9, 9, 9, 9, 9, 9, 9, 8, 8, 8, 8, 7, 7, 7, 7, 7, 7, 7, 7, 7, 11, 6, 7, 7, 7, 7, 3, 3, 3, 3, 7, 7, 7, 7, 7, 7, 7, 9, 7, 7, 7, 7, 7, 7, 7}
+	// verdana9CharWidths is a sorted list of character ranges with their widths from the Verdana font-family with font-size of 9px
+	verdana9CharWidths = []charWidthRange{{0x20, 0x20, 3}, {0x21, 0x22, 4}, {0x23, 0x23, 7}, {0x24, 0x24, 6}, {0x25, 0x25, 10}, {0x26, 0x26, 7}, {0x27, 0x27, 2}, {0x28, 0x29, 4}, {0x2a, 0x2a, 6}, {0x2b, 0x2b, 7}, {0x2c, 0x2c, 3}, {0x2d, 0x2d, 4}, {0x2e, 0x2e, 3}, {0x2f, 0x2f, 4}, {0x30, 0x39, 6}, {0x3a, 0x3b, 4}, {0x3c, 0x3e, 7}, {0x3f, 0x3f, 5}, {0x40, 0x40, 9}, {0x41, 0x43, 6}, {0x44, 0x44, 7}, {0x45, 0x45, 6}, {0x46, 0x46, 5}, {0x47, 0x48, 7}, {0x49, 0x4a, 4}, {0x4b, 0x4b, 6}, {0x4c, 0x4c, 5}, {0x4d, 0x4d, 8}, {0x4e, 0x4f, 7}, {0x50, 0x50, 5}, {0x51, 0x51, 7}, {0x52, 0x54, 6}, {0x55, 0x55, 7}, {0x56, 0x56, 6}, {0x57, 0x57, 9}, {0x58, 0x5a, 6}, {0x5b, 0x5d, 4}, {0x5e, 0x5e, 7}, {0x5f, 0x60, 6}, {0x61, 0x61, 5}, {0x62, 0x62, 6}, {0x63, 0x63, 5}, {0x64, 0x64, 6}, {0x65, 0x65, 5}, {0x66, 0x66, 3}, {0x67, 0x68, 6}, {0x69, 0x69, 2}, {0x6a, 0x6a, 3}, {0x6b, 0x6b, 5}, {0x6c, 0x6c, 2}, {0x6d, 0x6d, 9}, {0x6e, 0x6e, 6}, {0x6f, 0x6f, 5}, {0x70, 0x71, 6}, {0x72, 0x72, 4}, {0x73, 0x73, 5}, {0x74, 0x74, 4}, {0x75, 0x75, 6}, {0x76, 0x76, 5}, {0x77, 0x77, 7}, {0x78, 0x7a, 5}, {0x7b, 0x7b, 6}, {0x7c, 0x7c, 4}, {0x7d, 0x7d, 6}, {0x7e, 0x7e, 7}, {0xa0, 0xa0, 3}, {0xa1, 0xa1, 4}, {0xa2, 0xa5, 6}, {0xa6, 0xa6, 4}, {0xa7, 0xa8, 6}, {0xa9, 0xa9, 9}, {0xaa, 0xaa, 5}, {0xab, 0xab, 6}, {0xac, 0xac, 7}, {0xad, 0xad, 4}, {0xae, 0xae, 9}, {0xaf, 0xaf, 6}, {0xb0, 0xb0, 5}, {0xb1, 0xb1, 7}, {0xb2, 0xb3, 5}, {0xb4, 0xb6, 6}, {0xb7, 0xb7, 3}, {0xb8, 0xb8, 6}, {0xb9, 0xba, 5}, {0xbb, 0xbb, 6}, {0xbc, 0xbe, 9}, {0xbf, 0xbf, 5}, {0xc0, 0xc5, 6}, {0xc6, 0xc6, 9}, {0xc7, 0xcb, 6}, {0xcc, 0xcf, 4}, {0xd0, 0xdc, 7}, {0xdd, 0xdd, 6}, {0xde, 0xde, 5}, {0xdf, 0xdf, 6}, {0xe0, 0xe5, 5}, {0xe6, 0xe6, 9}, {0xe7, 0xeb, 5}, {0xec, 0xef, 2}, {0xf0, 0xf1, 6}, {0xf2, 0xf6, 5}, {0xf7, 0xf7, 7}, {0xf8, 0xf8, 5}, {0xf9, 0xfc, 6}, {0xfd, 0xfd, 5}, {0xfe, 0xfe, 6}, {0xff, 0xff, 5}, {0x100, 0x100, 6}, {0x101, 0x101, 5}, {0x102, 0x102, 6}, {0x103, 0x103, 5}, {0x104, 0x104, 6}, {0x105, 0x105, 5}, {0x106, 0x106, 6}, {0x107, 0x107, 5}, {0x108, 0x108, 6}, {0x109, 0x109, 5}, {0x10a, 0x10a, 6}, {0x10b, 0x10b, 5}, {0x10c, 0x10c, 6}, {0x10d, 0x10d, 5}, {0x10e, 0x10e, 7}, {0x10f, 0x10f, 6}, {0x110, 0x110, 7}, {0x111, 0x112, 6}, {0x113, 0x113, 5}, {0x114, 0x114, 6}, {0x115, 0x115, 5}, {0x116, 0x116, 6}, {0x117, 0x117, 5}, {0x118, 0x118, 6}, {0x119, 0x119, 5}, {0x11a, 0x11a, 6}, {0x11b, 0x11b, 5}, {0x11c, 0x11c, 7}, {0x11d, 0x11d, 6}, {0x11e, 0x11e, 7}, {0x11f, 0x11f, 6}, {0x120, 0x120, 7}, {0x121, 0x121, 6}, {0x122, 0x122, 7}, {0x123, 0x123, 6}, {0x124, 0x124, 7}, {0x125, 0x125, 6}, {0x126, 0x126, 7}, {0x127, 0x127, 6}, {0x128, 0x128, 4}, {0x129, 0x129, 2}, {0x12a, 0x12a, 4}, {0x12b, 0x12b, 2}, {0x12c, 0x12c, 4}, {0x12d, 0x12d, 2}, {0x12e, 0x12e, 4}, {0x12f, 0x12f, 2}, {0x130, 0x130, 4}, {0x131, 0x131, 2}, {0x132, 0x132, 8}, {0x133, 0x133, 6}, {0x134, 0x134, 4}, {0x135, 0x135, 3}, {0x136, 0x136, 6}, {0x137, 0x139, 5}, {0x13a, 0x13a, 2}, {0x13b, 0x13b, 5}, {0x13c, 0x13c, 2}, {0x13d, 0x13d, 5}, {0x13e, 0x13e, 3}, {0x13f, 0x13f, 5}, {0x140, 0x140, 4}, {0x141, 0x141, 5}, {0x142, 0x142, 3}, {0x143, 0x143, 7}, {0x144, 0x144, 6}, {0x145, 0x145, 7}, {0x146, 0x146, 6}, {0x147, 0x147, 7}, {0x148, 0x148, 6}, {0x149, 0x14a, 7}, {0x14b, 0x14b, 6}, {0x14c, 0x14c, 7}, {0x14d, 0x14d, 5}, {0x14e, 0x14e, 7}, {0x14f, 0x14f, 5}, {0x150, 0x150, 7}, {0x151, 0x151, 5}, {0x152, 0x152, 10}, {0x153, 0x153, 9}, {0x154, 0x154, 6}, {0x155, 0x155, 4}, {0x156, 0x156, 6}, {0x157, 0x157, 4}, {0x158, 0x158, 6}, {0x159, 0x159, 4}, {0x15a, 0x15a, 6}, {0x15b, 0x15b, 5}, {0x15c, 0x15c, 6}, {0x15d, 0x15d, 5}, {0x15e, 0x15e, 6}, {0x15f, 0x15f, 5}, {0x160, 0x160, 6}, {0x161, 0x161, 5}, {0x162, 0x162, 6}, {0x163, 0x163, 4}, {0x164, 0x164, 6}, {0x165, 0x165, 4}, {0x166, 0x166, 6}, {0x167, 0x167, 4}, {0x168, 0x168, 7}, {0x169, 0x169, 6}, {0x16a, 0x16a, 7}, {0x16b, 0x16b, 6}, {0x16c, 0x16c, 7}, {0x16d, 0x16d, 6}, {0x16e, 0x16e, 7}, {0x16f, 0x16f, 6}, {0x170, 0x170, 7}, {0x171, 0x171, 6}, {0x172, 0x172, 7}, {0x173, 0x173, 6}, {0x174, 0x174, 9}, {0x175, 0x175, 7}, {0x176, 0x176, 6}, {0x177, 0x177, 5}, {0x178, 0x179, 6}, {0x17a, 0x17a, 5}, {0x17b, 0x17b, 6}, {0x17c, 0x17c, 5}, {0x17d, 0x17d, 6}, {0x17e, 0x17e, 5}, {0x17f, 0x17f, 3}, {0x192, 0x192, 6}, {0x1fa, 0x1fa, 6}, {0x1fb, 0x1fb, 5}, {0x1fc, 0x1fd, 9}, {0x1fe, 0x1fe, 7}, {0x1ff, 0x1ff, 5}, {0x2c6, 0x2c7, 6}, {0x2c9, 0x2c9, 6}, {0x2d8, 0x2dd, 6}, {0x37e, 0x37e, 4}, {0x384, 0x386, 6}, {0x387, 0x387, 4}, {0x388, 0x388, 7}, {0x389, 0x389, 8}, {0x38a, 0x38a, 5}, {0x38c, 0x38c, 8}, {0x38e, 0x38e, 7}, {0x38f, 0x38f, 8}, {0x390, 0x390, 2}, {0x391, 0x392, 6}, {0x393, 0x393, 5}, {0x394, 0x396, 6}, {0x397, 0x398, 7}, {0x399, 0x399, 4}, {0x39a, 0x39b, 6}, {0x39c, 0x39c, 8}, {0x39d, 0x39d, 7}, {0x39e, 0x39e, 6}, {0x39f, 0x3a0, 7}, {0x3a1, 0x3a1, 5}, {0x3a3, 0x3a5, 6}, {0x3a6, 0x3a6, 7}, {0x3a7, 0x3a7, 6}, {0x3a8, 0x3a8, 8}, {0x3a9, 0x3a9, 7}, {0x3aa, 0x3aa, 4}, {0x3ab, 0x3ac, 6}, {0x3ad, 0x3ad, 5}, {0x3ae, 0x3ae, 6}, {0x3af, 0x3af, 2}, {0x3b0, 0x3b2, 6}, {0x3b3, 0x3b5, 5}, {0x3b6, 0x3b6, 4}, {0x3b7, 0x3b8, 6}, {0x3b9, 0x3b9, 2}, {0x3ba, 0x3bb, 5}, {0x3bc, 0x3bc, 6}, {0x3bd, 0x3bf, 5}, {0x3c0, 0x3c1, 6}, {0x3c2, 0x3c2, 5}, {0x3c3, 0x3c3, 6}, {0x3c4, 0x3c4, 4}, {0x3c5, 0x3c5, 6}, {0x3c6, 0x3c6, 7}, {0x3c7, 0x3c7, 5}, {0x3c8, 0x3c9, 7}, {0x3ca, 0x3ca, 2}, {0x3cb, 0x3cb, 6}, {0x3cc, 0x3cc, 5}, {0x3cd, 0x3cd, 6}, {0x3ce, 0x3ce, 7}, {0x401, 0x401, 6}, {0x402, 0x402, 7}, {0x403, 0x403, 5}, {0x404, 0x405, 6}, {0x406, 0x408, 4}, {0x409, 0x40a, 10}, {0x40b, 0x40b, 7}, {0x40c, 0x40c, 6}, {0x40e, 0x40e, 6}, {0x40f, 0x40f, 7}, {0x410, 0x412, 6}, {0x413, 0x413, 5}, {0x414, 0x414, 7}, {0x415, 0x415, 6}, {0x416, 0x416, 9}, {0x417, 0x417, 6}, {0x418, 0x419, 7}, {0x41a, 0x41a, 6}, {0x41b, 0x41b, 7}, {0x41c, 0x41c, 8}, {0x41d, 0x41f, 7}, {0x420, 0x420, 5}, {0x421, 0x423, 6}, {0x424, 0x424, 7}, {0x425, 0x425, 6}, {0x426, 0x426, 7}, {0x427, 0x427, 6}, {0x428, 0x429, 9}, {0x42a, 0x42a, 7}, {0x42b, 0x42b, 8}, {0x42c, 0x42d, 6}, {0x42e, 0x42e, 9}, {0x42f, 0x42f, 6}, {0x430, 0x430, 5}, {0x431, 0x431, 6}, {0x432, 0x432, 5}, {0x433, 0x433, 4}, {0x434, 0x434, 6}, {0x435, 0x435, 5}, {0x436, 0x436, 7}, {0x437, 0x437, 5}, {0x438, 0x439, 6}, {0x43a, 0x43a, 5}, {0x43b, 0x43d, 6}, {0x43e, 0x43e, 5}, {0x43f, 0x440, 6}, {0x441, 0x441, 5}, {0x442, 0x442, 4}, {0x443, 0x443, 5}, {0x444, 0x444, 8}, {0x445, 0x445, 5}, {0x446, 0x446, 6}, {0x447, 0x447, 5}, {0x448, 0x449, 8}, {0x44a, 0x44a, 6}, {0x44b, 0x44b, 7}, {0x44c, 0x44d, 5}, {0x44e, 0x44e, 8}, {0x44f, 0x44f, 5}, {0x451, 0x451, 5}, {0x452, 0x452, 6}, {0x453, 0x453, 4}, {0x454, 0x455, 5}, {0x456, 0x457, 2}, {0x458, 0x458, 3}, {0x459, 0x45a, 8}, {0x45b, 0x45b, 6}, {0x45c, 0x45c, 5}, {0x45e, 0x45e, 5}, {0x45f, 0x45f, 6}, {0x490, 0x490, 5}, {0x491, 0x491, 4}, {0x1e80, 0x1e80, 9}, {0x1e81, 0x1e81, 7}, {0x1e82, 0x1e82, 9}, {0x1e83, 0x1e83, 7}, {0x1e84, 0x1e84, 9}, {0x1e85, 0x1e85, 7}, {0x1ef2, 0x1ef2, 6}, {0x1ef3, 0x1ef3, 5}, {0x2013, 0x2013, 6}, {0x2014, 0x2015, 9}, {0x2017, 0x2017, 6}, {0x2018, 0x201b, 2}, {0x201c, 0x201e, 4}, {0x2020, 0x2021, 6}, {0x2022, 0x2022, 5}, {0x2026, 0x2026, 7}, {0x2030, 0x2030, 14}, {0x2032, 0x2032, 3}, {0x2033, 0x2033, 5}, {0x2039, 0x203a, 4}, {0x203c, 0x203c, 6}, {0x203e, 0x203e, 6}, {0x2044, 0x2044, 3}, {0x207f, 0x207f, 5}, {0x20a3, 0x20a4, 6}, {0x20a7, 0x20a7, 10}, {0x2105, 0x2105, 10}, {0x2113, 0x2113, 3}, {0x2116, 0x2116, 11}, {0x2122, 0x2122, 9}, {0x2126, 0x2126, 7}, {0x212e, 0x212e, 6}, {0x215b, 0x215e, 9}, {0x2202, 0x2202, 6}, {0x2206, 0x2206, 7}, {0x220f, 0x220f, 7}, {0x2211, 0x2212, 7}, {0x2215, 0x2215, 3}, {0x2219, 0x2219, 3}, {0x221a, 0x221a, 7}, {0x221e, 0x221e, 9}, {0x222b, 0x222b, 6}, {0x2248, 0x2248, 7}, {0x2260, 0x2260, 7}, {0x2264, 0x2265, 7}, {0x25a1, 0x25a1, 5}, {0x25aa, 0x25ab, 3}, {0x25ca, 0x25ca, 7}, {0x25cf, 0x25cf, 5}, {0x25e6, 0x25e6, 3}, {0xf001, 0xf002, 6}, {0xf004, 0xf004, 2}, {0xf005, 0xf005, 6}, {0xf006, 0xf00c, 5}, {0xf00d, 0xf00d, 0}, {0xfb01, 0xfb02, 6}}
+	// verdana11CharWidths is a sorted list of character ranges with their widths from the Verdana font-family with font-size of 11px
+	verdana11CharWidths = []charWidthRange{{0x20, 0x21, 4}, {0x22, 0x22, 5}, {0x23, 0x23, 9}, {0x24, 0x24, 7}, {0x25, 0x25, 12}, {0x26, 0x26, 8}, {0x27, 0x27, 3}, {0x28, 0x29, 5}, {0x2a, 0x2a, 7}, {0x2b, 0x2b, 9}, {0x2c, 0x2c, 4}, {0x2d, 0x2d, 5}, {0x2e, 0x2e, 4}, {0x2f, 0x2f, 5}, {0x30, 0x39, 7}, {0x3a, 0x3b, 5}, {0x3c, 0x3e, 9}, {0x3f, 0x3f, 6}, {0x40, 0x40, 11}, {0x41, 0x44, 8}, {0x45, 0x45, 7}, {0x46, 0x46, 6}, {0x47, 0x47, 9}, {0x48, 0x48, 8}, {0x49, 0x4a, 5}, {0x4b, 0x4b, 8}, {0x4c, 0x4c, 6}, {0x4d, 0x4d, 9}, {0x4e, 0x4e, 8}, {0x4f, 0x4f, 9}, {0x50, 0x50, 7}, {0x51, 0x51, 9}, {0x52, 0x53, 8}, {0x54, 0x54, 7}, {0x55, 0x56, 8}, {0x57, 0x57, 11}, {0x58, 0x58, 8}, {0x59, 0x59, 7}, {0x5a, 0x5a, 8}, {0x5b, 0x5d, 5}, {0x5e, 0x5e, 9}, {0x5f, 0x62, 7}, {0x63, 0x63, 6}, {0x64, 0x65, 7}, {0x66, 0x66, 4}, {0x67, 0x68, 7}, {0x69, 0x69, 3}, {0x6a, 0x6a, 4}, {0x6b, 0x6b, 7}, {0x6c, 0x6c, 3}, {0x6d, 0x6d, 11}, {0x6e, 0x71, 7}, {0x72, 0x72, 5}, {0x73, 0x73, 6}, {0x74, 0x74, 4}, {0x75, 0x76, 7}, {0x77, 0x77, 9}, {0x78, 0x79, 7}, {0x7a, 0x7a, 6}, {0x7b, 0x7b, 7}, {0x7c, 0x7c, 5}, {0x7d, 0x7d, 7}, {0x7e, 0x7e, 9}, {0xa0, 0xa1, 4}, {0xa2, 0xa5, 7}, {0xa6, 0xa6, 5}, {0xa7, 0xa8, 7}, {0xa9, 0xa9, 11}, {0xaa, 0xaa, 6}, {0xab, 0xab, 7}, {0xac, 0xac, 9}, {0xad, 0xad, 5}, {0xae, 0xae, 11}, {0xaf, 0xaf, 7}, {0xb0, 0xb0, 6}, {0xb1, 0xb1, 9}, {0xb2, 0xb3, 6}, {0xb4, 0xb6, 7}, {0xb7, 0xb7, 4}, {0xb8, 0xb8, 7}, {0xb9, 0xba, 6}, {0xbb, 0xbb, 7}, {0xbc, 0xbe, 11}, {0xbf, 0xbf, 6}, {0xc0, 0xc5, 8}, {0xc6, 0xc6, 11}, {0xc7, 0xc7, 8}, {0xc8, 0xcb, 7}, {0xcc, 0xcf, 5}, {0xd0, 0xd0, 9}, {0xd1, 0xd1, 8}, {0xd2, 0xd8, 9}, {0xd9, 0xdc, 8}, {0xdd, 0xe5, 7}, {0xe6, 0xe6, 11}, {0xe7, 0xe7, 6}, {0xe8, 0xeb, 7}, {0xec, 0xef, 3}, {0xf0, 0xf6, 7}, {0xf7, 0xf7, 9}, {0xf8, 0xff, 7}, {0x100, 0x100, 8}, {0x101, 0x101, 7}, {0x102, 0x102, 8}, {0x103, 0x103, 7}, {0x104, 0x104, 8}, {0x105, 0x105, 7}, {0x106, 0x106, 8}, {0x107, 0x107, 6}, {0x108, 0x108, 8}, {0x109, 0x109, 6}, {0x10a, 0x10a, 8}, {0x10b, 0x10b, 6}, {0x10c, 0x10c, 8}, {0x10d, 0x10d, 6}, {0x10e, 0x10e, 8}, {0x10f, 0x10f, 7}, {0x110, 0x110, 9}, {0x111, 0x11b, 7}, {0x11c, 0x11c, 9}, {0x11d, 0x11d, 7}, {0x11e, 0x11e, 9}, {0x11f, 0x11f, 7}, {0x120, 0x120, 9}, {0x121, 0x121, 7}, {0x122, 0x122, 9}, {0x123, 0x123, 7}, {0x124, 0x124, 8}, {0x125, 0x125, 7}, {0x126, 0x126, 8}, {0x127, 0x127, 7}, {0x128, 0x128, 5}, {0x129, 0x129, 3}, {0x12a, 0x12a, 5}, {0x12b, 0x12b, 3}, {0x12c, 0x12c, 5}, {0x12d, 0x12d, 3}, {0x12e, 0x12e, 5}, {0x12f, 0x12f, 3}, {0x130, 0x130, 5}, {0x131, 0x131, 3}, {0x132, 0x132, 10}, {0x133, 0x133, 7}, {0x134, 0x134, 5}, {0x135, 0x135, 4}, {0x136, 0x136, 8}, {0x137, 0x138, 7}, {0x139, 0x139, 6}, {0x13a, 0x13a, 3}, {0x13b, 0x13b, 6}, {0x13c, 0x13c, 3}, {0x13d, 0x13d, 6}, {0x13e, 0x13e, 3}, {0x13f, 0x13f, 6}, {0x140, 0x140, 5}, {0x141, 0x141, 6}, {0x142, 0x142, 3}, {0x143, 0x143, 8}, {0x144, 0x144, 7}, {0x145, 0x145, 8}, {0x146, 0x146, 7}, {0x147, 0x147, 8}, {0x148, 0x148, 7}, {0x149, 0x14a, 8}, {0x14b, 0x14b, 7}, {0x14c, 0x14c, 9}, {0x14d, 0x14d, 7}, {0x14e, 0x14e, 9}, {0x14f, 0x14f, 7}, {0x150, 0x150, 9}, {0x151, 0x151, 7}, {0x152, 0x152, 12}, {0x153, 0x153, 11}, {0x154, 0x154, 8}, {0x155, 0x155, 5}, {0x156, 0x156, 8}, {0x157, 0x157, 5}, {0x158, 0x158, 8}, {0x159, 0x159, 5}, {0x15a, 0x15a, 8}, {0x15b, 0x15b, 6}, {0x15c, 0x15c, 8}, {0x15d, 0x15d, 6}, {0x15e, 0x15e, 8}, {0x15f, 0x15f, 6}, {0x160, 0x160, 8}, {0x161, 0x161, 6}, {0x162, 0x162, 7}, {0x163, 0x163, 4}, {0x164, 0x164, 7}, {0x165, 0x165, 4}, {0x166, 0x166, 7}, {0x167, 0x167, 4}, {0x168, 0x168, 8}, {0x169, 0x169, 7}, {0x16a, 0x16a, 8}, {0x16b, 0x16b, 7}, {0x16c, 0x16c, 8}, {0x16d, 0x16d, 7}, {0x16e, 0x16e, 8}, {0x16f, 0x16f, 7}, {0x170, 0x170, 8}, {0x171, 0x171, 7}, {0x172, 0x172, 8}, {0x173, 0x173, 7}, {0x174, 0x174, 11}, {0x175, 0x175, 9}, {0x176, 0x178, 7}, {0x179, 0x179, 8}, {0x17a, 0x17a, 6}, {0x17b, 0x17b, 8}, {0x17c, 0x17c, 6}, {0x17d, 0x17d, 8}, {0x17e, 0x17e, 6}, {0x17f, 0x17f, 3}, {0x192, 0x192, 7}, {0x1fa, 0x1fa, 8}, {0x1fb, 0x1fb, 7}, {0x1fc, 0x1fd, 11}, {0x1fe, 0x1fe, 9}, {0x1ff, 0x1ff, 7}, {0x2c6, 0x2c7, 7}, {0x2c9, 0x2c9, 7}, {0x2d8, 0x2dd, 7}, {0x37e, 0x37e, 5}, {0x384, 0x385, 7}, {0x386, 0x386, 8}, {0x387, 0x387, 5}, {0x388, 0x388, 8}, {0x389, 0x389, 10}, {0x38a, 0x38a, 6}, {0x38c, 0x38c, 10}, {0x38e, 0x38e, 8}, {0x38f, 0x38f, 10}, {0x390, 0x390, 3}, {0x391, 0x392, 8}, {0x393, 0x393, 6}, {0x394, 0x394, 8}, {0x395, 0x395, 7}, {0x396, 0x397, 8}, {0x398, 0x398, 9}, {0x399, 0x399, 5}, {0x39a, 0x39b, 8}, {0x39c, 0x39c, 9}, {0x39d, 0x39d, 8}, {0x39e, 0x39e, 7}, {0x39f, 0x39f, 9}, {0x3a0, 0x3a0, 8}, {0x3a1, 0x3a1, 7}, {0x3a3, 0x3a5, 7}, {0x3a6, 0x3a6, 9}, {0x3a7, 0x3a7, 8}, {0x3a8, 0x3a8, 10}, {0x3a9, 0x3a9, 9}, {0x3aa, 0x3aa, 5}, {0x3ab, 0x3ac, 7}, {0x3ad, 0x3ad, 6}, {0x3ae, 0x3ae, 7}, {0x3af, 0x3af, 3}, {0x3b0, 0x3b4, 7}, {0x3b5, 0x3b5, 6}, {0x3b6, 0x3b6, 5}, {0x3b7, 0x3b8, 7}, {0x3b9, 0x3b9, 3}, {0x3ba, 0x3bd, 7}, {0x3be, 0x3be, 6}, {0x3bf, 0x3c1, 7}, {0x3c2, 0x3c2, 6}, {0x3c3, 0x3c3, 7}, {0x3c4, 0x3c4, 5}, {0x3c5, 0x3c5, 7}, {0x3c6, 0x3c6, 9}, {0x3c7, 0x3c7, 6}, {0x3c8, 0x3c9, 9}, {0x3ca, 0x3ca, 3}, {0x3cb, 0x3cd, 7}, {0x3ce, 0x3ce, 9}, {0x401, 0x401, 7}, {0x402, 0x402, 9}, {0x403, 0x403, 6}, {0x404, 0x405, 8}, {0x406, 0x408, 5}, {0x409, 0x40a, 12}, {0x40b, 0x40b, 9}, {0x40c, 0x40c, 8}, {0x40e, 0x40e, 7}, {0x40f, 0x412, 8}, {0x413, 0x413, 6}, {0x414, 0x414, 8}, {0x415, 0x415, 7}, {0x416, 0x416, 11}, {0x417, 0x417, 7}, {0x418, 0x41b, 8}, {0x41c, 0x41c, 9}, {0x41d, 0x41d, 8}, {0x41e, 0x41e, 9}, {0x41f, 0x41f, 8}, {0x420, 0x420, 7}, {0x421, 0x421, 8}, {0x422, 0x423, 7}, {0x424, 0x424, 9}, {0x425, 0x427, 8}, {0x428, 0x429, 11}, {0x42a, 0x42a, 9}, {0x42b, 0x42b, 10}, {0x42c, 0x42c, 7}, {0x42d, 0x42d, 8}, {0x42e, 0x42e, 11}, {0x42f, 0x42f, 8}, {0x430, 0x432, 7}, {0x433, 0x433, 5}, {0x434, 0x435, 7}, {0x436, 0x436, 9}, {0x437, 0x437, 6}, {0x438, 0x43b, 7}, {0x43c, 0x43c, 8}, {0x43d, 0x440, 7}, {0x441, 0x441, 6}, {0x442, 0x442, 5}, {0x443, 0x443, 7}, {0x444, 0x444, 9}, {0x445, 0x447, 7}, {0x448, 0x449, 10}, {0x44a, 0x44a, 7}, {0x44b, 0x44b, 9}, {0x44c, 0x44d, 6}, {0x44e, 0x44e, 9}, {0x44f, 0x44f, 7}, {0x451, 0x452, 7}, {0x453, 0x453, 5}, {0x454, 0x455, 6}, {0x456, 0x457, 3}, {0x458, 0x458, 4}, {0x459, 0x45a, 10}, {0x45b, 0x45c, 7}, {0x45e, 0x45f, 7}, {0x490, 0x490, 6}, {0x491, 0x491, 5}, {0x1e80, 0x1e80, 11}, {0x1e81, 0x1e81, 9}, {0x1e82, 0x1e82, 11}, {0x1e83, 0x1e83, 9}, {0x1e84, 0x1e84, 11}, {0x1e85, 0x1e85, 9}, {0x1ef2, 0x1ef3, 7}, {0x2013, 0x2013, 7}, {0x2014, 0x2015, 11}, {0x2017, 0x2017, 7}, {0x2018, 0x201b, 3}, {0x201c, 0x201e, 5}, {0x2020, 0x2021, 7}, {0x2022, 0x2022, 6}, {0x2026, 0x2026, 9}, {0x2030, 0x2030, 17}, {0x2032, 0x2032, 4}, {0x2033, 0x2033, 6}, {0x2039, 0x203a, 5}, {0x203c, 0x203c, 7}, {0x203e, 0x203e, 7}, {0x2044, 0x2044, 4}, {0x207f, 0x207f, 6}, {0x20a3, 0x20a4, 7}, {0x20a7, 0x20a7, 13}, {0x2105, 0x2105, 12}, {0x2113, 0x2113, 4}, {0x2116, 0x2116, 13}, {0x2122, 0x2122, 11}, {0x2126, 0x2126, 9}, {0x212e, 0x212e, 8}, {0x215b, 0x215e, 11}, {0x2202, 0x2202, 7}, {0x2206, 0x2206, 8}, {0x220f, 0x220f, 9}, {0x2211, 0x2211, 8}, {0x2212, 0x2212, 9}, {0x2215, 0x2215, 4}, {0x2219, 0x2219, 4}, {0x221a, 0x221a, 9}, {0x221e, 0x221e, 11}, {0x222b, 0x222b, 7}, {0x2248, 0x2248, 9}, {0x2260, 0x2260, 9}, {0x2264, 0x2265, 9}, {0x25a1, 0x25a1, 7}, {0x25aa, 0x25ab, 4}, {0x25ca, 0x25ca, 9}, {0x25cf, 0x25cf, 7}, {0x25e6, 0x25e6, 4}, {0xf001, 0xf002, 7}, {0xf004, 0xf004, 2}, {0xf005, 0xf005, 7}, {0xf006, 0xf00c, 6}, {0xf00d, 0xf00d, 0}, {0xfb01, 0xfb02, 7}}
+	// verdanaBold10CharWidths is a sorted list of character ranges with their bold widths from the Verdana font-family with font-size of 10px
+	verdanaBold10CharWidths = []charWidthRange{{0x20, 0x21, 4}, {0x22, 0x22, 5}, {0x23, 0x23, 9}, {0x24, 0x24, 7}, {0x25, 0x25, 11}, {0x26, 0x26, 8}, {0x27, 0x27, 3}, {0x28, 0x29, 5}, {0x2a, 0x2a, 7}, {0x2b, 0x2b, 9}, {0x2c, 0x2c, 4}, {0x2d, 0x2d, 5}, {0x2e, 0x2e, 4}, {0x2f, 0x2f, 5}, {0x30, 0x39, 7}, {0x3a, 0x3b, 5}, {0x3c, 0x3e, 9}, {0x3f, 0x3f, 6}, {0x40, 0x40, 10}, {0x41, 0x43, 7}, {0x44, 0x44, 8}, {0x45, 0x45, 7}, {0x46, 0x46, 6}, {0x47, 0x48, 8}, {0x49, 0x4a, 5}, {0x4b, 0x4b, 7}, {0x4c, 0x4c, 6}, {0x4d, 0x4d, 9}, {0x4e, 0x4f, 8}, {0x50, 0x50, 6}, {0x51, 0x51, 8}, {0x52, 0x54, 7}, {0x55, 0x55, 8}, {0x56, 0x56, 7}, {0x57, 0x57, 10}, {0x58, 0x5a, 7}, {0x5b, 0x5d, 5}, {0x5e, 0x5e, 9}, {0x5f, 0x60, 7}, {0x61, 0x61, 6}, {0x62, 0x62, 7}, {0x63, 0x63, 6}, {0x64, 0x64, 7}, {0x65, 0x65, 6}, {0x66, 0x66, 4}, {0x67, 0x68, 7}, {0x69, 0x69, 3}, {0x6a, 0x6a, 4}, {0x6b, 0x6b, 6}, {0x6c, 0x6c, 3}, {0x6d, 0x6d, 10}, {0x6e, 0x6e, 7}, {0x6f, 0x6f, 6}, {0x70, 0x71, 7}, {0x72, 0x72, 5}, {0x73, 0x73, 6}, {0x74, 0x74, 4}, {0x75, 0x75, 7}, {0x76, 0x76, 6}, {0x77, 0x77, 9}, {0x78, 0x7a, 6}, {0x7b, 0x7b, 7}, {0x7c, 0x7c, 5}, {0x7d, 0x7d, 7}, {0x7e, 0x7e, 9}, {0xa0, 0xa1, 4}, {0xa2, 0xa5, 7}, {0xa6, 0xa6, 5}, {0xa7, 0xa8, 7}, {0xa9, 0xa9, 10}, {0xaa, 0xaa, 6}, {0xab, 0xab, 7}, {0xac, 0xac, 9}, {0xad, 0xad, 5}, {0xae, 0xae, 10}, {0xaf, 0xaf, 7}, {0xb0, 0xb0, 6}, {0xb1, 0xb1, 9}, {0xb2, 0xb3, 6}, {0xb4, 0xb6, 7}, {0xb7, 0xb7, 4}, {0xb8, 0xb8, 7}, {0xb9, 0xba, 6}, {0xbb, 0xbb, 7}, {0xbc, 0xbe, 10}, {0xbf, 0xbf, 6}, {0xc0, 0xc5, 7}, {0xc6, 0xc6, 10}, {0xc7, 0xcb, 7}, {0xcc, 0xcf, 5}, {0xd0, 0xd6, 8}, {0xd7, 0xd7, 9}, {0xd8, 0xdc, 8}, {0xdd, 0xdd, 7}, {0xde, 0xde, 6}, {0xdf, 0xdf, 7}, {0xe0, 0xe5, 6}, {0xe6, 0xe6, 10}, {0xe7, 0xeb, 6}, {0xec, 0xef, 3}, {0xf0, 0xf1, 7}, {0xf2, 0xf6, 6}, {0xf7, 0xf7, 9}, {0xf8, 0xf8, 6}, {0xf9, 0xfc, 7}, {0xfd, 0xfd, 6}, {0xfe, 0xfe, 7}, {0xff, 0xff, 6}, {0x100, 0x100, 7}, {0x101, 0x101, 6}, {0x102, 0x102, 7}, {0x103, 0x103, 6}, {0x104, 0x104, 7}, {0x105, 0x105, 6}, {0x106, 0x106, 7}, {0x107, 0x107, 6}, {0x108, 0x108, 7}, {0x109, 0x109, 6}, {0x10a, 0x10a, 7}, {0x10b, 0x10b, 6}, {0x10c, 0x10c, 7}, {0x10d, 0x10d, 6}, {0x10e, 0x10e, 8}, {0x10f, 0x10f, 7}, {0x110, 0x110, 8}, {0x111, 0x112, 7}, {0x113, 0x113, 6}, {0x114, 0x114, 7}, {0x115, 0x115, 6}, {0x116, 0x116, 7}, {0x117, 0x117, 6}, {0x118, 0x118, 7}, {0x119, 0x119, 6}, {0x11a, 0x11a, 7}, {0x11b, 0x11b, 6}, {0x11c, 0x11c, 8}, {0x11d, 0x11d, 7}, {0x11e, 0x11e, 8}, {0x11f, 0x11f, 7}, {0x120, 0x120, 8}, {0x121, 0x121, 7}, {0x122, 0x122, 8}, {0x123, 0x123, 7}, {0x124, 0x124, 8}, {0x125, 0x125, 7}, {0x126, 0x126, 8}, {0x127, 0x127, 7}, {0x128, 0x128, 5}, {0x129, 0x129, 3}, {0x12a, 0x12a, 5}, {0x12b, 0x12b, 3}, {0x12c, 0x12c, 5}, {0x12d, 0x12d, 3}, {0x12e, 0x12e, 5}, {0x12f, 0x12f, 3}, {0x130, 0x130, 5}, {0x131, 0x131, 3}, {0x132, 0x132, 9}, {0x133, 0x133, 7}, {0x134, 0x134, 5}, {0x135, 0x135, 4}, {0x136, 0x136, 7}, {0x137, 0x139, 6}, {0x13a, 0x13a, 3}, {0x13b, 0x13b, 6}, {0x13c, 0x13c, 3}, {0x13d, 0x13d, 6}, {0x13e, 0x13e, 3}, {0x13f, 0x13f, 6}, {0x140, 0x140, 5}, {0x141, 0x141, 6}, {0x142, 0x142, 3}, {0x143, 0x143, 8}, {0x144, 0x144, 7}, {0x145, 0x145, 8}, {0x146, 0x146, 7}, {0x147, 0x147, 8}, {0x148, 0x148, 7}, {0x149, 0x14a, 8}, {0x14b, 0x14b, 7}, {0x14c, 0x14c, 8}, {0x14d, 0x14d, 6}, {0x14e, 0x14e, 8}, {0x14f, 0x14f, 6}, {0x150, 0x150, 8}, {0x151, 0x151, 6}, {0x152, 0x152, 11}, {0x153, 0x153, 10}, {0x154, 0x154, 7}, {0x155, 0x155, 5}, {0x156, 0x156, 7}, {0x157, 0x157, 5}, {0x158, 0x158, 7}, {0x159, 0x159, 5}, {0x15a, 0x15a, 7}, {0x15b, 0x15b, 6}, {0x15c, 0x15c, 7}, {0x15d, 0x15d, 6}, {0x15e, 0x15e, 7}, {0x15f, 0x15f, 6}, {0x160, 0x160, 7}, {0x161, 0x161, 6}, {0x162, 0x162, 7}, {0x163, 0x163, 4}, {0x164, 0x164, 7}, {0x165, 0x165, 4}, {0x166, 0x166, 7}, {0x167, 0x167, 4}, {0x168, 0x168, 8}, {0x169, 0x169, 7}, {0x16a, 0x16a, 8}, {0x16b, 0x16b, 7}, {0x16c, 0x16c, 8}, {0x16d, 0x16d, 7}, {0x16e, 0x16e, 8}, {0x16f, 0x16f, 7}, {0x170, 0x170, 8}, {0x171, 0x171, 7}, {0x172, 0x172, 8}, {0x173, 0x173, 7}, {0x174, 0x174, 10}, {0x175, 0x175, 9}, {0x176, 0x176, 7}, {0x177, 0x177, 6}, {0x178, 0x179, 7}, {0x17a, 0x17a, 6}, {0x17b, 0x17b, 7}, {0x17c, 0x17c, 6}, {0x17d, 0x17d, 7}, {0x17e, 0x17e, 6}, {0x17f, 0x17f, 3}, {0x192, 0x192, 7}, {0x1fa, 0x1fa, 7}, {0x1fb, 0x1fb, 6}, {0x1fc, 0x1fd, 10}, {0x1fe, 0x1fe, 8}, {0x1ff, 0x1ff, 6}, {0x2c6, 0x2c7, 7}, {0x2c9, 0x2c9, 7}, {0x2d8, 0x2dd, 7}, {0x37e, 0x37e, 5}, {0x384, 0x386, 7}, {0x387, 0x387, 5}, {0x388, 0x388, 8}, {0x389, 0x389, 9}, {0x38a, 0x38a, 6}, {0x38c, 0x38c, 9}, {0x38e, 0x38e, 8}, {0x38f, 0x38f, 9}, {0x390, 0x390, 3}, {0x391, 0x392, 7}, {0x393, 0x393, 6}, {0x394, 0x396, 7}, {0x397, 0x398, 8}, {0x399, 0x399, 5}, {0x39a, 0x39b, 7}, {0x39c, 0x39c, 9}, {0x39d, 0x39d, 8}, {0x39e, 0x39e, 7}, {0x39f, 0x3a0, 8}, {0x3a1, 0x3a1, 6}, {0x3a3, 0x3a5, 7}, {0x3a6, 0x3a6, 9}, {0x3a7, 0x3a7, 7}, {0x3a8, 0x3a9, 9}, {0x3aa, 0x3aa, 5}, {0x3ab, 0x3ac, 7}, {0x3ad, 0x3ad, 6}, {0x3ae, 0x3ae, 7}, {0x3af, 0x3af, 3}, {0x3b0, 0x3b2, 7}, {0x3b3, 0x3b5, 6}, {0x3b6, 0x3b6, 5}, {0x3b7, 0x3b8, 7}, {0x3b9, 0x3b9, 3}, {0x3ba, 0x3bb, 6}, {0x3bc, 0x3bc, 7}, {0x3bd, 0x3bd, 6}, {0x3be, 0x3be, 5}, {0x3bf, 0x3bf, 6}, {0x3c0, 0x3c1, 7}, {0x3c2, 0x3c2, 5}, {0x3c3, 0x3c3, 7}, {0x3c4, 0x3c4, 5}, {0x3c5, 0x3c5, 7}, {0x3c6, 0x3c6, 8}, {0x3c7, 0x3c7, 6}, {0x3c8, 0x3c9, 9}, {0x3ca, 0x3ca, 3}, {0x3cb, 0x3cb, 7}, {0x3cc, 0x3cc, 6}, {0x3cd, 0x3cd, 7}, {0x3ce, 0x3ce, 9}, {0x401, 0x401, 7}, {0x402, 0x402, 8}, {0x403, 0x403, 6}, {0x404, 0x405, 7}, {0x406, 0x408, 5}, {0x409, 0x409, 12}, {0x40a, 0x40a, 11}, {0x40b, 0x40b, 9}, {0x40c, 0x40c, 7}, {0x40e, 0x40e, 7}, {0x40f, 0x40f, 8}, {0x410, 0x412, 7}, {0x413, 0x413, 6}, {0x414, 0x414, 8}, {0x415, 0x415, 7}, {0x416, 0x416, 10}, {0x417, 0x417, 7}, {0x418, 0x419, 8}, {0x41a, 0x41a, 7}, {0x41b, 0x41b, 8}, {0x41c, 0x41c, 9}, {0x41d, 0x41f, 8}, {0x420, 0x420, 6}, {0x421, 0x423, 7}, {0x424, 0x424, 9}, {0x425, 0x425, 7}, {0x426, 0x427, 8}, {0x428, 0x429, 11}, {0x42a, 0x42a, 8}, {0x42b, 0x42b, 10}, {0x42c, 0x42d, 7}, {0x42e, 0x42e, 11}, {0x42f, 0x42f, 7}, {0x430, 0x430, 6}, {0x431, 0x431, 7}, {0x432, 0x432, 6}, {0x433, 0x433, 5}, {0x434, 0x434, 7}, {0x435, 0x435, 6}, {0x436, 0x436, 8}, {0x437, 0x437, 6}, {0x438, 0x439, 7}, {0x43a, 0x43a, 6}, {0x43b, 0x43d, 7}, {0x43e, 0x43e, 6}, {0x43f, 0x440, 7}, {0x441, 0x441, 6}, {0x442, 0x442, 5}, {0x443, 0x443, 6}, {0x444, 0x444, 9}, {0x445, 0x445, 6}, {0x446, 0x446, 7}, {0x447, 0x447, 6}, {0x448, 0x449, 9}, {0x44a, 0x44a, 7}, {0x44b, 0x44b, 8}, {0x44c, 0x44d, 6}, {0x44e, 0x44e, 9}, {0x44f, 0x44f, 6}, {0x451, 0x451, 6}, {0x452, 0x452, 7}, {0x453, 0x453, 5}, {0x454, 0x455, 6}, {0x456, 0x457, 3}, {0x458, 0x458, 4}, {0x459, 0x45a, 10}, {0x45b, 0x45b, 7}, {0x45c, 0x45c, 6}, {0x45e, 0x45e, 6}, {0x45f, 0x45f, 7}, {0x490, 0x490, 6}, {0x491, 0x491, 5}, {0x1e80, 0x1e80, 10}, {0x1e81, 0x1e81, 9}, {0x1e82, 0x1e82, 10}, {0x1e83, 0x1e83, 9}, {0x1e84, 0x1e84, 10}, {0x1e85, 0x1e85, 9}, {0x1ef2, 0x1ef2, 7}, {0x1ef3, 0x1ef3, 6}, {0x2013, 0x2013, 7}, {0x2014, 0x2015, 10}, {0x2017, 0x2017, 7}, {0x2018, 0x201b, 3}, {0x201c, 0x201e, 5}, {0x2020, 0x2021, 7}, {0x2022, 0x2022, 6}, {0x2026, 0x2026, 9}, {0x2030, 0x2030, 16}, {0x2032, 0x2032, 4}, {0x2033, 0x2033, 6}, {0x2039, 0x203a, 5}, {0x203c, 0x203c, 7}, {0x203e, 0x203e, 7}, {0x2044, 0x2044, 4}, {0x207f, 0x207f, 6}, {0x20a3, 0x20a4, 7}, {0x20a7, 0x20a7, 12}, {0x2105, 0x2105, 11}, {0x2113, 0x2113, 4}, {0x2116, 0x2116, 12}, {0x2122, 0x2122, 10}, {0x2126, 0x2126, 9}, {0x212e, 0x212e, 8}, {0x215b, 0x215e, 10}, {0x2202, 0x2202, 7}, {0x2206, 0x2206, 8}, {0x220f, 0x220f, 9}, {0x2211, 0x2211, 8}, {0x2212, 0x2212, 9}, {0x2215, 0x2215, 4}, {0x2219, 0x2219, 4}, {0x221a, 0x221a, 9}, {0x221e, 0x221e, 10}, {0x222b, 0x222b, 7}, {0x2248, 0x2248, 9}, {0x2260, 0x2260, 9}, {0x2264, 0x2265, 9}, {0x25a1, 0x25a1, 6}, {0x25aa, 0x25ab, 4}, {0x25ca, 0x25ca, 9}, {0x25cf, 0x25cf, 6}, {0x25e6, 0x25e6, 4}, {0xf001, 0xf002, 7}, {0xf004, 0xf004, 3}, {0xf005, 0xf005, 7}, {0xf006, 0xf00c, 6}, {0xf00d, 0xf00d, 0}, {0xfb01, 0xfb02, 7}}
+	// verdanaBold11CharWidths is a sorted list of character ranges with their bold widths from the Verdana font-family with font-size of 11px
+	verdanaBold11CharWidths = []charWidthRange{{0x20, 0x20, 4}, {0x21, 0x21, 5}, {0x22, 0x22, 6}, {0x23, 0x23, 9}, {0x24, 0x24, 7}, {0x25, 0x25, 12}, {0x26, 0x26, 8}, {0x27, 0x27, 3}, {0x28, 0x29, 5}, {0x2a, 0x2a, 7}, {0x2b, 0x2b, 9}, {0x2c, 0x2c, 4}, {0x2d, 0x2d, 5}, {0x2e, 0x2e, 4}, {0x2f, 0x2f, 5}, {0x30, 0x39, 7}, {0x3a, 0x3b, 5}, {0x3c, 0x3e, 9}, {0x3f, 0x3f, 6}, {0x40, 0x40, 11}, {0x41, 0x43, 8}, {0x44, 0x44, 9}, {0x45, 0x46, 7}, {0x47, 0x48, 9}, {0x49, 0x4a, 5}, {0x4b, 0x4b, 8}, {0x4c, 0x4c, 7}, {0x4d, 0x4d, 10}, {0x4e, 0x4f, 9}, {0x50, 0x50, 7}, {0x51, 0x51, 9}, {0x52, 0x53, 8}, {0x54, 0x54, 7}, {0x55, 0x55, 9}, {0x56, 0x56, 8}, {0x57, 0x57, 11}, {0x58, 0x58, 8}, {0x59, 0x59, 7}, {0x5a, 0x5a, 8}, {0x5b, 0x5d, 5}, {0x5e, 0x5e, 9}, {0x5f, 0x62, 7}, {0x63, 0x63, 6}, {0x64, 0x65, 7}, {0x66, 0x66, 4}, {0x67, 0x68, 7}, {0x69, 0x69, 3}, {0x6a, 0x6a, 4}, {0x6b, 0x6b, 7}, {0x6c, 0x6c, 3}, {0x6d, 0x6d, 11}, {0x6e, 0x71, 7}, {0x72, 0x72, 5}, {0x73, 0x73, 6}, {0x74, 0x74, 5}, {0x75, 0x76, 7}, {0x77, 0x77, 9}, {0x78, 0x79, 7}, {0x7a, 0x7a, 6}, {0x7b, 0x7b, 7}, {0x7c, 0x7c, 5}, {0x7d, 0x7d, 7}, {0x7e, 0x7e, 9}, {0xa0, 0xa0, 4}, {0xa1, 0xa1, 5}, {0xa2, 0xa5, 7}, {0xa6, 0xa6, 5}, {0xa7, 0xa8, 7}, {0xa9, 0xa9, 11}, {0xaa, 0xaa, 6}, {0xab, 0xab, 8}, {0xac, 0xac, 9}, {0xad, 0xad, 5}, {0xae, 0xae, 11}, {0xaf, 0xaf, 7}, {0xb0, 0xb0, 6}, {0xb1, 0xb1, 9}, {0xb2, 0xb3, 6}, {0xb4, 0xb4, 7}, {0xb5, 0xb5, 8}, {0xb6, 0xb6, 7}, {0xb7, 0xb7, 4}, {0xb8, 0xb8, 7}, {0xb9, 0xba, 6}, {0xbb, 0xbb, 8}, {0xbc, 0xbe, 11}, {0xbf, 0xbf, 6}, {0xc0, 0xc5, 8}, {0xc6, 0xc6, 11}, {0xc7, 0xc7, 8}, {0xc8, 0xcb, 7}, {0xcc, 0xcf, 5}, {0xd0, 0xdc, 9}, {0xdd, 0xe5, 7}, {0xe6, 0xe6, 11}, {0xe7, 0xe7, 6}, {0xe8, 0xeb, 7}, {0xec, 0xef, 3}, {0xf0, 0xf6, 7}, {0xf7, 0xf7, 9}, {0xf8, 0xff, 7}, {0x100, 0x100, 8}, {0x101, 0x101, 7}, {0x102, 0x102, 8}, {0x103, 0x103, 7}, {0x104, 0x104, 8}, {0x105, 0x105, 7}, {0x106, 0x106, 8}, {0x107, 0x107, 6}, {0x108, 0x108, 8}, {0x109, 0x109, 6}, {0x10a, 0x10a, 8}, {0x10b, 0x10b, 6}, {0x10c, 0x10c, 8}, {0x10d, 0x10d, 6}, {0x10e, 0x10e, 9}, {0x10f, 0x10f, 8}, {0x110, 0x110, 9}, {0x111, 0x11b, 7}, {0x11c, 0x11c, 9}, {0x11d, 0x11d, 7}, {0x11e, 0x11e, 9}, {0x11f, 0x11f, 7}, {0x120, 0x120, 9}, {0x121, 0x121, 7}, {0x122, 0x122, 9}, {0x123, 0x123, 7}, {0x124, 0x124, 9}, {0x125, 0x125, 7}, {0x126, 0x126, 9}, {0x127, 0x127, 7}, {0x128, 0x128, 5}, {0x129, 0x129, 3}, {0x12a, 0x12a, 5}, {0x12b, 0x12b, 3}, {0x12c, 0x12c, 5}, {0x12d, 0x12d, 3}, {0x12e, 0x12e, 5}, {0x12f, 0x12f, 3}, {0x130, 0x130, 5}, {0x131, 0x131, 3}, {0x132, 0x132, 10}, {0x133, 0x133, 7}, {0x134, 0x134, 5}, {0x135, 0x135, 4}, {0x136, 0x136, 8}, {0x137, 0x139, 7}, {0x13a, 0x13a, 3}, {0x13b, 0x13b, 7}, {0x13c, 0x13c, 3}, {0x13d, 0x13d, 7}, {0x13e, 0x13e, 4}, {0x13f, 0x13f, 7}, {0x140, 0x140, 6}, {0x141, 0x141, 7}, {0x142, 0x142, 4}, {0x143, 0x143, 9}, {0x144, 0x144, 7}, {0x145, 0x145, 9}, {0x146, 0x146, 7}, {0x147, 0x147, 9}, {0x148, 0x148, 7}, {0x149, 0x149, 8}, {0x14a, 0x14a, 9}, {0x14b, 0x14b, 7}, {0x14c, 0x14c, 9}, {0x14d, 0x14d, 7}, {0x14e, 0x14e, 9}, {0x14f, 0x14f, 7}, {0x150, 0x150, 9}, {0x151, 0x151, 7}, {0x152, 0x152, 12}, {0x153, 0x153, 11}, {0x154, 0x154, 8}, {0x155, 0x155, 5}, {0x156, 0x156, 8}, {0x157, 0x157, 5}, {0x158, 0x158, 8}, {0x159, 0x159, 5}, {0x15a, 0x15a, 8}, {0x15b, 0x15b, 6}, {0x15c, 0x15c, 8}, {0x15d, 0x15d, 6}, {0x15e, 0x15e, 8}, {0x15f, 0x15f, 6}, {0x160, 0x160, 8}, {0x161, 0x161, 6}, {0x162, 0x162, 7}, {0x163, 0x163, 5}, {0x164, 0x164, 7}, {0x165, 0x165, 5}, {0x166, 0x166, 7}, {0x167, 0x167, 5}, {0x168, 0x168, 9}, {0x169, 0x169, 7}, {0x16a, 0x16a, 9}, {0x16b, 0x16b, 7}, {0x16c, 0x16c, 9}, {0x16d, 0x16d, 7}, {0x16e, 0x16e, 9}, {0x16f, 0x16f, 7}, {0x170, 0x170, 9}, {0x171, 0x171, 7}, {0x172, 0x172, 9}, {0x173, 0x173, 7}, {0x174, 0x174, 11}, {0x175, 0x175, 9}, {0x176, 0x178, 7}, {0x179, 0x179, 8}, {0x17a, 0x17a, 6}, {0x17b, 0x17b, 8}, {0x17c, 0x17c, 6}, {0x17d, 0x17d, 8}, {0x17e, 0x17e, 6}, {0x17f, 0x17f, 4}, {0x192, 0x192, 7}, {0x1fa, 0x1fa, 8}, {0x1fb, 0x1fb, 7}, {0x1fc, 0x1fd, 11}, {0x1fe, 0x1fe, 9}, {0x1ff, 0x1ff, 7}, {0x2c6, 0x2c7, 7}, {0x2c9, 0x2c9, 7}, {0x2d8, 0x2dd, 7}, {0x37e, 0x37e, 5}, {0x384, 0x385, 7}, {0x386, 0x386, 8}, {0x387, 0x387, 5}, {0x388, 0x388, 9}, {0x389, 0x389, 10}, {0x38a, 0x38a, 6}, {0x38c, 0x38c, 10}, {0x38e, 0x38e, 9}, {0x38f, 0x38f, 10}, {0x390, 0x390, 3}, {0x391, 0x392, 8}, {0x393, 0x393, 7}, {0x394, 0x394, 8}, {0x395, 0x395, 7}, {0x396, 0x396, 8}, {0x397, 0x398, 9}, {0x399, 0x399, 5}, {0x39a, 0x39b, 8}, {0x39c, 0x39c, 10}, {0x39d, 0x39d, 9}, {0x39e, 0x39e, 8}, {0x39f, 0x3a0, 9}, {0x3a1, 0x3a1, 7}, {0x3a3, 0x3a3, 8}, {0x3a4, 0x3a5, 7}, {0x3a6, 0x3a6, 9}, {0x3a7, 0x3a7, 8}, {0x3a8, 0x3a8, 10}, {0x3a9, 0x3a9, 9}, {0x3aa, 0x3aa, 5}, {0x3ab, 0x3ac, 7}, {0x3ad, 0x3ad, 6}, {0x3ae, 0x3ae, 7}, {0x3af, 0x3af, 3}, {0x3b0, 0x3b4, 7}, {0x3b5, 0x3b5, 6}, {0x3b6, 0x3b6, 5}, {0x3b7, 0x3b8, 7}, {0x3b9, 0x3b9, 3}, {0x3ba, 0x3bd, 7}, {0x3be, 0x3be, 6}, {0x3bf, 0x3c1, 7}, {0x3c2, 0x3c2, 6}, {0x3c3, 0x3c3, 7}, {0x3c4, 0x3c4, 6}, {0x3c5, 0x3c5, 7}, {0x3c6, 0x3c6, 9}, {0x3c7, 0x3c7, 7}, {0x3c8, 0x3c9, 9}, {0x3ca, 0x3ca, 3}, {0x3cb, 0x3cd, 7}, {0x3ce, 0x3ce, 9}, {0x401, 0x401, 7}, {0x402, 0x402, 9}, {0x403, 0x403, 7}, {0x404, 0x405, 8}, {0x406, 0x408, 5}, {0x409, 0x40a, 13}, {0x40b, 0x40b, 9}, {0x40c, 0x40c, 8}, {0x40e, 0x40e, 7}, {0x40f, 0x40f, 9}, {0x410, 0x412, 8}, {0x413, 0x413, 7}, {0x414, 0x414, 9}, {0x415, 0x415, 7}, {0x416, 0x416, 11}, {0x417, 0x417, 7}, {0x418, 0x419, 9}, {0x41a, 0x41a, 8}, {0x41b, 0x41b, 9}, {0x41c, 0x41c, 10}, {0x41d, 0x41f, 9}, {0x420, 0x420, 7}, {0x421, 0x421, 8}, {0x422, 0x423, 7}, {0x424, 0x424, 9}, {0x425, 0x425, 8}, {0x426, 0x426, 9}, {0x427, 0x427, 8}, {0x428, 0x429, 12}, {0x42a, 0x42a, 9}, {0x42b, 0x42b, 11}, {0x42c, 0x42d, 8}, {0x42e, 0x42e, 12}, {0x42f, 0x42f, 8}, {0x430, 0x432, 7}, {0x433, 0x433, 6}, {0x434, 0x435, 7}, {0x436, 0x436, 9}, {0x437, 0x437, 6}, {0x438, 0x43b, 7}, {0x43c, 0x43c, 8}, {0x43d, 0x440, 7}, {0x441, 0x442, 6}, {0x443, 0x443, 7}, {0x444, 0x444, 10}, {0x445, 0x445, 7}, {0x446, 0x446, 8}, {0x447, 0x447, 7}, {0x448, 0x449, 10}, {0x44a, 0x44a, 8}, {0x44b, 0x44b, 9}, {0x44c, 0x44c, 7}, {0x44d, 0x44d, 6}, {0x44e, 0x44e, 10}, {0x44f, 0x44f, 7}, {0x451, 0x452, 7}, {0x453, 0x455, 6}, {0x456, 0x457, 3}, {0x458, 0x458, 4}, {0x459, 0x45a, 11}, {0x45b, 0x45c, 7}, {0x45e, 0x45f, 7}, {0x490, 0x490, 7}, {0x491, 0x491, 6}, {0x1e80, 0x1e80, 11}, {0x1e81, 0x1e81, 9}, {0x1e82, 0x1e82, 11}, {0x1e83, 0x1e83, 9}, {0x1e84, 0x1e84, 11}, {0x1e85, 0x1e85, 9}, {0x1ef2, 0x1ef3, 7}, {0x2013, 0x2013, 7}, {0x2014, 0x2015, 11}, {0x2017, 0x2017, 7}, {0x2018, 0x201b, 3}, {0x201c, 0x201e, 6}, {0x2020, 0x2021, 7}, {0x2022, 0x2022, 6}, {0x2026, 0x2026, 9}, {0x2030, 0x2030, 17}, {0x2032, 0x2032, 4}, {0x2033, 0x2033, 7}, {0x2039, 0x203a, 5}, {0x203c, 0x203c, 7}, {0x203e, 0x203e, 7}, {0x2044, 0x2044, 4}, {0x207f, 0x207f, 6}, {0x20a3, 0x20a4, 7}, {0x20a7, 0x20a7, 13}, {0x2105, 0x2105, 12}, {0x2113, 0x2113, 4}, {0x2116, 0x2116, 13}, {0x2122, 0x2122, 11}, {0x2126, 0x2126, 9}, {0x212e, 0x212e, 8}, {0x215b, 0x215e, 11}, {0x2202, 0x2202, 7}, {0x2206, 0x2206, 8}, {0x220f, 0x220f, 9}, {0x2211, 0x2211, 8}, {0x2212, 0x2212, 9}, {0x2215, 0x2215, 4}, {0x2219, 0x2219, 4}, {0x221a, 0x221a, 9}, {0x221e, 0x221e, 11}, {0x222b, 0x222b, 7}, {0x2248, 0x2248, 9}, {0x2260, 0x2260, 9}, {0x2264, 0x2265, 9}, {0x25a1, 0x25a1, 7}, {0x25aa, 0x25ab, 4}, {0x25ca, 0x25ca, 9}, {0x25cf, 0x25cf, 7}, {0x25e6, 0x25e6, 4}, {0xf001, 0xf002, 7}, {0xf004, 0xf004, 3}, {0xf005, 0xf005, 7}, {0xf006, 0xf00c, 6}, {0xf00d, 0xf00d, 0}, {0xfb01, 0xfb02, 7}}
 )
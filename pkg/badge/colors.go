@@ -1,8 +1,12 @@
 package badge
 
 import (
+	"image/color"
 	"regexp"
+	"strconv"
 	"strings"
+
+	"golang.org/x/image/colornames"
 )
 
 var cssColorNames = map[string]struct{}{
@@ -183,3 +187,24 @@ func parseColor(str string) string {
 
 	return ""
 }
+
+// colorToRGBA converts a color parsed by `parseColor` into its RGBA value
+func colorToRGBA(str string) (color.RGBA, bool) {
+	if rgba, ok := colornames.Map[str]; ok {
+		return rgba, true
+	}
+	if !isValidHexColor(str) {
+		return color.RGBA{}, false
+	}
+
+	hex := strings.TrimPrefix(str, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}, true
+}
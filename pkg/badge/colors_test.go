@@ -1,6 +1,7 @@
 package badge
 
 import (
+	"image/color"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -90,3 +91,35 @@ func TestParseColor(t *testing.T) {
 	assert.Equal(t, "", parseColor("#f7baa"))
 	assert.Equal(t, "", parseColor("#f7b1"))
 }
+
+func TestColorToRGBA(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected color.RGBA
+		ok       bool
+	}{
+		{"#f7b137", color.RGBA{0xf7, 0xb1, 0x37, 0xff}, true},
+		{"f7b137", color.RGBA{0xf7, 0xb1, 0x37, 0xff}, true},
+		{"#fff", color.RGBA{0xff, 0xff, 0xff, 0xff}, true},
+		{"#888", color.RGBA{0x88, 0x88, 0x88, 0xff}, true},
+		{"red", color.RGBA{0xff, 0x00, 0x00, 0xff}, true},
+		{"mediumturquoise", color.RGBA{0x48, 0xd1, 0xcc, 0xff}, true},
+		{"rainbow", color.RGBA{}, false},
+		{"#f7b137aa", color.RGBA{}, false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			result, ok := colorToRGBA(testCase.input)
+			assert.Equal(t, testCase.ok, ok)
+			assert.Equal(t, testCase.expected, result)
+		})
+	}
+
+	for cssColorName := range cssColorNames {
+		_, ok := colorToRGBA(cssColorName)
+		assert.True(t, ok, cssColorName)
+	}
+}
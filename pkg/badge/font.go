@@ -1,35 +1,322 @@
 package badge
 
-import "fmt"
+import (
+	_ "embed" // embed Verdana font for measuring & rasterizing badge texts
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultFontFamily is the font family of all built-in badge styles
+const DefaultFontFamily = "Verdana"
+
+// Range of supported font sizes, which fit within the height of badges
+const (
+	MinFontSize = 8
+	MaxFontSize = 14
+)
 
 const fallbackCharCode = 64 // @
 
-func computeTextWidth(text string, fontSize int, fontFamily string) (int, error) {
-	textArray := []rune(text)
-	textWidth := 0
+const zeroWidthJoiner = '\u200d'
 
-	var charWidthTable []int
-	switch fontFamily {
-	case "Verdana":
-		switch fontSize {
-		case 9:
-			charWidthTable = verdana9CharWidths[:]
-		case 11:
-			charWidthTable = verdana11CharWidths[:]
-		default:
-			return 0, fmt.Errorf("unsupported font size: %d", fontSize)
+//go:embed assets/fonts/Verdana.ttf
+var verdanaTTF []byte
+
+// fallbackCharWidths approximates the widths (in em) of characters of scripts that are usually not covered by fonts,
+// which are rendered by browsers with fallback fonts
+var fallbackCharWidths = []struct {
+	First rune
+	Last  rune
+	Width float64
+}{
+	{0x0370, 0x03ff, 0.65}, // Greek and Coptic
+	{0x0400, 0x052f, 0.65}, // Cyrillic, Cyrillic Supplement
+	{0x0590, 0x05ff, 0.6},  // Hebrew
+	{0x0600, 0x06ff, 0.5},  // Arabic
+	{0x0750, 0x077f, 0.5},  // Arabic Supplement
+	{0x1100, 0x115f, 1},    // Hangul Jamo (leading consonants)
+	{0x2600, 0x27bf, 1},    // Miscellaneous Symbols, Dingbats
+	{0x2e80, 0x303e, 1},    // CJK Radicals Supplement ... CJK Symbols and Punctuation
+	{0x3041, 0x33ff, 1},    // Hiragana ... CJK Compatibility
+	{0x3400, 0x4dbf, 1},    // CJK Unified Ideographs Extension A
+	{0x4e00, 0x9fff, 1},    // CJK Unified Ideographs
+	{0xac00, 0xd7a3, 1},    // Hangul Syllables
+	{0xf900, 0xfaff, 1},    // CJK Compatibility Ideographs
+	{0xfb1d, 0xfb4f, 0.6},  // Hebrew Presentation Forms
+	{0xfb50, 0xfdff, 0.5},  // Arabic Presentation Forms-A
+	{0xfe30, 0xfe4f, 1},    // CJK Compatibility Forms
+	{0xfe70, 0xfeff, 0.5},  // Arabic Presentation Forms-B
+	{0xff00, 0xff60, 1},    // Fullwidth Forms
+	{0xffe0, 0xffe6, 1},    // Fullwidth Signs
+	// Emoji, as wide as the glyphs of Noto Color Emoji
+	{0x1f1e6, 0x1f1ff, 1.25}, // Regional Indicator Symbols
+	{0x1f300, 0x1f64f, 1.25}, // Miscellaneous Symbols and Pictographs, Emoticons
+	{0x1f680, 0x1f6ff, 1.25}, // Transport and Map Symbols
+	{0x1f900, 0x1f9ff, 1.25}, // Supplemental Symbols and Pictographs
+	{0x1fa70, 0x1faff, 1.25}, // Symbols and Pictographs Extended-A
+	{0x20000, 0x3fffd, 1},    // CJK Unified Ideographs Extension B ... Extension H
+}
+
+var (
+	// emojiRanges covers common emoji, which are rendered as a single glyph when joined by zero width joiners
+	emojiRanges = &unicode.RangeTable{
+		R16: []unicode.Range16{{Lo: 0x2600, Hi: 0x27bf, Stride: 1}},
+		R32: []unicode.Range32{
+			{Lo: 0x1f1e6, Hi: 0x1f1ff, Stride: 1},
+			{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+			{Lo: 0x1f680, Hi: 0x1f6ff, Stride: 1},
+			{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+			{Lo: 0x1fa70, Hi: 0x1faff, Stride: 1},
+		},
+	}
+	// emojiModifierRanges covers the skin tone modifiers of emoji
+	emojiModifierRanges = &unicode.RangeTable{R32: []unicode.Range32{{Lo: 0x1f3fb, Hi: 0x1f3ff, Stride: 1}}}
+	// regionalIndicatorRanges covers the regional indicator symbols, which are rendered as flags in pairs
+	regionalIndicatorRanges = &unicode.RangeTable{R32: []unicode.Range32{{Lo: 0x1f1e6, Hi: 0x1f1ff, Stride: 1}}}
+)
+
+// fontFamilyNamePattern matches valid names of registered font families
+var fontFamilyNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]*$`)
+
+var (
+	registeredFonts      = make(map[string]*fontFamily)
+	registeredFontsMutex sync.RWMutex
+
+	verdanaFont     *fontFamily
+	verdanaFontErr  error
+	verdanaFontOnce sync.Once
+)
+
+// charWidthRange is a range of consecutive characters with the same width
+type charWidthRange struct {
+	First rune
+	Last  rune
+	Width int
+}
+
+// fontMetrics identifies the character widths of a font family with a font size & weight
+type fontMetrics struct {
+	FontSize int
+	Bold     bool
+}
+
+// fontFamily holds the fonts of a font family, parsed from TrueType files
+type fontFamily struct {
+	Regular *sfnt.Font
+	// Bold is nil if bold texts are emboldened from the regular font
+	Bold *sfnt.Font
+	// CharWidths holds character widths computed by gen.go, which are used instead of the fonts when available
+	CharWidths map[fontMetrics][]charWidthRange
+}
+
+// RegisterFont registers a font family parsed from the TrueType files of its regular & (optional) bold weights, so
+// that badges can be rendered with it (see `Params.FontFamily`). Bold texts are emboldened from the regular weight if
+// there is no bold TrueType file. Registering an existing font family replaces it, except for the built-in Verdana
+// font family which cannot be replaced.
+func RegisterFont(family string, regular []byte, bold []byte) error {
+	newFontFamily, err := parseFontFamily(family, regular, bold)
+	if err != nil {
+		return err
+	}
+
+	registeredFontsMutex.Lock()
+	defer registeredFontsMutex.Unlock()
+	registeredFonts[family] = newFontFamily
+
+	return nil
+}
+
+// ValidateFont checks that a font family can be registered with the given TrueType files (see `RegisterFont`),
+// without registering it
+func ValidateFont(family string, regular []byte, bold []byte) error {
+	_, err := parseFontFamily(family, regular, bold)
+	return err
+}
+
+// UnregisterFont unregisters a font family registered with `RegisterFont`
+func UnregisterFont(family string) {
+	registeredFontsMutex.Lock()
+	defer registeredFontsMutex.Unlock()
+	delete(registeredFonts, family)
+}
+
+// parseFontFamily parses the TrueType files of the regular & (optional) bold weights of a font family
+func parseFontFamily(family string, regular []byte, bold []byte) (*fontFamily, error) {
+	if !fontFamilyNamePattern.MatchString(family) {
+		return nil, fmt.Errorf("Invalid font family name: %q", family)
+	}
+	if family == DefaultFontFamily {
+		return nil, fmt.Errorf("Built-in font family cannot be replaced: %s", family)
+	}
+
+	newFontFamily := &fontFamily{}
+	var err error
+	if newFontFamily.Regular, err = sfnt.Parse(regular); err != nil {
+		return nil, fmt.Errorf("Invalid font of font family %s: %v", family, err)
+	}
+	if bold != nil {
+		if newFontFamily.Bold, err = sfnt.Parse(bold); err != nil {
+			return nil, fmt.Errorf("Invalid bold font of font family %s: %v", family, err)
 		}
-	default:
-		return 0, fmt.Errorf("unsupported font family: %s", fontFamily)
 	}
 
-	charWidthTableSize := len(charWidthTable)
-	for _, character := range textArray {
-		charCode := int(character)
-		if charCode > charWidthTableSize {
-			charCode = fallbackCharCode
+	return newFontFamily, nil
+}
+
+// Fonts returns all supported font families, including registered font families
+func Fonts() []string {
+	registeredFontsMutex.RLock()
+	defer registeredFontsMutex.RUnlock()
+
+	registered := make([]string, 0, len(registeredFonts))
+	for family := range registeredFonts {
+		registered = append(registered, family)
+	}
+	sort.Strings(registered)
+
+	return append([]string{DefaultFontFamily}, registered...)
+}
+
+// loadFontFamily returns the fonts of a built-in or registered font family
+func loadFontFamily(family string) (*fontFamily, error) {
+	if family == DefaultFontFamily {
+		verdanaFontOnce.Do(func() {
+			var regular *sfnt.Font
+			if regular, verdanaFontErr = sfnt.Parse(verdanaTTF); verdanaFontErr == nil {
+				verdanaFont = &fontFamily{
+					Regular: regular,
+					CharWidths: map[fontMetrics][]charWidthRange{
+						{FontSize: 9}:              verdana9CharWidths,
+						{FontSize: 11}:             verdana11CharWidths,
+						{FontSize: 10, Bold: true}: verdanaBold10CharWidths,
+						{FontSize: 11, Bold: true}: verdanaBold11CharWidths,
+					},
+				}
+			}
+		})
+		return verdanaFont, verdanaFontErr
+	}
+
+	registeredFontsMutex.RLock()
+	defer registeredFontsMutex.RUnlock()
+	if registeredFont, ok := registeredFonts[family]; ok {
+		return registeredFont, nil
+	}
+
+	return nil, fmt.Errorf("unsupported font family: %s", family)
+}
+
+// face returns the font of the given weight, and whether it has to be emboldened
+func (family *fontFamily) face(bold bool) (*sfnt.Font, bool) {
+	if bold && family.Bold != nil {
+		return family.Bold, false
+	}
+	return family.Regular, bold
+}
+
+// charWidth returns the width of a character with the given font size & weight, or false if neither the font family
+// nor `fallbackCharWidths` covers the character
+func (family *fontFamily) charWidth(buf *sfnt.Buffer, character rune, fontSize int, bold bool) (int, bool, error) {
+	face, embolden := family.face(bold)
+	if charWidths, ok := family.CharWidths[fontMetrics{FontSize: fontSize, Bold: bold}]; ok {
+		if width, ok := lookupCharWidth(charWidths, character); ok {
+			return width, true, nil
+		}
+	}
+
+	// characters missing from the pre-computed widths (eg. Greek & Cyrillic for Verdana) are measured with the font
+	glyphIndex, err := face.GlyphIndex(buf, character)
+	if err != nil {
+		return 0, false, err
+	}
+	if glyphIndex != 0 {
+		advance, err := face.GlyphAdvance(buf, glyphIndex, fixed.I(int(face.UnitsPerEm())), font.HintingNone)
+		if err != nil {
+			return 0, false, err
+		}
+		return scaleCharWidth(advance.Round(), int(face.UnitsPerEm()), fontSize, embolden), true, nil
+	}
+
+	for _, fallback := range fallbackCharWidths {
+		if fallback.First <= character && character <= fallback.Last {
+			advance := int(math.Round(fallback.Width * float64(face.UnitsPerEm())))
+			return scaleCharWidth(advance, int(face.UnitsPerEm()), fontSize, embolden), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// scaleCharWidth converts the advance width of a character (in font units) into its width with the given font size.
+// Like gen.go, emboldened characters are widened by 1/24 em.
+func scaleCharWidth(advance int, unitsPerEm int, fontSize int, embolden bool) int {
+	if embolden && advance > 0 {
+		advance += unitsPerEm / 24
+	}
+	return int(math.Round(float64(advance) / float64(unitsPerEm) * float64(fontSize)))
+}
+
+// lookupCharWidth returns the width of a character from a sorted list of character ranges
+func lookupCharWidth(charWidths []charWidthRange, character rune) (int, bool) {
+	i := sort.Search(len(charWidths), func(i int) bool { return charWidths[i].Last >= character })
+	if i < len(charWidths) && charWidths[i].First <= character {
+		return charWidths[i].Width, true
+	}
+	return 0, false
+}
+
+func computeTextWidth(text string, fontSize int, fontFamily string, fontWeight string) (int, error) {
+	textWidth := 0
+
+	family, err := loadFontFamily(fontFamily)
+	if err != nil {
+		return 0, err
+	}
+	if fontWeight != "" && fontWeight != "bold" {
+		return 0, fmt.Errorf("unsupported font weight: %q", fontWeight)
+	}
+	bold := fontWeight == "bold"
+
+	var buf sfnt.Buffer
+	fallbackCharWidth, _, err := family.charWidth(&buf, fallbackCharCode, fontSize, bold)
+	if err != nil {
+		return 0, err
+	}
+
+	var previous rune
+	regionalIndicators := 0
+	for _, character := range text {
+		if unicode.Is(regionalIndicatorRanges, character) {
+			regionalIndicators++
+		} else {
+			regionalIndicators = 0
+		}
+
+		switch {
+		case unicode.In(character, unicode.Mn, unicode.Me, unicode.Cf):
+			// Combining marks & format characters (eg. variation selectors, joiners & bidi controls) take no space
+		case previous == zeroWidthJoiner && unicode.Is(emojiRanges, character),
+			unicode.Is(emojiModifierRanges, character) && unicode.Is(emojiRanges, previous),
+			regionalIndicators%2 == 0 && regionalIndicators > 0:
+			// Emoji sequences are rendered as a single glyph, as wide as their first emoji
+		default:
+			charWidth, ok, err := family.charWidth(&buf, character, fontSize, bold)
+			if err != nil {
+				return 0, err
+			}
+			if !ok {
+				charWidth = fallbackCharWidth
+			}
+			textWidth += charWidth
 		}
-		textWidth += charWidthTable[charCode]
+		previous = character
 	}
 
 	return textWidth, nil
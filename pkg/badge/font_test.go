@@ -0,0 +1,134 @@
+package badge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestComputeTextWidth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		text       string
+		fontSize   int
+		fontWeight string
+		expected   int
+	}{
+		{"latin", "build", 11, "", 27},
+		{"latin-1 supplement", "ÿ", 11, "", 7},
+		{"greek", "Ωμέγα", 11, "", 36},
+		{"cyrillic", "сборка", 11, "", 41},
+		{"cjk", "ビルド状態", 11, "", 55},
+		{"hangul", "빌드", 9, "", 18},
+		{"hebrew", "בנייה", 11, "", 35},
+		{"arabic", "بناء", 11, "", 24},
+		{"emoji", "🚀", 11, "", 14},
+		{"unsupported characters", "\u0800", 11, "", 11},
+		{"combining marks", "e\u0301", 11, "", 7},
+		{"zero width joiner sequence", "\U0001f468\u200d\U0001f469\u200d\U0001f467", 11, "", 14},
+		{"emoji modifier", "\U0001f44d\U0001f3fd", 11, "", 14},
+		{"variation selector", "\u2764\ufe0f", 11, "", 11},
+		{"flags", "\U0001f1ef\U0001f1f5\U0001f1f0\U0001f1f7", 11, "", 28},
+		{"bold", "ビルド", 10, "bold", 30},
+		{"font size without precomputed widths", "build", 13, "", 32},
+		{"bold font size without precomputed widths", "build", 13, "bold", 35},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			textWidth, err := computeTextWidth(test.text, test.fontSize, "Verdana", test.fontWeight)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, textWidth)
+		})
+	}
+
+	_, err := computeTextWidth("build", 11, "Verdana", "light")
+	assert.EqualError(t, err, `unsupported font weight: "light"`)
+	_, err = computeTextWidth("build", 11, "Arial", "")
+	assert.EqualError(t, err, "unsupported font family: Arial")
+}
+
+func TestCharWidth(t *testing.T) {
+	t.Parallel()
+
+	regular, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	measured := &fontFamily{Regular: regular}
+	precomputed := &fontFamily{Regular: regular, CharWidths: map[fontMetrics][]charWidthRange{
+		{FontSize: 11}: {{First: 'a', Last: 'a', Width: 42}},
+	}}
+	var buf sfnt.Buffer
+
+	width, ok, err := precomputed.charWidth(&buf, 'a', 11, false)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 42, width)
+
+	// Characters missing from the precomputed widths are measured with the font, before using the fallback widths
+	expectedWidth, _, err := measured.charWidth(&buf, 'b', 11, false)
+	assert.NoError(t, err)
+	width, ok, err = precomputed.charWidth(&buf, 'b', 11, false)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, expectedWidth, width)
+	assert.Equal(t, 6, width)
+}
+
+func TestRegisterFont(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, RegisterFont("Test Go", goregular.TTF, gobold.TTF))
+	assert.NoError(t, RegisterFont("Test Go Regular", goregular.TTF, nil))
+	assert.Contains(t, Fonts(), "Test Go")
+
+	regularWidth, err := computeTextWidth("build", 11, "Test Go", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 24, regularWidth)
+	boldWidth, err := computeTextWidth("build", 11, "Test Go", "bold")
+	assert.NoError(t, err)
+	assert.Equal(t, 27, boldWidth)
+	emboldenedWidth, err := computeTextWidth("build", 11, "Test Go Regular", "bold")
+	assert.NoError(t, err)
+	assert.Equal(t, 27, emboldenedWidth)
+
+	// Fallback widths are only emboldened for font families without a bold font
+	boldFallbackWidth, err := computeTextWidth("ビルド", 14, "Test Go", "bold")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, boldFallbackWidth)
+	emboldenedFallbackWidth, err := computeTextWidth("ビルド", 14, "Test Go Regular", "bold")
+	assert.NoError(t, err)
+	assert.Equal(t, 45, emboldenedFallbackWidth)
+
+	newBadge, err := Create(&Params{Subject: "build", Status: "passing", FontFamily: "Test Go", FontSize: 12})
+	assert.NoError(t, err)
+	newBadgeParams, err := ExtractParams(newBadge)
+	assert.NoError(t, err)
+	assert.Equal(t, Params{Style: DefaultStyle, Subject: "build", Status: "passing", Color: DefaultColor,
+		FontFamily: "Test Go", FontSize: 12}, *newBadgeParams)
+	_, err = CreatePNG(&Params{Style: ForTheBadgeStyle, Subject: "build", Status: "passing", FontFamily: "Test Go"})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, RegisterFont(DefaultFontFamily, goregular.TTF, nil),
+		"Built-in font family cannot be replaced: Verdana")
+	assert.EqualError(t, RegisterFont("Invalid,Name", goregular.TTF, nil), `Invalid font family name: "Invalid,Name"`)
+	assert.Error(t, RegisterFont("Test Invalid", []byte("not a font"), nil))
+	assert.Error(t, RegisterFont("Test Invalid Bold", goregular.TTF, []byte("not a font")))
+	assert.NotContains(t, Fonts(), "Test Invalid")
+
+	// Validated font families aren't registered
+	assert.NoError(t, ValidateFont("Test Validated", goregular.TTF, gobold.TTF))
+	assert.NotContains(t, Fonts(), "Test Validated")
+	assert.Error(t, ValidateFont("Test Invalid", []byte("not a font"), nil))
+
+	assert.NoError(t, RegisterFont("Test Unregistered", goregular.TTF, nil))
+	UnregisterFont("Test Unregistered")
+	UnregisterFont(DefaultFontFamily)
+	assert.NotContains(t, Fonts(), "Test Unregistered")
+	assert.Contains(t, Fonts(), DefaultFontFamily)
+}
@@ -14,13 +14,19 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"unicode"
 
-	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
 	"golang.org/x/image/math/fixed"
 )
 
-// Support up to Latin-1 Supplement (Unicode block)
-const maxCharCode = 0xFF
+// charWidthRange is a range of consecutive characters with the same width
+type charWidthRange struct {
+	First rune
+	Last  rune
+	Width int
+}
 
 type datum struct {
 	Name  string
@@ -35,18 +41,21 @@ func main() {
 }
 
 func run() error {
-	var Verdana9CharWidths = make([]int, maxCharCode)
-	var Verdana11CharWidths = make([]int, maxCharCode)
-	for i := 0; i < maxCharCode; i++ {
-		var err error
-		Verdana9CharWidths[i], err = computeCharWidth("Verdana", 9, rune(i))
-		if err != nil {
-			return err
-		}
-		Verdana11CharWidths[i], err = computeCharWidth("Verdana", 11, rune(i))
-		if err != nil {
-			return err
-		}
+	Verdana9CharWidths, err := computeCharWidths("Verdana", 9, false)
+	if err != nil {
+		return err
+	}
+	Verdana11CharWidths, err := computeCharWidths("Verdana", 11, false)
+	if err != nil {
+		return err
+	}
+	VerdanaBold10CharWidths, err := computeCharWidths("Verdana", 10, true)
+	if err != nil {
+		return err
+	}
+	VerdanaBold11CharWidths, err := computeCharWidths("Verdana", 11, true)
+	if err != nil {
+		return err
 	}
 
 	badgeTemplates := make([]datum, 0)
@@ -90,10 +99,12 @@ func run() error {
 	}
 
 	data := map[string]interface{}{
-		"Icons":               icons,
-		"Templates":           badgeTemplates,
-		"Verdana9CharWidths":  Verdana9CharWidths,
-		"Verdana11CharWidths": Verdana11CharWidths,
+		"Icons":                   icons,
+		"Templates":               badgeTemplates,
+		"Verdana9CharWidths":      Verdana9CharWidths,
+		"Verdana11CharWidths":     Verdana11CharWidths,
+		"VerdanaBold10CharWidths": VerdanaBold10CharWidths,
+		"VerdanaBold11CharWidths": VerdanaBold11CharWidths,
 	}
 	for filename, t := range templates {
 		var buf bytes.Buffer
@@ -119,24 +130,60 @@ func run() error {
 	return nil
 }
 
-func computeCharWidth(fontFamily string, fontSize int, character rune) (int, error) {
+// computeCharWidths computes the widths of all characters covered by the font, merging
+// consecutive characters with the same width into ranges. Bold widths are computed from "<FONT>-Bold.ttf" if it
+// exists, otherwise they are approximated by emboldening the regular font like FreeType (widening advances by 1/24 em).
+func computeCharWidths(fontFamily string, fontSize int, bold bool) ([]charWidthRange, error) {
 	filePath := fmt.Sprintf("assets/fonts/%s.ttf", fontFamily)
+	synthesizeBold := false
+	if bold {
+		boldFilePath := fmt.Sprintf("assets/fonts/%s-Bold.ttf", fontFamily)
+		if _, err := os.Stat(boldFilePath); err == nil {
+			filePath = boldFilePath
+		} else {
+			synthesizeBold = true
+		}
+	}
 	ttf, err := os.ReadFile(filePath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open font file: %v", err)
+		return nil, fmt.Errorf("failed to open font file: %v", err)
 	}
 
-	font, err := truetype.Parse(ttf)
+	face, err := sfnt.Parse(ttf)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse font file: %v", err)
+		return nil, fmt.Errorf("failed to parse font file: %v", err)
 	}
 
-	fUnitsPerEm := fixed.Int26_6(font.FUnitsPerEm())
-	ttIndex := font.Index(character)
-	horizontalMetric := font.HMetric(fUnitsPerEm, ttIndex)
-	charWidth := horizontalMetric.AdvanceWidth
+	var buf sfnt.Buffer
+	unitsPerEm := int(face.UnitsPerEm())
+	charWidths := make([]charWidthRange, 0)
+	for character := rune(0); character <= unicode.MaxRune; character++ {
+		glyphIndex, err := face.GlyphIndex(&buf, character)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up glyph of %U: %v", character, err)
+		}
+		if glyphIndex == 0 {
+			continue
+		}
+		advance, err := face.GlyphAdvance(&buf, glyphIndex, fixed.I(unitsPerEm), font.HintingNone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get glyph advance of %U: %v", character, err)
+		}
+		charWidth := advance.Round()
+		if synthesizeBold && charWidth > 0 {
+			charWidth += unitsPerEm / 24
+		}
+		width := int(math.Round(float64(charWidth) / float64(unitsPerEm) * float64(fontSize)))
 
-	return int(math.Round(float64(charWidth) / float64(fUnitsPerEm) * float64(fontSize))), nil
+		if last := len(charWidths) - 1; last >= 0 && charWidths[last].Last == character-1 &&
+			charWidths[last].Width == width {
+			charWidths[last].Last = character
+		} else {
+			charWidths = append(charWidths, charWidthRange{First: character, Last: character, Width: width})
+		}
+	}
+
+	return charWidths, nil
 }
 
 // Filename -> Template.
@@ -145,10 +192,14 @@ var templates = map[string]*template.Template{
 package badge
 
 var (
-	// verdana9CharWidths is an array of character widths from the Verdana font-family with font-size of 9px & indexed by their respective UTF-16 code unit
-	verdana9CharWidths = {{.Verdana9CharWidths | stringifyIntSlice}}
-	// verdana11CharWidths is an array of character widths from the Verdana font-family with font-size of 11px & indexed by their respective UTF-16 code unit
-	verdana11CharWidths = {{.Verdana11CharWidths | stringifyIntSlice}}
+	// verdana9CharWidths is a sorted list of character ranges with their widths from the Verdana font-family with font-size of 9px
+	verdana9CharWidths = {{.Verdana9CharWidths | stringifyCharWidths}}
+	// verdana11CharWidths is a sorted list of character ranges with their widths from the Verdana font-family with font-size of 11px
+	verdana11CharWidths = {{.Verdana11CharWidths | stringifyCharWidths}}
+	// verdanaBold10CharWidths is a sorted list of character ranges with their bold widths from the Verdana font-family with font-size of 10px
+	verdanaBold10CharWidths = {{.VerdanaBold10CharWidths | stringifyCharWidths}}
+	// verdanaBold11CharWidths is a sorted list of character ranges with their bold widths from the Verdana font-family with font-size of 11px
+	verdanaBold11CharWidths = {{.VerdanaBold11CharWidths | stringifyCharWidths}}
 )
 `),
 	"icons.go": t(`// Code generated by gen.go; DO NOT EDIT.
@@ -180,8 +231,12 @@ func t(text string) *template.Template {
 		"minifyString": func(s string) string {
 			return regexp.MustCompile(`[\n\r\t]`).ReplaceAllString(s, "")
 		},
-		"stringifyIntSlice": func(s []int) string {
-			return fmt.Sprintf("[...]int {%s}", strings.Trim(strings.Join(strings.Fields(fmt.Sprint(s)), ","), "[]"))
+		"stringifyCharWidths": func(s []charWidthRange) string {
+			ranges := make([]string, len(s))
+			for i, r := range s {
+				ranges[i] = fmt.Sprintf("{%#x, %#x, %d}", r.First, r.Last, r.Width)
+			}
+			return fmt.Sprintf("[]charWidthRange{%s}", strings.Join(ranges, ", "))
 		},
 	}).Parse(text))
 }
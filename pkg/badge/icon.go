@@ -0,0 +1,281 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxLogoSize is the maximum size (in bytes) of the SVG icon of a logo data URI
+const MaxLogoSize = 16 * 1024
+
+// logoPrefix is the prefix of logo data URIs
+const logoPrefix = "data:image/svg+xml;base64,"
+
+const (
+	svgNamespace   = "http://www.w3.org/2000/svg"
+	xlinkNamespace = "http://www.w3.org/1999/xlink"
+)
+
+// iconNamePattern matches valid names of registered icons (eg. "custom/our-logo")
+var iconNamePattern = regexp.MustCompile(`^[a-z0-9-]+/[a-z0-9-]+$`)
+
+// builtinIconNamespaces contains the namespaces of the built-in Font Awesome icons
+var builtinIconNamespaces = [...]string{"brands", "regular", "solid"}
+
+// iconElements contains the SVG elements kept in sanitized icons, which only draw static shapes
+var iconElements = map[string]bool{
+	"svg": true, "g": true, "defs": true, "symbol": true, "use": true, "path": true, "rect": true, "circle": true,
+	"ellipse": true, "line": true, "polyline": true, "polygon": true, "linearGradient": true, "radialGradient": true,
+	"stop": true, "clipPath": true, "mask": true,
+}
+
+// iconAttributes contains the SVG attributes kept in sanitized icons, which cannot run scripts or load resources
+var iconAttributes = map[string]bool{
+	"id": true, "viewBox": true, "preserveAspectRatio": true, "transform": true, "d": true, "x": true, "y": true,
+	"x1": true, "y1": true, "x2": true, "y2": true, "cx": true, "cy": true, "r": true, "rx": true, "ry": true,
+	"fx": true, "fy": true, "width": true, "height": true, "points": true, "href": true, "fill": true,
+	"fill-rule": true, "fill-opacity": true, "stroke": true, "stroke-width": true, "stroke-linecap": true,
+	"stroke-linejoin": true, "stroke-miterlimit": true, "stroke-dasharray": true, "stroke-dashoffset": true,
+	"stroke-opacity": true, "opacity": true, "clip-path": true, "clip-rule": true, "mask": true, "offset": true,
+	"stop-color": true, "stop-opacity": true, "gradientUnits": true, "gradientTransform": true,
+	"spreadMethod": true, "clipPathUnits": true, "maskUnits": true, "maskContentUnits": true,
+}
+
+// urlPattern matches references to resources in attribute values (eg. "url(#gradient)")
+var urlPattern = regexp.MustCompile(`(?i)url\(`)
+
+// localURLPattern matches attribute values only referencing elements of the same document
+var localURLPattern = regexp.MustCompile(`^url\(#[A-Za-z0-9_.:-]+\)$`)
+
+var (
+	registeredIcons      = make(map[string]string)
+	registeredIconsMutex sync.RWMutex
+)
+
+// RegisterIcon registers a SVG icon under a namespaced name (eg. "custom/our-logo"), so that badges can include it
+// (see `Params.Icon`). The icon is sanitized (see `SanitizeIcon`) before it is registered. Registering an existing
+// icon replaces it, except for the built-in Font Awesome icons whose namespaces cannot be used.
+func RegisterIcon(name string, icon string) error {
+	sanitizedIcon, err := parseIcon(name, icon)
+	if err != nil {
+		return err
+	}
+
+	registeredIconsMutex.Lock()
+	defer registeredIconsMutex.Unlock()
+	registeredIcons[name] = sanitizedIcon
+
+	return nil
+}
+
+// ValidateIcon checks that a SVG icon can be registered under the given name (see `RegisterIcon`), without
+// registering it
+func ValidateIcon(name string, icon string) error {
+	_, err := parseIcon(name, icon)
+	return err
+}
+
+// UnregisterIcon unregisters an icon registered with `RegisterIcon`
+func UnregisterIcon(name string) {
+	registeredIconsMutex.Lock()
+	defer registeredIconsMutex.Unlock()
+	delete(registeredIcons, name)
+}
+
+// parseIcon validates the name of an icon & returns its sanitized SVG
+func parseIcon(name string, icon string) (string, error) {
+	if !iconNamePattern.MatchString(name) {
+		return "", fmt.Errorf("Invalid icon name: %q", name)
+	}
+	namespace := strings.SplitN(name, "/", 2)[0]
+	for _, builtinNamespace := range builtinIconNamespaces {
+		if namespace == builtinNamespace {
+			return "", fmt.Errorf("Built-in icon namespace cannot be used: %s", namespace)
+		}
+	}
+
+	sanitizedIcon, err := SanitizeIcon(icon)
+	if err != nil {
+		return "", fmt.Errorf("Invalid icon %s: %v", name, err)
+	}
+
+	return sanitizedIcon, nil
+}
+
+// Icons returns the names of all registered icons (excluding the built-in Font Awesome icons)
+func Icons() []string {
+	registeredIconsMutex.RLock()
+	defer registeredIconsMutex.RUnlock()
+
+	result := make([]string, 0, len(registeredIcons))
+	for name := range registeredIcons {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// lookupIcon returns the SVG of a built-in or registered icon
+func lookupIcon(name string) (string, bool) {
+	if icon, ok := fontAwesomeIcons[name]; ok {
+		return icon, true
+	}
+
+	registeredIconsMutex.RLock()
+	defer registeredIconsMutex.RUnlock()
+	icon, ok := registeredIcons[name]
+	return icon, ok
+}
+
+// parseLogo returns the sanitized SVG icon of a logo data URI ("data:image/svg+xml;base64,<BASE64_SVG>")
+func parseLogo(logo string) (string, error) {
+	// Restore "+" characters decoded as spaces from unescaped query parameters
+	logo = strings.ReplaceAll(logo, " ", "+")
+	if !strings.HasPrefix(logo, logoPrefix) {
+		return "", fmt.Errorf("Logo is not a base64 SVG data URI")
+	}
+	encodedIcon := strings.TrimPrefix(logo, logoPrefix)
+	if base64.StdEncoding.DecodedLen(len(encodedIcon)) > MaxLogoSize+2 {
+		return "", fmt.Errorf("Logo exceeds %d bytes", MaxLogoSize)
+	}
+	icon, err := base64.StdEncoding.DecodeString(encodedIcon)
+	if err != nil {
+		return "", err
+	}
+	if len(icon) > MaxLogoSize {
+		return "", fmt.Errorf("Logo exceeds %d bytes", MaxLogoSize)
+	}
+
+	return SanitizeIcon(string(icon))
+}
+
+// SanitizeIcon returns a copy of a SVG icon that only keeps elements & attributes drawing static shapes, dropping
+// scripts, event handlers, styles, text & references to external resources. The icon is scaled to the badge via its
+// `viewBox`, which is derived from its `width` & `height` if missing.
+func SanitizeIcon(icon string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(icon))
+
+	var buf bytes.Buffer
+	depth, skipDepth := 0, 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+			if skipDepth > 0 {
+				continue
+			}
+			if depth == 1 && (token.Name.Local != "svg" || token.Name.Space != svgNamespace) {
+				return "", fmt.Errorf("missing root <svg> element")
+			}
+			if (token.Name.Space != svgNamespace && token.Name.Space != "") || !iconElements[token.Name.Local] {
+				skipDepth = depth
+				continue
+			}
+
+			attrs := sanitizeIconAttrs(token.Attr)
+			if depth == 1 {
+				if attrs, err = rootIconAttrs(attrs); err != nil {
+					return "", err
+				}
+			}
+			buf.WriteString("<" + token.Name.Local)
+			for _, attr := range attrs {
+				buf.WriteString(" " + attr.Name.Local + `="`)
+				if err := xml.EscapeText(&buf, []byte(attr.Value)); err != nil {
+					return "", err
+				}
+				buf.WriteString(`"`)
+			}
+			buf.WriteString(">")
+		case xml.EndElement:
+			if skipDepth == 0 {
+				buf.WriteString("</" + token.Name.Local + ">")
+			} else if skipDepth == depth {
+				skipDepth = 0
+			}
+			depth--
+		}
+	}
+	if buf.Len() == 0 {
+		return "", fmt.Errorf("missing root <svg> element")
+	}
+
+	// Ensure the icon can be rasterized in PNG badges
+	sanitizedIcon := buf.String()
+	if err := drawIcon(image.NewRGBA(image.Rect(0, 0, 12, 12)), sanitizedIcon, 0, 0, 12, color.Black); err != nil {
+		return "", err
+	}
+
+	return sanitizedIcon, nil
+}
+
+// sanitizeIconAttrs returns the attributes of an icon element that cannot run scripts or load external resources
+func sanitizeIconAttrs(attrs []xml.Attr) []xml.Attr {
+	result := make([]xml.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Name.Space != "" && !(attr.Name.Space == xlinkNamespace && attr.Name.Local == "href") {
+			continue
+		}
+		if !iconAttributes[attr.Name.Local] {
+			continue
+		}
+		if attr.Name.Local == "href" && !strings.HasPrefix(attr.Value, "#") {
+			continue
+		}
+		if urlPattern.MatchString(attr.Value) && !localURLPattern.MatchString(strings.TrimSpace(attr.Value)) {
+			continue
+		}
+		result = append(result, xml.Attr{Name: xml.Name{Local: attr.Name.Local}, Value: attr.Value})
+	}
+
+	return result
+}
+
+// rootIconAttrs returns the attributes of the root element of an icon, scaled to its container by its `viewBox`
+func rootIconAttrs(attrs []xml.Attr) ([]xml.Attr, error) {
+	var width, height, viewBox string
+	result := []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: svgNamespace}}
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "width":
+			width = strings.TrimSuffix(attr.Value, "px")
+		case "height":
+			height = strings.TrimSuffix(attr.Value, "px")
+		case "viewBox":
+			viewBox = attr.Value
+		default:
+			result = append(result, attr)
+		}
+	}
+	if viewBox == "" {
+		if _, err := strconv.ParseFloat(width, 32); err != nil {
+			return nil, fmt.Errorf("missing viewBox")
+		}
+		if _, err := strconv.ParseFloat(height, 32); err != nil {
+			return nil, fmt.Errorf("missing viewBox")
+		}
+		viewBox = "0 0 " + width + " " + height
+	}
+
+	return append(result[:1], append([]xml.Attr{{Name: xml.Name{Local: "viewBox"}, Value: viewBox}},
+		result[1:]...)...), nil
+}
@@ -0,0 +1,143 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/bradleyjkemp/cupaloy"
+	"github.com/stretchr/testify/assert"
+)
+
+const testIcon = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="24" height="24" onload="alert(1)">
+	<title>Our Logo</title>
+	<script>alert(1)</script>
+	<style>path { fill: red; }</style>
+	<defs><linearGradient id="gradient"><stop offset="0" stop-color="#fff"/></linearGradient></defs>
+	<g fill="url(#gradient)" style="fill: url(https://example.com/track.svg)">
+		<path d="M0 0h24v24H0z" onclick="alert(1)"/>
+		<circle cx="12" cy="12" r="6" fill="url(https://example.com/track.svg)"/>
+	</g>
+	<use xlink:href="https://example.com/icon.svg#path"/>
+	<use href="#gradient"/>
+	<image href="https://example.com/track.png"/>
+	<foreignObject><div xmlns="http://www.w3.org/1999/xhtml"><a href="javascript:alert(1)">link</a></div></foreignObject>
+</svg>`
+
+const testSanitizedIcon = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24">` +
+	`<defs><linearGradient id="gradient"><stop offset="0" stop-color="#fff"></stop></linearGradient></defs>` +
+	`<g fill="url(#gradient)"><path d="M0 0h24v24H0z"></path><circle cx="12" cy="12" r="6"></circle></g>` +
+	`<use></use><use href="#gradient"></use></svg>`
+
+func TestSanitizeIcon(t *testing.T) {
+	t.Parallel()
+
+	result, err := SanitizeIcon(testIcon)
+	assert.NoError(t, err)
+	assert.Equal(t, testSanitizedIcon, result)
+
+	// Sanitizing is idempotent
+	result, err = SanitizeIcon(result)
+	assert.NoError(t, err)
+	assert.Equal(t, testSanitizedIcon, result)
+
+	errorCases := map[string]string{
+		"":            "missing root <svg> element",
+		"<svg></svg>": "missing root <svg> element",
+		`<html xmlns="http://www.w3.org/2000/svg"></html>`:                                "missing root <svg> element",
+		`<svg xmlns="http://www.w3.org/2000/svg"></svg>`:                                  "missing viewBox",
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 0 0"></svg>`:                "Invalid icon viewBox: 0 0 0 0",
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 1 1"><path d="X"/></svg>`:   "Unsupported path command: X",
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 1 1"><path d="M0 0"></svg>`: "XML syntax error on line 1: element <path> closed by </svg>",
+	}
+	for icon, expectedErr := range errorCases {
+		_, err := SanitizeIcon(icon)
+		assert.EqualError(t, err, expectedErr, icon)
+	}
+}
+
+func TestRegisterIcon(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, RegisterIcon("test/our-logo", testIcon))
+	assert.True(t, HasIcon("test/our-logo"))
+	assert.Contains(t, Icons(), "test/our-logo")
+
+	params := &Params{Subject: "build", Status: "passing", Icon: "test/our-logo"}
+	newBadge, err := generateBadge(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "test/our-logo", newBadge.IconLabel)
+	assert.Equal(t, testSanitizedIcon, newBadge.IconSVG)
+
+	result, err := Create(params)
+	assert.NoError(t, err)
+	extractedParams, err := ExtractParams(result)
+	assert.NoError(t, err)
+	assert.Equal(t, "test/our-logo", extractedParams.Icon)
+
+	assert.EqualError(t, RegisterIcon("our-logo", testIcon), `Invalid icon name: "our-logo"`)
+	assert.EqualError(t, RegisterIcon("test/Our-Logo", testIcon), `Invalid icon name: "test/Our-Logo"`)
+	assert.EqualError(t, RegisterIcon("brands/our-logo", testIcon), "Built-in icon namespace cannot be used: brands")
+	assert.EqualError(t, RegisterIcon("test/invalid", `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 1 1">`),
+		"Invalid icon test/invalid: XML syntax error on line 1: unexpected EOF")
+
+	// Validated icons aren't registered
+	assert.NoError(t, ValidateIcon("test/validated", testIcon))
+	assert.False(t, HasIcon("test/validated"))
+	assert.EqualError(t, ValidateIcon("brands/our-logo", testIcon), "Built-in icon namespace cannot be used: brands")
+
+	assert.NoError(t, RegisterIcon("test/unregistered", testIcon))
+	UnregisterIcon("test/unregistered")
+	UnregisterIcon("brands/github")
+	assert.False(t, HasIcon("test/unregistered"))
+	assert.True(t, HasIcon("brands/github"))
+}
+
+func TestBadgeWithLogo(t *testing.T) {
+	t.Parallel()
+
+	logo := logoPrefix + base64.StdEncoding.EncodeToString([]byte(testIcon))
+	sanitizedLogo := logoPrefix + base64.StdEncoding.EncodeToString([]byte(testSanitizedIcon))
+	params := &Params{Subject: "build", Status: "passing", Icon: "brands/github", Logo: logo}
+
+	result, err := Create(params)
+	assert.NoError(t, err)
+	cupaloy.SnapshotT(t, result)
+
+	// Logos override icons & round-trip through `ExtractParams`
+	resolvedParams, err := Resolve(params)
+	assert.NoError(t, err)
+	assert.Equal(t, "", resolvedParams.Icon)
+	assert.Equal(t, sanitizedLogo, resolvedParams.Logo)
+	extractedParams, err := ExtractParams(result)
+	assert.NoError(t, err)
+	assert.Equal(t, resolvedParams, extractedParams)
+
+	// Unescaped "+" characters of query parameters are decoded as spaces
+	resolvedParams, err = Resolve(&Params{Logo: strings.ReplaceAll(logo, "+", " ")})
+	assert.NoError(t, err)
+	assert.Equal(t, sanitizedLogo, resolvedParams.Logo)
+
+	pngResult, err := CreatePNG(params)
+	assert.NoError(t, err)
+	_, err = png.Decode(bytes.NewReader(pngResult))
+	assert.NoError(t, err)
+
+	// Invalid logos are discarded
+	invalidLogos := []string{
+		"https://example.com/logo.svg",
+		"data:image/png;base64,iVBORw0KGgo=",
+		logoPrefix + "not-base64",
+		logoPrefix + base64.StdEncoding.EncodeToString([]byte("<svg>")),
+		logoPrefix + base64.StdEncoding.EncodeToString([]byte(strings.Repeat(" ", MaxLogoSize)+testIcon)),
+	}
+	for _, invalidLogo := range invalidLogos {
+		resolvedParams, err := Resolve(&Params{Icon: "brands/github", Logo: invalidLogo})
+		assert.NoError(t, err)
+		assert.Equal(t, "brands/github", resolvedParams.Icon)
+		assert.Equal(t, "", resolvedParams.Logo)
+	}
+}
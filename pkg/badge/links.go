@@ -0,0 +1,22 @@
+package badge
+
+import (
+	"net/url"
+	"strings"
+)
+
+// linkSchemes contains the URL schemes of links that badges can embed, excluding schemes running scripts (eg.
+// "javascript:") or embedding documents (eg. "data:")
+var linkSchemes = map[string]bool{"http": true, "https": true}
+
+// parseLink returns the normalized URL of a link, or an empty string if it isn't an absolute URL with a supported
+// scheme
+func parseLink(link string) string {
+	parsedURL, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || !linkSchemes[strings.ToLower(parsedURL.Scheme)] || parsedURL.Host == "" {
+		return ""
+	}
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+
+	return parsedURL.String()
+}
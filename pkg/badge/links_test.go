@@ -0,0 +1,28 @@
+package badge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLink(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"https://github.com/tohjustin/aegis":               "https://github.com/tohjustin/aegis",
+		" HTTP://example.com/a b?c=d&e=<f>#g ":             "http://example.com/a%20b?c=d&e=<f>#g",
+		"https://example.com/\"><script>alert(1)</script>": "https://example.com/%22%3E%3Cscript%3Ealert%281%29%3C/script%3E",
+		"javascript:alert(1)":                              "",
+		"JaVaScRiPt://example.com/%0aalert(1)":             "",
+		"data:text/html;base64,PHNjcmlwdD4=":               "",
+		"//example.com":                                    "",
+		"/relative/path":                                   "",
+		"https://":                                         "",
+		"https://exa mple.com":                             "",
+		"":                                                 "",
+	}
+	for link, expected := range testCases {
+		assert.Equal(t, expected, parseLink(link), link)
+	}
+}
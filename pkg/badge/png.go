@@ -0,0 +1,659 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// gradientStop represents a color stop of a vertical linear gradient
+type gradientStop struct {
+	Offset float64
+	Color  color.NRGBA
+}
+
+// rasterStyle holds the visual properties of a badge style that are hardcoded in its SVG template
+type rasterStyle struct {
+	CornerRadius    float32
+	SubjectBorder   color.NRGBA
+	SubjectGradient []gradientStop
+	Gradient        []gradientStop
+	TextShadow      bool
+	TextBaseline    int
+}
+
+// rasterStyles mirrors the SVG templates in "assets/templates"
+var rasterStyles = map[Style]rasterStyle{
+	ClassicStyle: {
+		CornerRadius: 3,
+		Gradient: []gradientStop{
+			{0, color.NRGBA{0xbb, 0xbb, 0xbb, 0x1a}},
+			{1, color.NRGBA{0x00, 0x00, 0x00, 0x1a}},
+		},
+		TextShadow:   true,
+		TextBaseline: 14,
+	},
+	FlatStyle: {
+		TextShadow:   true,
+		TextBaseline: 14,
+	},
+	PlasticStyle: {
+		CornerRadius: 3,
+		Gradient: []gradientStop{
+			{0, color.NRGBA{0xff, 0xff, 0xff, 0xb3}},
+			{0.1, color.NRGBA{0xaa, 0xaa, 0xaa, 0x1a}},
+			{0.9, color.NRGBA{0x00, 0x00, 0x00, 0x4d}},
+			{1, color.NRGBA{0x00, 0x00, 0x00, 0x80}},
+		},
+		TextShadow:   true,
+		TextBaseline: 14,
+	},
+	SemaphoreCIStyle: {
+		CornerRadius: 2,
+		TextBaseline: 13,
+	},
+	ForTheBadgeStyle: {
+		TextBaseline: 18,
+	},
+	SocialStyle: {
+		CornerRadius:  2,
+		SubjectBorder: color.NRGBA{0xd5, 0xd5, 0xd5, 0xff},
+		SubjectGradient: []gradientStop{
+			{0, color.NRGBA{0xfc, 0xfc, 0xfc, 0x00}},
+			{1, color.NRGBA{0x00, 0x00, 0x00, 0x1a}},
+		},
+		TextBaseline: 14,
+	},
+}
+
+// CreatePNG generates a PNG badge with the same layout as the SVG badge generated by `Create`
+func CreatePNG(params *Params) ([]byte, error) {
+	newBadge, err := generateBadge(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodePNG(newBadge)
+}
+
+// CreateSegmentsPNG generates a PNG badge with the same layout as the SVG badge generated by `CreateSegments`
+func CreateSegmentsPNG(params *SegmentParams) ([]byte, error) {
+	newBadge, err := generateSegmentedBadge(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodePNG(newBadge)
+}
+
+// encodePNG rasterizes the badge & encodes it as a PNG image
+func encodePNG(newBadge *badgeDimensions) ([]byte, error) {
+	img, err := rasterize(newBadge)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// rasterize draws the badge onto an image
+func rasterize(newBadge *badgeDimensions) (*image.RGBA, error) {
+	style, ok := rasterStyles[newBadge.Style]
+	if !ok && registeredTemplate(newBadge.Style) != nil {
+		style, ok = rasterStyles[ClassicStyle], true
+	}
+	if !ok {
+		return nil, fmt.Errorf("Badge style cannot be rasterized: %s", newBadge.Style)
+	}
+	segmentColors := make([]color.RGBA, len(newBadge.Segments))
+	fontColors := make([]color.RGBA, len(newBadge.Segments))
+	for i, segment := range newBadge.Segments {
+		if segmentColors[i], ok = colorToRGBA(segment.Color); !ok {
+			return nil, fmt.Errorf("Invalid badge color: %s", segment.Color)
+		}
+		if fontColors[i], ok = colorToRGBA(segment.FontColor); !ok {
+			return nil, fmt.Errorf("Invalid font color: %s", segment.FontColor)
+		}
+	}
+
+	bounds := image.Rect(0, 0, newBadge.TotalWidth, newBadge.Height)
+	img := image.NewRGBA(bounds)
+
+	// Draw background, clipped by a (rounded) rectangle
+	background := image.NewRGBA(bounds)
+	for i, segment := range newBadge.Segments {
+		// Segments also fill the gap before them, for the arrow pointing to the previous segment
+		segmentBounds := image.Rect(segment.Start, 0, segment.Start+segment.Width, newBadge.Height)
+		if i > 0 {
+			segmentBounds.Min.X -= newBadge.Gap
+		}
+		if segment.Label && style.SubjectBorder.A > 0 {
+			draw.Draw(background, segmentBounds, image.NewUniform(style.SubjectBorder), image.Point{}, draw.Src)
+			segmentBounds = segmentBounds.Inset(1)
+		}
+		draw.Draw(background, segmentBounds, image.NewUniform(segmentColors[i]), image.Point{}, draw.Src)
+		if segment.Label && len(style.SubjectGradient) > 0 {
+			draw.Draw(background, segmentBounds, newGradient(segmentBounds, style.SubjectGradient), segmentBounds.Min,
+				draw.Over)
+		}
+	}
+	if len(style.Gradient) > 0 {
+		draw.Draw(background, bounds, newGradient(bounds, style.Gradient), image.Point{}, draw.Over)
+	}
+	clipMask := image.NewAlpha(bounds)
+	clipPath := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	if newBadge.Gap > 0 {
+		// Separate segments, with arrows pointing from each segment to the previous one
+		height := float32(newBadge.Height)
+		for i, segment := range newBadge.Segments {
+			start := float32(segment.Start)
+			addRoundedRect(clipPath, start, float32(segment.Width), height, style.CornerRadius)
+			if i > 0 {
+				clipPath.MoveTo(start, height/2-4)
+				clipPath.LineTo(start-4, height/2)
+				clipPath.LineTo(start, height/2+4)
+				clipPath.ClosePath()
+			}
+		}
+	} else {
+		addRoundedRect(clipPath, 0, float32(bounds.Dx()), float32(bounds.Dy()), style.CornerRadius)
+	}
+	clipPath.Draw(clipMask, bounds, image.Opaque, image.Point{})
+	draw.DrawMask(img, bounds, background, image.Point{}, clipMask, image.Point{}, draw.Over)
+
+	// Draw icon
+	if newBadge.IconBase64Str != "" {
+		if err := drawIcon(img, newBadge.IconSVG, newBadge.PaddingOuter, (newBadge.Height-12)/2, 12,
+			fontColors[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	// Draw texts
+	family, err := loadFontFamily(newBadge.FontFamily)
+	if err != nil {
+		return nil, err
+	}
+	for i, segment := range newBadge.Segments {
+		sfntFont, embolden := family.face(segment.FontWeight == "bold")
+		face, err := opentype.NewFace(sfntFont, &opentype.FaceOptions{
+			Size:    float64(newBadge.FontSize),
+			DPI:     72,
+			Hinting: font.HintingNone,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if style.TextShadow {
+			drawText(img, face, segment.Text, segment.TextOffset, style.TextBaseline+1, newBadge.LetterSpacing,
+				color.NRGBA{0x00, 0x00, 0x00, 0x4d})
+		}
+		drawText(img, face, segment.Text, segment.TextOffset, style.TextBaseline, newBadge.LetterSpacing, fontColors[i])
+		// Embolden texts by overdrawing them, if the font family has no bold font
+		if embolden {
+			drawText(img, face, segment.Text, segment.TextOffset+1, style.TextBaseline, newBadge.LetterSpacing,
+				fontColors[i])
+		}
+		face.Close()
+	}
+
+	return img, nil
+}
+
+// newGradient returns an image filled with a vertical linear gradient
+func newGradient(bounds image.Rectangle, stops []gradientStop) *image.NRGBA {
+	img := image.NewNRGBA(bounds)
+	height := bounds.Dy()
+	for y := 0; y < height; y++ {
+		offset := (float64(y) + 0.5) / float64(height)
+		c := interpolateGradient(stops, offset)
+		for x := 0; x < bounds.Dx(); x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+// interpolateGradient returns the color of the gradient at the given offset
+func interpolateGradient(stops []gradientStop, offset float64) color.NRGBA {
+	if offset <= stops[0].Offset {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		start, end := stops[i-1], stops[i]
+		if offset > end.Offset {
+			continue
+		}
+		t := (offset - start.Offset) / (end.Offset - start.Offset)
+		lerp := func(a, b uint8) uint8 {
+			return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+		}
+		return color.NRGBA{
+			R: lerp(start.Color.R, end.Color.R),
+			G: lerp(start.Color.G, end.Color.G),
+			B: lerp(start.Color.B, end.Color.B),
+			A: lerp(start.Color.A, end.Color.A),
+		}
+	}
+
+	return stops[len(stops)-1].Color
+}
+
+// addRoundedRect adds a rectangle with rounded corners starting at (x, 0) to the rasterizer's path
+func addRoundedRect(z *vector.Rasterizer, x float32, width float32, height float32, radius float32) {
+	right := x + width
+	if radius <= 0 {
+		z.MoveTo(x, 0)
+		z.LineTo(right, 0)
+		z.LineTo(right, height)
+		z.LineTo(x, height)
+		z.ClosePath()
+		return
+	}
+
+	// Control point distance for approximating a quarter circle with a cubic bézier curve
+	k := radius * 0.5523
+	z.MoveTo(x+radius, 0)
+	z.LineTo(right-radius, 0)
+	z.CubeTo(right-radius+k, 0, right, radius-k, right, radius)
+	z.LineTo(right, height-radius)
+	z.CubeTo(right, height-radius+k, right-radius+k, height, right-radius, height)
+	z.LineTo(x+radius, height)
+	z.CubeTo(x+radius-k, height, x, height-radius+k, x, height-radius)
+	z.LineTo(x, radius)
+	z.CubeTo(x, radius-k, x+radius-k, 0, x+radius, 0)
+	z.ClosePath()
+}
+
+// drawText draws text onto the image with its baseline starting at (x, y), spacing its characters apart. Invisible
+// format characters (eg. bidi isolates & joiners) are skipped, as the font has no glyphs for them.
+func drawText(img draw.Image, face font.Face, text string, x int, y int, letterSpacing int, c color.Color) {
+	text = strings.Map(func(character rune) rune {
+		if unicode.Is(unicode.Cf, character) {
+			return -1
+		}
+		return character
+	}, text)
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	if letterSpacing == 0 {
+		drawer.DrawString(text)
+		return
+	}
+	for _, character := range text {
+		drawer.DrawString(string(character))
+		drawer.Dot.X += fixed.I(letterSpacing)
+	}
+}
+
+type svgIcon struct {
+	XMLName xml.Name `xml:"svg"`
+	ViewBox string   `xml:"viewBox,attr"`
+	svgShapes
+}
+
+// svgShapes holds the shapes of a SVG icon (or of one of its groups) drawn in PNG badges
+type svgShapes struct {
+	Paths []struct {
+		D string `xml:"d,attr"`
+	} `xml:"path"`
+	Rects []struct {
+		X      float32 `xml:"x,attr"`
+		Y      float32 `xml:"y,attr"`
+		Width  float32 `xml:"width,attr"`
+		Height float32 `xml:"height,attr"`
+	} `xml:"rect"`
+	Circles []struct {
+		CX float32 `xml:"cx,attr"`
+		CY float32 `xml:"cy,attr"`
+		R  float32 `xml:"r,attr"`
+	} `xml:"circle"`
+	Ellipses []struct {
+		CX float32 `xml:"cx,attr"`
+		CY float32 `xml:"cy,attr"`
+		RX float32 `xml:"rx,attr"`
+		RY float32 `xml:"ry,attr"`
+	} `xml:"ellipse"`
+	Polygons []struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+	Groups []svgShapes `xml:"g"`
+}
+
+// pathData returns the shapes as SVG path data
+func (shapes *svgShapes) pathData() []string {
+	var result []string
+	for _, path := range shapes.Paths {
+		result = append(result, path.D)
+	}
+	for _, rect := range shapes.Rects {
+		result = append(result, fmt.Sprintf("M%g %gh%gv%gh%gz", rect.X, rect.Y, rect.Width, rect.Height, -rect.Width))
+	}
+	for _, circle := range shapes.Circles {
+		result = append(result, fmt.Sprintf("M%g %gA%g %g 0 1 0 %g %gA%g %g 0 1 0 %g %gz", circle.CX-circle.R, circle.CY,
+			circle.R, circle.R, circle.CX+circle.R, circle.CY, circle.R, circle.R, circle.CX-circle.R, circle.CY))
+	}
+	for _, ellipse := range shapes.Ellipses {
+		result = append(result, fmt.Sprintf("M%g %gA%g %g 0 1 0 %g %gA%g %g 0 1 0 %g %gz", ellipse.CX-ellipse.RX,
+			ellipse.CY, ellipse.RX, ellipse.RY, ellipse.CX+ellipse.RX, ellipse.CY, ellipse.RX, ellipse.RY,
+			ellipse.CX-ellipse.RX, ellipse.CY))
+	}
+	for _, polygon := range shapes.Polygons {
+		if polygon.Points != "" {
+			result = append(result, "M"+polygon.Points+"z")
+		}
+	}
+	for i := range shapes.Groups {
+		result = append(result, shapes.Groups[i].pathData()...)
+	}
+	return result
+}
+
+// drawIcon draws a SVG icon onto the image, scaled to a square of the given size at (x, y). Icons are drawn as filled
+// shapes of a single color, ignoring transforms, strokes & gradients.
+func drawIcon(img *image.RGBA, icon string, x int, y int, size int, c color.Color) error {
+	var iconObj svgIcon
+	if err := xml.Unmarshal([]byte(icon), &iconObj); err != nil {
+		return err
+	}
+
+	viewBox := strings.Fields(iconObj.ViewBox)
+	if len(viewBox) != 4 {
+		return fmt.Errorf("Invalid icon viewBox: %s", iconObj.ViewBox)
+	}
+	var viewBoxValues [4]float32
+	for i, value := range viewBox {
+		parsedValue, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid icon viewBox: %s", iconObj.ViewBox)
+		}
+		viewBoxValues[i] = float32(parsedValue)
+	}
+	minX, minY, width, height := viewBoxValues[0], viewBoxValues[1], viewBoxValues[2], viewBoxValues[3]
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("Invalid icon viewBox: %s", iconObj.ViewBox)
+	}
+
+	// Preserve aspect ratio & center the icon within its bounding square
+	scale := float32(size) / width
+	if height > width {
+		scale = float32(size) / height
+	}
+	offsetX := float32(x) + (float32(size)-width*scale)/2
+	offsetY := float32(y) + (float32(size)-height*scale)/2
+	transform := func(px float32, py float32) (float32, float32) {
+		return offsetX + (px-minX)*scale, offsetY + (py-minY)*scale
+	}
+
+	bounds := img.Bounds()
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	for _, d := range iconObj.pathData() {
+		if err := addPathData(z, d, transform); err != nil {
+			return err
+		}
+	}
+	z.Draw(img, bounds, image.NewUniform(c), image.Point{})
+
+	return nil
+}
+
+// addPathData adds the shapes described by SVG path data to the rasterizer's path.
+// Supports the move, line, (smooth) cubic & quadratic bézier curve, elliptical arc & close path commands.
+func addPathData(z *vector.Rasterizer, d string,
+	transform func(x float32, y float32) (float32, float32)) error {
+	tokens := tokenizePathData(d)
+
+	var command, previousCommand byte
+	var startX, startY, x, y float32
+	// Last control point of the previous curve command, reflected by smooth curve commands
+	var controlX, controlY float32
+	nextNumbers := func(n int) ([]float32, error) {
+		numbers := make([]float32, n)
+		for i := 0; i < n; i++ {
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("Unexpected end of path data for command: %c", command)
+			}
+			value, err := strconv.ParseFloat(tokens[0], 32)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid path data: %s", tokens[0])
+			}
+			numbers[i] = float32(value)
+			tokens = tokens[1:]
+		}
+		return numbers, nil
+	}
+
+	for len(tokens) > 0 {
+		if c := tokens[0][0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			command = c
+			tokens = tokens[1:]
+		} else if command == 0 {
+			return fmt.Errorf("Path data must start with a command")
+		}
+
+		relative := command >= 'a' && command <= 'z'
+		var dx, dy float32
+		if relative {
+			dx, dy = x, y
+		}
+		// Smooth curve commands reflect the control point of the previous curve command of the same kind
+		reflectX, reflectY := x, y
+		if (strings.IndexByte("CcSs", command) >= 0 && strings.IndexByte("CcSs", previousCommand) >= 0) ||
+			(strings.IndexByte("QqTt", command) >= 0 && strings.IndexByte("QqTt", previousCommand) >= 0) {
+			reflectX, reflectY = 2*x-controlX, 2*y-controlY
+		}
+		previousCommand = command
+
+		switch command {
+		case 'M', 'm':
+			p, err := nextNumbers(2)
+			if err != nil {
+				return err
+			}
+			x, y = p[0]+dx, p[1]+dy
+			startX, startY = x, y
+			z.MoveTo(transform(x, y))
+			// Subsequent coordinate pairs are implicit line commands
+			if relative {
+				command = 'l'
+			} else {
+				command = 'L'
+			}
+		case 'L', 'l':
+			p, err := nextNumbers(2)
+			if err != nil {
+				return err
+			}
+			x, y = p[0]+dx, p[1]+dy
+			z.LineTo(transform(x, y))
+		case 'H', 'h':
+			p, err := nextNumbers(1)
+			if err != nil {
+				return err
+			}
+			x = p[0] + dx
+			z.LineTo(transform(x, y))
+		case 'V', 'v':
+			p, err := nextNumbers(1)
+			if err != nil {
+				return err
+			}
+			y = p[0] + dy
+			z.LineTo(transform(x, y))
+		case 'C', 'c':
+			p, err := nextNumbers(6)
+			if err != nil {
+				return err
+			}
+			bx, by := transform(p[0]+dx, p[1]+dy)
+			controlX, controlY = p[2]+dx, p[3]+dy
+			cx, cy := transform(controlX, controlY)
+			x, y = p[4]+dx, p[5]+dy
+			ex, ey := transform(x, y)
+			z.CubeTo(bx, by, cx, cy, ex, ey)
+		case 'S', 's':
+			p, err := nextNumbers(4)
+			if err != nil {
+				return err
+			}
+			bx, by := transform(reflectX, reflectY)
+			controlX, controlY = p[0]+dx, p[1]+dy
+			cx, cy := transform(controlX, controlY)
+			x, y = p[2]+dx, p[3]+dy
+			ex, ey := transform(x, y)
+			z.CubeTo(bx, by, cx, cy, ex, ey)
+		case 'Q', 'q':
+			p, err := nextNumbers(4)
+			if err != nil {
+				return err
+			}
+			controlX, controlY = p[0]+dx, p[1]+dy
+			bx, by := transform(controlX, controlY)
+			x, y = p[2]+dx, p[3]+dy
+			cx, cy := transform(x, y)
+			z.QuadTo(bx, by, cx, cy)
+		case 'T', 't':
+			p, err := nextNumbers(2)
+			if err != nil {
+				return err
+			}
+			controlX, controlY = reflectX, reflectY
+			bx, by := transform(controlX, controlY)
+			x, y = p[0]+dx, p[1]+dy
+			cx, cy := transform(x, y)
+			z.QuadTo(bx, by, cx, cy)
+		case 'A', 'a':
+			p, err := nextNumbers(7)
+			if err != nil {
+				return err
+			}
+			startArcX, startArcY := x, y
+			x, y = p[5]+dx, p[6]+dy
+			addArc(z, transform, startArcX, startArcY, p[0], p[1], p[2], p[3] != 0, p[4] != 0, x, y)
+		case 'Z', 'z':
+			z.ClosePath()
+			x, y = startX, startY
+			command = 0
+		default:
+			return fmt.Errorf("Unsupported path command: %c", command)
+		}
+	}
+
+	return nil
+}
+
+// addArc adds an elliptical arc from (x1, y1) to (x2, y2) to the rasterizer's path, approximated by line segments.
+// See https://www.w3.org/TR/SVG11/implnote.html#ArcImplementationNotes
+func addArc(z *vector.Rasterizer, transform func(x float32, y float32) (float32, float32),
+	x1 float32, y1 float32, rx float32, ry float32, rotation float32, largeArc bool, sweep bool, x2 float32, y2 float32) {
+	if x1 == x2 && y1 == y2 {
+		return
+	}
+	if rx == 0 || ry == 0 {
+		z.LineTo(transform(x2, y2))
+		return
+	}
+
+	radiusX, radiusY := math.Abs(float64(rx)), math.Abs(float64(ry))
+	sinPhi, cosPhi := math.Sincos(float64(rotation) * math.Pi / 180)
+	halfDX, halfDY := float64(x1-x2)/2, float64(y1-y2)/2
+	x1p, y1p := cosPhi*halfDX+sinPhi*halfDY, -sinPhi*halfDX+cosPhi*halfDY
+
+	// Scale up radii too small to reach the end point
+	if lambda := x1p*x1p/(radiusX*radiusX) + y1p*y1p/(radiusY*radiusY); lambda > 1 {
+		radiusX, radiusY = radiusX*math.Sqrt(lambda), radiusY*math.Sqrt(lambda)
+	}
+
+	numerator := radiusX*radiusX*radiusY*radiusY - radiusX*radiusX*y1p*y1p - radiusY*radiusY*x1p*x1p
+	denominator := radiusX*radiusX*y1p*y1p + radiusY*radiusY*x1p*x1p
+	coefficient := math.Sqrt(math.Max(0, numerator/denominator))
+	if largeArc == sweep {
+		coefficient = -coefficient
+	}
+	cxp, cyp := coefficient*radiusX*y1p/radiusY, -coefficient*radiusY*x1p/radiusX
+	cx := cosPhi*cxp - sinPhi*cyp + float64(x1+x2)/2
+	cy := sinPhi*cxp + cosPhi*cyp + float64(y1+y2)/2
+
+	startAngle := math.Atan2((y1p-cyp)/radiusY, (x1p-cxp)/radiusX)
+	deltaAngle := math.Atan2((-y1p-cyp)/radiusY, (-x1p-cxp)/radiusX) - startAngle
+	if sweep && deltaAngle < 0 {
+		deltaAngle += 2 * math.Pi
+	} else if !sweep && deltaAngle > 0 {
+		deltaAngle -= 2 * math.Pi
+	}
+
+	n := int(math.Ceil(math.Abs(deltaAngle) / (math.Pi / 16)))
+	for i := 1; i < n; i++ {
+		sinAngle, cosAngle := math.Sincos(startAngle + deltaAngle*float64(i)/float64(n))
+		px := cx + radiusX*cosAngle*cosPhi - radiusY*sinAngle*sinPhi
+		py := cy + radiusX*cosAngle*sinPhi + radiusY*sinAngle*cosPhi
+		z.LineTo(transform(float32(px), float32(py)))
+	}
+	z.LineTo(transform(x2, y2))
+}
+
+// tokenizePathData splits SVG path data into commands & numbers
+func tokenizePathData(d string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(d); i++ {
+		c := d[i]
+		switch {
+		case (c >= 'a' && c <= 'z' && c != 'e') || (c >= 'A' && c <= 'Z' && c != 'E'):
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		case c == '-' || c == '+':
+			// A sign starts a new number unless it belongs to an exponent
+			if current.Len() > 0 {
+				if last := current.String()[current.Len()-1]; last != 'e' && last != 'E' {
+					flush()
+				}
+			}
+			current.WriteByte(c)
+		case c == '.':
+			// A second decimal point starts a new number (eg. "0.5.5")
+			if strings.Contains(current.String(), ".") {
+				flush()
+			}
+			current.WriteByte(c)
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+
+	return tokens
+}
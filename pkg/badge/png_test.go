@@ -0,0 +1,72 @@
+package badge
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/vector"
+)
+
+func TestCreatePNG(t *testing.T) {
+	t.Parallel()
+
+	for _, spec := range testCases {
+		t.Run(spec.name, func(t *testing.T) {
+			result, err := CreatePNG(&spec.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img, err := png.Decode(bytes.NewReader(result))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			newBadge, err := generateBadge(&spec.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, newBadge.TotalWidth, img.Bounds().Dx())
+			assert.Equal(t, newBadge.Height, img.Bounds().Dy())
+
+			// Status section is filled with the badge color (sampled at the unclipped, text-free bottom edge)
+			expectedColor, _ := colorToRGBA(newBadge.Color)
+			r, g, b, a := img.At(newBadge.TotalWidth-newBadge.PaddingOuter, newBadge.Height-2).RGBA()
+			actualColor := color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+			assert.Equal(t, uint8(0xff), actualColor.A)
+			if style := rasterStyles[newBadge.Style]; len(style.Gradient) == 0 {
+				assert.Equal(t, expectedColor, actualColor)
+			}
+		})
+	}
+}
+
+func TestAddPathDataErrors(t *testing.T) {
+	t.Parallel()
+
+	identity := func(x float32, y float32) (float32, float32) { return x, y }
+	testCases := []string{
+		"10 10",
+		"M10",
+		"M10 10 R5 5",
+		"M10 10 A5 5 0 0 1",
+		"M10 10 Lx 20",
+	}
+
+	for _, d := range testCases {
+		t.Run(d, func(t *testing.T) {
+			assert.Error(t, addPathData(vector.NewRasterizer(20, 20), d, identity))
+		})
+	}
+}
+
+func TestTokenizePathData(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		[]string{"M", "1.5", "-2", "L", "3", ".5", ".5", "c", "1e-2", "-1", "0", "0", "1", "1", "Z"},
+		tokenizePathData("M1.5-2L3 .5.5c1e-2,-1 0 0 1 1Z"))
+}
@@ -0,0 +1,168 @@
+package badge
+
+import (
+	"fmt"
+	"text/template"
+	"text/template/parse"
+)
+
+// Segment holds the parameters of a segment of a multi-segment badge
+type Segment struct {
+	// Text determines the text of the segment.
+	Text string
+	// Color determines the background color of the segment (see `Params.Color`).
+	// Segments without colors are labels, styled like the subject of a badge.
+	Color string
+	// Link determines the URL opened by clicking the segment (see `Params.Link`).
+	Link string
+}
+
+// SegmentParams holds parameters of badges with any number of segments side by side
+// (eg. "build | passing | coverage | 87%")
+type SegmentParams struct {
+	// Segments determines the segments of the badge, from left to right.
+	Segments []Segment
+	// Icon determines the icon included in the first segment of the badge (see `Params.Icon`).
+	Icon string
+	// Logo determines an ad-hoc icon included in the first segment of the badge (see `Params.Logo`).
+	Logo string
+	// Style determines the visual style of the badge
+	Style Style
+	// FontFamily determines the font family of the badge texts (see `Params.FontFamily`).
+	FontFamily string
+	// FontSize determines the font size (in px) of the badge texts (see `Params.FontSize`).
+	FontSize int
+	// Link determines the URL opened by clicking the badge, outside of segments with links (see `Params.Link`).
+	Link string
+}
+
+// supportsSegments reports whether the template renders any number of segments, ie. its parse tree (or the parse
+// tree of a template it defines) ranges over `.Segments`
+func supportsSegments(tmpl *template.Template) bool {
+	for _, definedTmpl := range tmpl.Templates() {
+		if definedTmpl.Tree != nil && rangesOverSegments(definedTmpl.Tree.Root) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rangesOverSegments reports whether a node of a parse tree contains a `range` action over `.Segments`
+func rangesOverSegments(node parse.Node) bool {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return false
+		}
+		for _, child := range node.Nodes {
+			if rangesOverSegments(child) {
+				return true
+			}
+		}
+	case *parse.RangeNode:
+		for _, cmd := range node.Pipe.Cmds {
+			for _, arg := range cmd.Args {
+				if field, ok := arg.(*parse.FieldNode); ok && len(field.Ident) == 1 && field.Ident[0] == "Segments" {
+					return true
+				}
+			}
+		}
+		return rangesOverSegments(node.List) || rangesOverSegments(node.ElseList)
+	case *parse.IfNode:
+		return rangesOverSegments(node.List) || rangesOverSegments(node.ElseList)
+	case *parse.WithNode:
+		return rangesOverSegments(node.List) || rangesOverSegments(node.ElseList)
+	}
+
+	return false
+}
+
+// SegmentsError represents multi-segment badge parameters that cannot be rendered, ie. without segments or with a
+// style that doesn't support multiple segments
+type SegmentsError struct {
+	reason string
+}
+
+func (err *SegmentsError) Error() string {
+	return err.reason
+}
+
+// generateSegmentedBadge converts multi-segment badge parameters into dimensions for generating SVG badge
+func generateSegmentedBadge(params *SegmentParams) (*badgeDimensions, error) {
+	badgeParams := params
+	if badgeParams == nil {
+		badgeParams = &SegmentParams{}
+	}
+	if len(badgeParams.Segments) == 0 {
+		return nil, &SegmentsError{"Badge has no segments"}
+	}
+	badgeStyle := badgeParams.Style
+	if badgeStyle == Style("") {
+		badgeStyle = DefaultStyle
+	}
+
+	newBadge := styleDimensions(badgeStyle)
+	if newBadge.Template == nil {
+		return nil, fmt.Errorf("Badge template does not exist: %s", badgeStyle)
+	}
+	if !supportsSegments(newBadge.Template) {
+		return nil, &SegmentsError{fmt.Sprintf("Badge style does not support multiple segments: %s", badgeStyle)}
+	}
+
+	newBadge.Link = parseLink(badgeParams.Link)
+	newBadge.setFont(badgeParams.FontFamily, badgeParams.FontSize)
+
+	segments := make([]Segment, len(badgeParams.Segments))
+	for i, segment := range badgeParams.Segments {
+		segments[i] = Segment{Text: segment.Text, Link: segment.Link}
+		if segment.Color != "" {
+			if segments[i].Color = parseColor(segment.Color); segments[i].Color == "" {
+				segments[i].Color = DefaultColor
+			}
+		}
+	}
+	if err := newBadge.layout(segments, badgeParams.Icon, badgeParams.Logo); err != nil {
+		return nil, err
+	}
+
+	return &newBadge, nil
+}
+
+// CreateSegments generates a SVG badge with any number of segments
+func CreateSegments(params *SegmentParams) (string, error) {
+	newBadge, err := generateSegmentedBadge(params)
+	if err != nil {
+		return "", err
+	}
+
+	return newBadge.render()
+}
+
+// ResolveSegments returns the multi-segment badge parameters as rendered by `CreateSegments`, with defaults applied
+// & unsupported values discarded
+func ResolveSegments(params *SegmentParams) (*SegmentParams, error) {
+	newBadge, err := generateSegmentedBadge(params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SegmentParams{
+		Segments:   make([]Segment, len(newBadge.Segments)),
+		Icon:       newBadge.IconLabel,
+		Logo:       newBadge.Logo,
+		Style:      newBadge.Style,
+		FontFamily: newBadge.FontFamily,
+		FontSize:   newBadge.FontSize,
+		Link:       newBadge.Link,
+	}
+	for i, segment := range newBadge.Segments {
+		result.Segments[i].Text = unisolateText(segment.Text)
+		result.Segments[i].Link = segment.Link
+		if !segment.Label {
+			result.Segments[i].Color = segment.Color
+		}
+	}
+
+	return result, nil
+}
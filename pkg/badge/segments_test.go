@@ -0,0 +1,185 @@
+package badge
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"testing"
+	"text/template"
+
+	"github.com/bradleyjkemp/cupaloy"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSegmentParams = SegmentParams{
+	Segments: []Segment{
+		{Text: "build"},
+		{Text: "passing", Color: "green"},
+		{Text: "coverage"},
+		{Text: "87%", Color: "#DFB317"},
+		{Text: "go"},
+		{Text: "1.22", Color: "invalid"},
+	},
+	Icon: "brands/golang",
+}
+
+func TestSnapshotCreateSegments(t *testing.T) {
+	t.Parallel()
+
+	for _, style := range []Style{ClassicStyle, SemaphoreCIStyle, ForTheBadgeStyle} {
+		t.Run(string(style), func(t *testing.T) {
+			params := testSegmentParams
+			params.Style = style
+			result, err := CreateSegments(&params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cupaloy.SnapshotT(t, result)
+		})
+	}
+}
+
+func TestCreateSegments(t *testing.T) {
+	t.Parallel()
+
+	// two-segment badges are laid out like badges with a subject & status
+	twoSegmentBadge, err := generateSegmentedBadge(&SegmentParams{
+		Segments: []Segment{{Text: "build"}, {Text: "passing", Color: "green"}},
+		Icon:     "brands/github",
+		Style:    FlatStyle,
+	})
+	assert.NoError(t, err)
+	newBadge, err := generateBadge(&Params{Subject: "build", Status: "passing", Color: "green", Icon: "brands/github",
+		Style: FlatStyle})
+	assert.NoError(t, err)
+	assert.Equal(t, newBadge.Segments[0].Start, twoSegmentBadge.Segments[0].Start)
+	assert.Equal(t, newBadge.Segments[1].Start, twoSegmentBadge.Segments[1].Start)
+	assert.Equal(t, newBadge.TotalWidth, twoSegmentBadge.TotalWidth)
+
+	// Segments with links are wrapped in links
+	result, err := CreateSegments(&SegmentParams{
+		Segments: []Segment{{Text: "build", Link: "https://example.com/build"}, {Text: "passing", Color: "green"},
+			{Text: "coverage", Link: "javascript:alert(1)"}, {Text: "87%", Color: "yellow"}},
+		Link: "https://example.com",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, result, `<a id="link" target="_blank" xlink:href="https://example.com">`)
+	assert.Contains(t, result, `<a target="_blank" xlink:href="https://example.com/build">`)
+	assert.NotContains(t, result, "javascript:")
+
+	_, err = CreateSegments(&SegmentParams{Segments: testSegmentParams.Segments, Style: SocialStyle})
+	assert.EqualError(t, err, "Badge style does not support multiple segments: social")
+	_, err = CreateSegments(&SegmentParams{})
+	assert.EqualError(t, err, "Badge has no segments")
+	var segmentsErr *SegmentsError
+	assert.ErrorAs(t, err, &segmentsErr)
+}
+
+func TestSupportsSegments(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]bool{
+		`{{range .Segments}}<text>{{.Text}}</text>{{end}}`:                                true,
+		`{{range $i, $segment := .Segments}}<text>{{$segment.Text}}</text>{{end}}`:        true,
+		`{{if .Icon}}{{with .Subject}}{{range .Segments}}{{end}}{{end}}{{end}}`:           true,
+		`{{define "segments"}}{{range .Segments}}{{end}}{{end}}{{template "segments" .}}`: true,
+		`<text>{{.Subject}}</text><!-- .Segments -->`:                                     false,
+		`<text>{{index .Segments 0}}</text>`:                                              false,
+		`{{range .Icons}}{{.Segments}}{{end}}`:                                            false,
+	}
+	for text, expected := range testCases {
+		tmpl := template.Must(template.New("test").Parse(text))
+		assert.Equal(t, expected, supportsSegments(tmpl), text)
+	}
+}
+
+func TestResolveSegments(t *testing.T) {
+	t.Parallel()
+
+	params := testSegmentParams
+	params.Style = SemaphoreCIStyle
+	resolvedParams, err := ResolveSegments(&params)
+	assert.NoError(t, err)
+	assert.Equal(t, &SegmentParams{
+		Segments: []Segment{
+			{Text: "BUILD"},
+			{Text: "PASSING", Color: "green"},
+			{Text: "COVERAGE"},
+			{Text: "87%", Color: "#dfb317"},
+			{Text: "GO"},
+			{Text: "1.22", Color: DefaultColor},
+		},
+		Icon:       "brands/golang",
+		Style:      SemaphoreCIStyle,
+		FontFamily: DefaultFontFamily,
+		FontSize:   9,
+	}, resolvedParams)
+}
+
+func TestCreateSegmentsPNG(t *testing.T) {
+	t.Parallel()
+
+	result, err := CreateSegmentsPNG(&testSegmentParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(result))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newBadge, err := generateSegmentedBadge(&testSegmentParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, newBadge.TotalWidth, img.Bounds().Dx())
+	assert.Equal(t, newBadge.Height, img.Bounds().Dy())
+}
+
+func FuzzCreateSegments(f *testing.F) {
+	logo := func(icon string) string {
+		return logoPrefix + base64.StdEncoding.EncodeToString([]byte(icon))
+	}
+	f.Add("build", "passing", "green", "https://example.com", logo(testIcon), "classic")
+	f.Add(`<script>alert(1)</script>`, `</text><a href="javascript:alert(1)">`, `red"/><script/>`,
+		`https://example.com/"><script>alert(1)</script>`, logo(`<svg xmlns="http://www.w3.org/2000/svg">`+
+			`<script>alert(1)</script><path d="M0 0" onload="alert(1)"/></svg>`), "flat")
+	f.Add("]]><!--", "&amp;&lt;&#x0;", "#abc' onload='alert(1)", "javascript:alert(1)",
+		logo(`<svg xmlns="http://www.w3.org/2000/svg"><image href="https://example.com/a.png"/></svg>`), "for-the-badge")
+	f.Add("\x00\x01\ufffe", "\u202e\u2068", "\n\t\r", "https://\x00", logoPrefix+"not-base64", "semaphoreci")
+	f.Add("\xff\xfe", "<?xml?>", "", "", "data:image/svg+xml;base64,"+`"><script>`, "social")
+
+	f.Fuzz(func(t *testing.T, label, text, color, link, logo, style string) {
+		params := &SegmentParams{
+			Segments: []Segment{
+				{Text: label, Link: link},
+				{Text: text, Color: color, Link: link},
+				{Text: label},
+			},
+			Logo:  logo,
+			Style: Style(style),
+			Link:  link,
+		}
+		result, err := CreateSegments(params)
+		if err != nil {
+			t.Skip(err)
+		}
+
+		// User-supplied parameters don't change the structure of the badge
+		resolvedParams, err := ResolveSegments(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments := make([]Segment, len(resolvedParams.Segments))
+		for i, segment := range resolvedParams.Segments {
+			segments[i] = Segment{Text: "text", Color: segment.Color, Link: segment.Link}
+		}
+		expected, err := CreateSegments(&SegmentParams{Segments: segments, Icon: resolvedParams.Icon,
+			Logo: resolvedParams.Logo, Style: resolvedParams.Style, Link: resolvedParams.Link})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, svgElements(t, expected), svgElements(t, result))
+	})
+}
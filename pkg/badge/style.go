@@ -0,0 +1,121 @@
+package badge
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"text/template"
+)
+
+// styleNamePattern matches valid names of registered badge styles
+var styleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+var (
+	registeredTemplates      = make(map[Style]*template.Template)
+	registeredTemplatesMutex sync.RWMutex
+)
+
+// RegisterStyle registers a badge style rendered with the given SVG template, laid out (& rasterized) like the
+// classic style. The template is executed with the same fields as the built-in templates & must render a path
+// with the "fill" id & texts with the "subject" & "status" ids (nested in any group), so that `ExtractParams` can
+// parse its badges.
+// Registering an existing style replaces its template, except for built-in styles which cannot be replaced.
+func RegisterStyle(style Style, tmpl string) error {
+	parsedTemplate, err := parseStyle(style, tmpl)
+	if err != nil {
+		return err
+	}
+
+	registeredTemplatesMutex.Lock()
+	defer registeredTemplatesMutex.Unlock()
+	registeredTemplates[style] = parsedTemplate
+
+	return nil
+}
+
+// ValidateStyle checks that a badge style can be registered with the given SVG template (see `RegisterStyle`),
+// without registering it
+func ValidateStyle(style Style, tmpl string) error {
+	_, err := parseStyle(style, tmpl)
+	return err
+}
+
+// UnregisterStyle unregisters a badge style registered with `RegisterStyle`
+func UnregisterStyle(style Style) {
+	registeredTemplatesMutex.Lock()
+	defer registeredTemplatesMutex.Unlock()
+	delete(registeredTemplates, style)
+}
+
+// parseStyle parses & validates the SVG template of a badge style
+func parseStyle(style Style, tmpl string) (*template.Template, error) {
+	if !styleNamePattern.MatchString(string(style)) {
+		return nil, fmt.Errorf("Invalid badge style name: %q", style)
+	}
+	if _, ok := badgeTemplates[style]; ok {
+		return nil, fmt.Errorf("Built-in badge style cannot be replaced: %s", style)
+	}
+
+	parsedTemplate, err := template.New(string(style)).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid template of badge style %s: %v", style, err)
+	}
+	if err := validateTemplate(parsedTemplate); err != nil {
+		return nil, fmt.Errorf("Invalid template of badge style %s: %v", style, err)
+	}
+
+	return parsedTemplate, nil
+}
+
+// Styles returns all supported badge styles, including registered styles
+func Styles() []Style {
+	registeredTemplatesMutex.RLock()
+	defer registeredTemplatesMutex.RUnlock()
+
+	result := append([]Style{}, SupportedStyles[:]...)
+	registered := make([]Style, 0, len(registeredTemplates))
+	for style := range registeredTemplates {
+		registered = append(registered, style)
+	}
+	sort.Slice(registered, func(i, j int) bool { return registered[i] < registered[j] })
+
+	return append(result, registered...)
+}
+
+// registeredTemplate returns the template of a registered badge style, or nil if it is not registered
+func registeredTemplate(style Style) *template.Template {
+	registeredTemplatesMutex.RLock()
+	defer registeredTemplatesMutex.RUnlock()
+
+	return registeredTemplates[style]
+}
+
+// validateTemplate checks that badges rendered with the template expose their parameters to `ExtractParams`
+func validateTemplate(tmpl *template.Template) error {
+	sampleBadge, err := generateBadge(&Params{Subject: "subject", Status: "status", Color: "#4c1"})
+	if err != nil {
+		return err
+	}
+
+	sampleBadge.Template = tmpl
+	result, err := sampleBadge.render()
+	if err != nil {
+		return err
+	}
+	params, err := parseParams(result)
+	if err != nil {
+		return err
+	}
+	if params.Color != sampleBadge.Color {
+		return fmt.Errorf("missing path with the \"fill\" id & the badge color as its fill")
+	}
+	if params.Subject != sampleBadge.Subject {
+		return fmt.Errorf("missing text with the \"subject\" id & the badge subject as its content")
+	}
+	if params.Status != sampleBadge.Status {
+		return fmt.Errorf("missing text with the \"status\" id & the badge status as its content")
+	}
+
+	return nil
+}
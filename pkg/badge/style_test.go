@@ -0,0 +1,46 @@
+package badge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStyleTemplate = `<svg xmlns="http://www.w3.org/2000/svg" height="20" width="{{.TotalWidth}}"><g><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="#000"/><path id="fill" d="M{{.SubjectWidth}} 0h{{.StatusWidth}}v20H{{.SubjectWidth}}z" fill="{{.Color}}"/></g><g font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}"><text id="subject" fill="{{.SubjectFontColor}}" x="{{.SubjectOffset}}" y="14">{{.Subject}}</text><text id="status" fill="{{.StatusFontColor}}" x="{{.StatusOffset}}" y="14">{{.Status}}</text></g></svg>`
+
+func TestRegisterStyle(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, RegisterStyle("test-corporate", testStyleTemplate))
+	assert.Contains(t, Styles(), Style("test-corporate"))
+
+	newBadge, err := Create(&Params{Style: "test-corporate", Subject: "build", Status: "passing", Color: "green"})
+	assert.NoError(t, err)
+	newBadgeParams, err := ExtractParams(newBadge)
+	assert.NoError(t, err)
+	assert.Equal(t, Params{Style: "test-corporate", Subject: "build", Status: "passing", Color: "green",
+		FontFamily: DefaultFontFamily, FontSize: 11}, *newBadgeParams)
+
+	_, err = CreatePNG(&Params{Style: "test-corporate", Subject: "build", Status: "passing"})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, RegisterStyle(ClassicStyle, testStyleTemplate),
+		"Built-in badge style cannot be replaced: classic")
+	assert.EqualError(t, RegisterStyle("Invalid Name", testStyleTemplate), `Invalid badge style name: "Invalid Name"`)
+	assert.Error(t, RegisterStyle("test-unparsable", `<svg>{{.Subject</svg>`))
+	assert.Error(t, RegisterStyle("test-unknown-field", `<svg>{{.Unknown}}</svg>`))
+	assert.EqualError(t, RegisterStyle("test-missing-status", `<svg><g><path id="fill" fill="{{.Color}}"/></g><g><text id="subject">{{.Subject}}</text></g></svg>`),
+		`Invalid template of badge style test-missing-status: missing text with the "status" id & the badge status as its content`)
+	assert.NotContains(t, Styles(), Style("test-missing-status"))
+
+	// Validated styles aren't registered
+	assert.NoError(t, ValidateStyle("test-validated", testStyleTemplate))
+	assert.NotContains(t, Styles(), Style("test-validated"))
+	assert.EqualError(t, ValidateStyle(ClassicStyle, testStyleTemplate), "Built-in badge style cannot be replaced: classic")
+
+	assert.NoError(t, RegisterStyle("test-unregistered", testStyleTemplate))
+	UnregisterStyle("test-unregistered")
+	UnregisterStyle(ClassicStyle)
+	assert.NotContains(t, Styles(), Style("test-unregistered"))
+	assert.Contains(t, Styles(), ClassicStyle)
+}
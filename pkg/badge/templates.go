@@ -5,8 +5,10 @@ import "text/template"
 
 // styleName -> template
 var badgeTemplates = map[Style]*template.Template{
-	"classic":     template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><clipPath id="a"><rect height="20" width="{{.TotalWidth}}" rx="3"/></clipPath><g clip-path="url(#a)"><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="#555"/><path id="fill" d="M{{.SubjectWidth}} 0h{{.StatusWidth}}v20H{{.SubjectWidth}}z" fill="{{.Color}}"/><path d="M0 0h{{.TotalWidth}}v20H0z" fill="url(#b)"/></g><g font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}">{{if .IconBase64Str}}<image id="icon" alt="{{.IconLabel}}" height="12" width="12" x="{{.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{.IconBase64Str}}"></image>{{end}}<text fill="#000" fill-opacity=".3" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="15">{{.Subject}}</text><text id="subject" fill="{{.SubjectFontColor}}" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="14">{{.Subject}}</text><text fill="#000" fill-opacity=".3" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="15">{{.Status}}</text><text id="status" fill="{{.StatusFontColor}}" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="14">{{.Status}}</text></g></svg>`)),
-	"flat":        template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><clipPath id="a"><rect height="20" width="{{.TotalWidth}}"/></clipPath><g clip-path="url(#a)"><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="#555"/><path id="fill" d="M{{.SubjectWidth}} 0h{{.StatusWidth}}v20H{{.SubjectWidth}}z" fill="{{.Color}}"/><path d="M0 0h{{.TotalWidth}}v20H0z" fill="url(#b)"/></g><g font-family="Verdana,sans-serif" font-size="{{.FontSize}}">{{if .IconBase64Str}}<image id="icon" alt="{{.IconLabel}}" height="12" width="12" x="{{.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{.IconBase64Str}}"></image>{{end}}<text fill="#000" fill-opacity=".3" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="15">{{.Subject}}</text><text id="subject" fill="{{.SubjectFontColor}}" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="14">{{.Subject}}</text><text fill="#000" fill-opacity=".3" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="15">{{.Status}}</text><text id="status" fill="{{.StatusFontColor}}" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="14">{{.Status}}</text></g></svg>`)),
-	"plastic":     template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/><stop offset=".9" stop-color="#000" stop-opacity=".3"/><stop offset="1" stop-color="#000" stop-opacity=".5"/></linearGradient><clipPath id="a"><rect height="20" width="{{.TotalWidth}}" rx="3"/></clipPath><g clip-path="url(#a)"><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="#555"/><path id="fill" d="M{{.SubjectWidth}} 0h{{.StatusWidth}}v20H{{.SubjectWidth}}z" fill="{{.Color}}"/><path d="M0 0h{{.TotalWidth}}v20H0z" fill="url(#b)"/></g><g font-family="Verdana,sans-serif" font-size="{{.FontSize}}">{{if .IconBase64Str}}<image id="icon" alt="{{.IconLabel}}" height="12" width="12" x="{{.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{.IconBase64Str}}"></image>{{end}}<text fill="#000" fill-opacity=".3" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="15">{{.Subject}}</text><text id="subject" fill="{{.SubjectFontColor}}" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="14">{{.Subject}}</text><text fill="#000" fill-opacity=".3" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="15">{{.Status}}</text><text id="status" fill="{{.StatusFontColor}}" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="14">{{.Status}}</text></g></svg>`)),
-	"semaphoreci": template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><clipPath id="a"><rect height="20" width="{{.TotalWidth}}" rx="2"/></clipPath><g clip-path="url(#a)"><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="#f1f1f1"/><path id="fill" d="M{{.SubjectWidth}} 0h{{.StatusWidth}}v20H{{.SubjectWidth}}z" fill="{{.Color}}"/></g><g font-family="Verdana,sans-serif" font-size="{{.FontSize}}">{{if .IconBase64Str}}<image id="icon" alt="{{.IconLabel}}" height="12" width="12" x="{{.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{.IconBase64Str}}"></image>{{end}}<text id="subject" fill="{{.SubjectFontColor}}" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="13">{{.Subject}}</text><text id="status" fill="{{.StatusFontColor}}" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="13">{{.Status}}</text></g></svg>`)),
+	"classic":       template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient><clipPath id="a"><rect height="20" width="{{.TotalWidth}}" rx="3"/></clipPath>{{if .Link}}<a id="link" target="_blank" xlink:href="{{.Link}}">{{end}}<g clip-path="url(#a)" font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}">{{range $index, $segment := .Segments}}{{if .Link}}<a{{if .LinkID}} id="{{.LinkID}}"{{end}} target="_blank" xlink:href="{{.Link}}">{{end}}<g><path{{if .PathID}} id="{{.PathID}}"{{end}} d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="{{.Color}}"/><path d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="url(#b)"/>{{if and (eq $index 0) $.IconBase64Str}}<image id="icon" alt="{{$.IconLabel}}" height="12" width="12" x="{{$.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{$.IconBase64Str}}"></image>{{end}}<text fill="#000" fill-opacity=".3" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="15">{{.Text}}</text><text{{if .TextID}} id="{{.TextID}}"{{end}} fill="{{.FontColor}}" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="14">{{.Text}}</text></g>{{if .Link}}</a>{{end}}{{end}}</g>{{if .Link}}</a>{{end}}</svg>`)),
+	"flat":          template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><clipPath id="a"><rect height="20" width="{{.TotalWidth}}"/></clipPath>{{if .Link}}<a id="link" target="_blank" xlink:href="{{.Link}}">{{end}}<g clip-path="url(#a)" font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}">{{range $index, $segment := .Segments}}{{if .Link}}<a{{if .LinkID}} id="{{.LinkID}}"{{end}} target="_blank" xlink:href="{{.Link}}">{{end}}<g><path{{if .PathID}} id="{{.PathID}}"{{end}} d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="{{.Color}}"/><path d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="url(#b)"/>{{if and (eq $index 0) $.IconBase64Str}}<image id="icon" alt="{{$.IconLabel}}" height="12" width="12" x="{{$.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{$.IconBase64Str}}"></image>{{end}}<text fill="#000" fill-opacity=".3" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="15">{{.Text}}</text><text{{if .TextID}} id="{{.TextID}}"{{end}} fill="{{.FontColor}}" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="14">{{.Text}}</text></g>{{if .Link}}</a>{{end}}{{end}}</g>{{if .Link}}</a>{{end}}</svg>`)),
+	"for-the-badge": template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="28" width="{{.TotalWidth}}">{{if .Link}}<a id="link" target="_blank" xlink:href="{{.Link}}">{{end}}<g font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}" font-weight="bold" letter-spacing="{{.LetterSpacing}}">{{range $index, $segment := .Segments}}{{if .Link}}<a{{if .LinkID}} id="{{.LinkID}}"{{end}} target="_blank" xlink:href="{{.Link}}">{{end}}<g><path{{if .PathID}} id="{{.PathID}}"{{end}} d="M{{.Start}} 0h{{.Width}}v28H{{.Start}}z" fill="{{.Color}}"/>{{if and (eq $index 0) $.IconBase64Str}}<image id="icon" alt="{{$.IconLabel}}" height="12" width="12" x="{{$.PaddingOuter}}" y="8" xlink:href="data:image/svg+xml;base64,{{$.IconBase64Str}}"></image>{{end}}<text{{if .TextID}} id="{{.TextID}}"{{end}} fill="{{.FontColor}}" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="18">{{.Text}}</text></g>{{if .Link}}</a>{{end}}{{end}}</g>{{if .Link}}</a>{{end}}</svg>`)),
+	"plastic":       template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/><stop offset=".9" stop-color="#000" stop-opacity=".3"/><stop offset="1" stop-color="#000" stop-opacity=".5"/></linearGradient><clipPath id="a"><rect height="20" width="{{.TotalWidth}}" rx="3"/></clipPath>{{if .Link}}<a id="link" target="_blank" xlink:href="{{.Link}}">{{end}}<g clip-path="url(#a)" font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}">{{range $index, $segment := .Segments}}{{if .Link}}<a{{if .LinkID}} id="{{.LinkID}}"{{end}} target="_blank" xlink:href="{{.Link}}">{{end}}<g><path{{if .PathID}} id="{{.PathID}}"{{end}} d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="{{.Color}}"/><path d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="url(#b)"/>{{if and (eq $index 0) $.IconBase64Str}}<image id="icon" alt="{{$.IconLabel}}" height="12" width="12" x="{{$.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{$.IconBase64Str}}"></image>{{end}}<text fill="#000" fill-opacity=".3" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="15">{{.Text}}</text><text{{if .TextID}} id="{{.TextID}}"{{end}} fill="{{.FontColor}}" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="14">{{.Text}}</text></g>{{if .Link}}</a>{{end}}{{end}}</g>{{if .Link}}</a>{{end}}</svg>`)),
+	"semaphoreci":   template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><clipPath id="a"><rect height="20" width="{{.TotalWidth}}" rx="2"/></clipPath>{{if .Link}}<a id="link" target="_blank" xlink:href="{{.Link}}">{{end}}<g clip-path="url(#a)" font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}">{{range $index, $segment := .Segments}}{{if .Link}}<a{{if .LinkID}} id="{{.LinkID}}"{{end}} target="_blank" xlink:href="{{.Link}}">{{end}}<g><path{{if .PathID}} id="{{.PathID}}"{{end}} d="M{{.Start}} 0h{{.Width}}v20H{{.Start}}z" fill="{{.Color}}"/>{{if and (eq $index 0) $.IconBase64Str}}<image id="icon" alt="{{$.IconLabel}}" height="12" width="12" x="{{$.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{$.IconBase64Str}}"></image>{{end}}<text{{if .TextID}} id="{{.TextID}}"{{end}} fill="{{.FontColor}}" textLength="{{.TextWidth}}" x="{{.TextOffset}}" y="13">{{.Text}}</text></g>{{if .Link}}</a>{{end}}{{end}}</g>{{if .Link}}</a>{{end}}</svg>`)),
+	"social":        template.Must(template.New("").Parse(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" height="20" width="{{.TotalWidth}}"><linearGradient id="b" x2="0" y2="100%"><stop offset="0" stop-color="#fcfcfc" stop-opacity="0"/><stop offset="1" stop-opacity=".1"/></linearGradient><clipPath id="a"><rect height="20" width="{{.SubjectWidth}}" rx="2"/></clipPath><clipPath id="c"><rect height="20" width="{{.StatusWidth}}" x="{{.StatusStart}}" rx="2"/></clipPath>{{if .Link}}<a id="link" target="_blank" xlink:href="{{.Link}}">{{end}}<g font-family="{{.FontFamily}},sans-serif" font-size="{{.FontSize}}">{{if .SubjectLink}}<a id="subject-link" target="_blank" xlink:href="{{.SubjectLink}}">{{end}}<g><g clip-path="url(#a)"><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="#fcfcfc"/><path d="M0 0h{{.SubjectWidth}}v20H0z" fill="url(#b)"/><rect height="20" width="{{.SubjectWidth}}" rx="2" fill="none" stroke="#d5d5d5" stroke-width="2"/></g>{{if .IconBase64Str}}<image id="icon" alt="{{.IconLabel}}" height="12" width="12" x="{{.PaddingOuter}}" y="4" xlink:href="data:image/svg+xml;base64,{{.IconBase64Str}}"></image>{{end}}<text fill="#fff" font-weight="bold" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="15">{{.Subject}}</text><text id="subject" fill="{{.SubjectFontColor}}" font-weight="bold" textLength="{{.SubjectTextWidth}}" x="{{.SubjectOffset}}" y="14">{{.Subject}}</text></g>{{if .SubjectLink}}</a>{{end}}{{if .StatusLink}}<a id="status-link" target="_blank" xlink:href="{{.StatusLink}}">{{end}}<g><g clip-path="url(#c)"><path id="fill" d="M{{.StatusStart}} 0h{{.StatusWidth}}v20H{{.StatusStart}}z" fill="{{.Color}}"/></g><path d="M{{.StatusStart}} 6l-4 4 4 4z" fill="{{.Color}}"/><text id="status" fill="{{.StatusFontColor}}" textLength="{{.StatusTextWidth}}" x="{{.StatusOffset}}" y="14">{{.Status}}</text></g>{{if .StatusLink}}</a>{{end}}</g>{{if .Link}}</a>{{end}}</svg>`)),
 }
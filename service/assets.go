@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// badgeAssets holds the badge styles, font families & icons loaded from the configured directories, which are
+// validated when loaded but only registered once their configuration is applied
+type badgeAssets struct {
+	// styles maps badge styles onto their SVG templates, nil if no template directory is configured
+	styles map[badge.Style]string
+	// fonts maps font families onto their TrueType files, nil if no font directory is configured
+	fonts map[string]fontFiles
+	// icons maps icon names onto their SVG icons, nil if no icon directory is configured
+	icons map[string]string
+}
+
+// fontFiles holds the TrueType files of the regular & (optional) bold weights of a font family
+type fontFiles struct {
+	regular []byte
+	bold    []byte
+}
+
+// loadBadgeAssets loads & validates the badge styles, font families & icons of the configured directories
+func loadBadgeAssets(configuration *config.Config) (*badgeAssets, error) {
+	assets := &badgeAssets{}
+	var err error
+	if configuration.TemplateDir != "" {
+		if assets.styles, err = loadTemplates(configuration.TemplateDir); err != nil {
+			return nil, fmt.Errorf("failed to load badge templates: %v", err)
+		}
+	}
+	if configuration.FontDir != "" {
+		if assets.fonts, err = loadFonts(configuration.FontDir); err != nil {
+			return nil, fmt.Errorf("failed to load fonts: %v", err)
+		}
+	}
+	if configuration.IconDir != "" {
+		if assets.icons, err = loadIcons(configuration.IconDir); err != nil {
+			return nil, fmt.Errorf("failed to load icons: %v", err)
+		}
+	}
+
+	return assets, nil
+}
+
+// register registers the assets, replacing the previously registered assets (which may be nil) & unregistering
+// the ones that were removed from their directories
+func (assets *badgeAssets) register(previous *badgeAssets, logger *zap.Logger) error {
+	var errs []error
+	for style, tmpl := range assets.styles {
+		errs = append(errs, badge.RegisterStyle(style, tmpl))
+	}
+	for family, files := range assets.fonts {
+		errs = append(errs, badge.RegisterFont(family, files.regular, files.bold))
+	}
+	for name, icon := range assets.icons {
+		errs = append(errs, badge.RegisterIcon(name, icon))
+	}
+	if previous != nil {
+		for style := range previous.styles {
+			if _, ok := assets.styles[style]; !ok {
+				badge.UnregisterStyle(style)
+			}
+		}
+		for family := range previous.fonts {
+			if _, ok := assets.fonts[family]; !ok {
+				badge.UnregisterFont(family)
+			}
+		}
+		for name := range previous.icons {
+			if _, ok := assets.icons[name]; !ok {
+				badge.UnregisterIcon(name)
+			}
+		}
+	}
+
+	if assets.styles != nil {
+		logger.Info("Registered badge templates", zap.Any("styles", slices.Sorted(maps.Keys(assets.styles))))
+	}
+	if assets.fonts != nil {
+		logger.Info("Registered fonts", zap.Strings("fonts", slices.Sorted(maps.Keys(assets.fonts))))
+	}
+	if assets.icons != nil {
+		logger.Info("Registered icons", zap.Strings("icons", slices.Sorted(maps.Keys(assets.icons))))
+	}
+
+	return errors.Join(errs...)
+}
+
+// registerDir registers the files of a directory matching the given pattern (eg. "*.tmpl") with `register`. All
+// files are registered even if some of them fail, returning the errors of the failed files.
+func registerDir(dir string, pattern string, register func(path string, content []byte) error) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := register(path, content); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", path, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
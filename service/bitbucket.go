@@ -1,30 +1,67 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 
-	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
-	"github.com/tohjustin/aegis/pkg/badge"
 	"github.com/tohjustin/aegis/service/config"
 )
 
+// bitbucketBaseURL is the base URL of the Bitbucket Cloud REST API
+const bitbucketBaseURL = "https://api.bitbucket.org/2.0"
+
 type bitbucketService struct {
-	name   string
-	config *config.Config
-	logger *zap.Logger
+	name    string
+	baseURL string
+	cache   *responseCache
+	client  *http.Client
+	handler BadgeService
+	config  *config.Config
+	logger  *zap.Logger
 }
 
 type bitbucketFilteredResponse struct {
 	Size int `json:"size"`
 }
 
+type bitbucketRepositoryResponse struct {
+	MainBranch struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+}
+
+type bitbucketPipelinesResponse struct {
+	Values []struct {
+		State struct {
+			Name   string `json:"name"`
+			Result struct {
+				Name string `json:"name"`
+			} `json:"result"`
+		} `json:"state"`
+	} `json:"values"`
+}
+
+type bitbucketTagsResponse struct {
+	Values []struct {
+		Name string `json:"name"`
+	} `json:"values"`
+}
+
 // NewBitbucketService returns a HTTP handler for the Bitbucket badge service
 func NewBitbucketService(configuration *config.Config,
 	logger *zap.Logger) (GitProviderService, error) {
+	return newBitbucketService(configuration, logger, nil)
+}
+
+// newBitbucketService returns a HTTP handler for the Bitbucket badge service, carrying over its response cache from
+// the given service state
+func newBitbucketService(configuration *config.Config,
+	logger *zap.Logger, state *serviceState) (GitProviderService, error) {
 	if configuration == nil {
 		return nil, fmt.Errorf("missing config dependency")
 	}
@@ -32,29 +69,56 @@ func NewBitbucketService(configuration *config.Config,
 		return nil, fmt.Errorf("missing logger dependency")
 	}
 
-	return &bitbucketService{
-		name:   "bitbucket",
-		config: configuration,
-		logger: logger,
-	}, nil
+	cache := loadResponseCache(state, configuration.BitbucketCacheTTL, configuration.CacheStaleTTL,
+		"bitbucket", bitbucketBaseURL, configuration.BitbucketUsername, configuration.BitbucketAppPassword,
+		configuration.BitbucketAccessToken)
+	service := &bitbucketService{
+		name:    "bitbucket",
+		baseURL: bitbucketBaseURL,
+		cache:   cache,
+		client:  &http.Client{Transport: newInstrumentedTransport("bitbucket", nil)},
+		config:  configuration,
+		logger:  logger,
+	}
+	handler, err := newProviderService(configuration, logger, service.name, service.methods())
+	if err != nil {
+		return nil, err
+	}
+	service.handler = handler
+
+	return service, nil
 }
 
 func (service *bitbucketService) fetch(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	return service.fetchContext(context.Background(), url)
+}
+
+func (service *bitbucketService) fetchContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
+	switch {
+	case service.config.BitbucketAccessToken != "":
+		req.Header.Set("Authorization", "Bearer "+service.config.BitbucketAccessToken)
+	case service.config.BitbucketAppPassword != "":
+		req.SetBasicAuth(service.config.BitbucketUsername, service.config.BitbucketAppPassword)
+	}
 
-	resp, err := (&http.Client{}).Do(req)
+	resp, err := service.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &upstreamStatusError{statusCode: resp.StatusCode}
+	}
 
 	return resp, err
 }
 
 func (service *bitbucketService) getForkCount(owner string, repo string) (int, error) {
-	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s/forks?&fields=size", owner, repo)
+	url := fmt.Sprintf("%s/repositories/%s/%s/forks?&fields=size", service.baseURL, owner, repo)
 	resp, err := service.fetch(url)
 	if err != nil {
 		return 0, err
@@ -70,7 +134,7 @@ func (service *bitbucketService) getForkCount(owner string, repo string) (int, e
 }
 
 func (service *bitbucketService) getIssueCount(owner string, repo string, issueState string) (int, error) {
-	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s/issues", owner, repo)
+	url := fmt.Sprintf("%s/repositories/%s/%s/issues", service.baseURL, owner, repo)
 	switch issueState {
 	case "new":
 		url = fmt.Sprintf("%s?&fields=size&q=(state+=+\"%s\")", url, issueState)
@@ -103,8 +167,109 @@ func (service *bitbucketService) getIssueCount(owner string, repo string, issueS
 	return issues.Size, nil
 }
 
+// getLatestRelease returns the latest semantically versioned tag, as Bitbucket has no releases
+func (service *bitbucketService) getLatestRelease(owner string, repo string, includePrereleases bool) (string, error) {
+	tags, err := service.getTags(owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	release, ok := latestVersion(tags, includePrereleases)
+	if !ok {
+		return "", errNoReleases
+	}
+
+	return release, nil
+}
+
+func (service *bitbucketService) getLatestTag(owner string, repo string, includePrereleases bool) (string, error) {
+	tags, err := service.getTags(owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	return latestTag(tags, includePrereleases)
+}
+
+func (service *bitbucketService) getTags(owner string, repo string) ([]string, error) {
+	url := fmt.Sprintf("%s/repositories/%s/%s/refs/tags?pagelen=100&sort=-target.date&fields=values.name",
+		service.baseURL, owner, repo)
+	resp, err := service.fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var bitbucketTags bitbucketTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&bitbucketTags); err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0, len(bitbucketTags.Values))
+	for _, tag := range bitbucketTags.Values {
+		tags = append(tags, tag.Name)
+	}
+
+	return tags, nil
+}
+
+// getPipelineStatus returns the status of the latest Bitbucket Pipelines run on a branch (defaults to the
+// main branch)
+func (service *bitbucketService) getPipelineStatus(owner string, repo string, branch string, workflow string) (string, error) {
+	if branch == "" {
+		url := fmt.Sprintf("%s/repositories/%s/%s?fields=mainbranch.name", service.baseURL, owner, repo)
+		resp, err := service.fetch(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		var repository bitbucketRepositoryResponse
+		if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+			return "", err
+		}
+		branch = repository.MainBranch.Name
+	}
+
+	url := fmt.Sprintf("%s/repositories/%s/%s/pipelines/?target.branch=%s&sort=-created_on&pagelen=1",
+		service.baseURL, owner, repo, neturl.QueryEscape(branch))
+	resp, err := service.fetch(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var pipelines bitbucketPipelinesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pipelines); err != nil {
+		return "", err
+	}
+	if len(pipelines.Values) == 0 {
+		return "", errNoPipelines
+	}
+	state := pipelines.Values[0].State
+
+	return bitbucketPipelineStatus(state.Name, state.Result.Name), nil
+}
+
+// bitbucketPipelineStatus normalizes the state & result of a Bitbucket Pipelines run
+func bitbucketPipelineStatus(state string, result string) string {
+	if state != "COMPLETED" {
+		return pipelineRunning
+	}
+
+	switch result {
+	case "SUCCESSFUL":
+		return pipelinePassing
+	case "FAILED", "ERROR":
+		return pipelineFailing
+	case "STOPPED":
+		return pipelineCancelled
+	default:
+		return pipelineUnknown
+	}
+}
+
 func (service *bitbucketService) getPullRequestCount(owner string, repo string, pullRequestState string) (int, error) {
-	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s/pullrequests", owner, repo)
+	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests", service.baseURL, owner, repo)
 	switch pullRequestState {
 	case "merged":
 		url = fmt.Sprintf("%s?&fields=size&q=(state+=+\"%s\")", url, pullRequestState)
@@ -133,161 +298,49 @@ func (service *bitbucketService) getStarCount(owner string, repo string) (int, e
 	return -2, nil
 }
 
-func (service *bitbucketService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	routeVariables := mux.Vars(r)
-	owner := routeVariables["owner"]
-	repo := routeVariables["repo"]
-	method := routeVariables["method"]
-
-	// Fetch data
-	var color, status, subject string
-	var value int
-	var err error
-	switch method {
-	case "forks":
-		subject = "forks"
-		value, err = service.getForkCount(owner, repo)
-	case "issues":
-		state := r.URL.Query().Get("state")
-		switch state {
-		case "":
-			subject = "issues"
-		case "new":
-			subject = "new issues"
-		case "open":
-			subject = "open issues"
-		case "resolved":
-			subject = "resolved issues"
-		case "on-hold":
-			subject = "on-hold issues"
-		case "invalid":
-			subject = "invalid issues"
-		case "duplicate":
-			subject = "duplicate issues"
-		case "wontfix":
-			subject = "wontfix issues"
-		case "closed":
-			subject = "closed issues"
-		default:
-			service.logger.Info("Unsupported state",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.String("state", state))
-			if err := badRequest(w, service.config); err != nil {
-				service.logger.Error("Failed to create error badge",
-					zap.String("url", r.URL.RequestURI()),
-					zap.String("service", service.name),
-					zap.String("method", method),
-					zap.Error(err))
-			}
-			return
-		}
-		value, err = service.getIssueCount(owner, repo, state)
-	case "pull-requests":
-		state := r.URL.Query().Get("state")
-		switch state {
-		case "":
-			subject = "PRs"
-		case "merged":
-			subject = "merged PRs"
-		case "superseded":
-			subject = "superseded PRs"
-		case "open":
-			subject = "open PRs"
-		case "declined":
-			subject = "declined PRs"
-		default:
-			service.logger.Info("Unsupported state",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.String("state", state))
-			if err := badRequest(w, service.config); err != nil {
-				service.logger.Error("Failed to create error badge",
-					zap.String("url", r.URL.RequestURI()),
-					zap.String("service", service.name),
-					zap.String("method", method),
-					zap.Error(err))
-			}
-			return
-		}
-		value, err = service.getPullRequestCount(owner, repo, state)
-	case "stars":
-		subject = "stars"
-		value, err = service.getStarCount(owner, repo)
-	default:
-		service.logger.Info("Unsupported method",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method))
-		if err := notFound(w, service.config); err != nil {
-			service.logger.Error("Failed to create error badge",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.Error(err))
-		}
-		return
+// probe checks that the Bitbucket API is reachable & the credentials (if any) are valid
+func (service *bitbucketService) probe(ctx context.Context) error {
+	url := fmt.Sprintf("%s/user", service.baseURL)
+	if service.config.BitbucketAccessToken == "" && service.config.BitbucketAppPassword == "" {
+		url = fmt.Sprintf("%s/repositories?pagelen=1&fields=pagelen", service.baseURL)
 	}
+	resp, err := service.fetchContext(ctx, url)
 	if err != nil {
-		service.logger.Error("Failed to fetch data",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-		if err := internalServerError(w, service.config); err != nil {
-			service.logger.Error("Failed to create error badge",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.Error(err))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		}
-		return
+		return err
 	}
-	status = formatIntegerWithMetricPrefix(value)
 
-	// Overwrite any badge texts
-	if queryColor := r.URL.Query().Get("color"); queryColor != "" {
-		color = queryColor
-	}
-	if queryStatus := r.URL.Query().Get("status"); queryStatus != "" {
-		status = queryStatus
-	}
-	if querySubject := r.URL.Query().Get("subject"); querySubject != "" {
-		subject = querySubject
-	}
+	return resp.Body.Close()
+}
 
-	// Generate badge
-	generatedBadge, err := badge.Create(&badge.Params{
-		Style:   badge.Style(r.URL.Query().Get("style")),
-		Subject: subject,
-		Status:  status,
-		Color:   color,
-		Icon:    r.URL.Query().Get("icon"),
-	})
-	if err != nil {
-		service.logger.Error("Failed to create badge",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+// methods returns the badge methods supported by the Bitbucket badge service
+func (service *bitbucketService) methods() map[string]ProviderMethod {
+	return map[string]ProviderMethod{
+		"forks": countMethod(service.cache, service.name, "forks", service.getForkCount),
+		"issues": stateCountMethod(service.cache, service.name, map[string]string{
+			"":          "issues",
+			"new":       "new issues",
+			"open":      "open issues",
+			"resolved":  "resolved issues",
+			"on-hold":   "on-hold issues",
+			"invalid":   "invalid issues",
+			"duplicate": "duplicate issues",
+			"wontfix":   "wontfix issues",
+			"closed":    "closed issues",
+		}, service.getIssueCount),
+		"pipeline": pipelineMethod(service.cache, service.name, false, service.getPipelineStatus),
+		"pull-requests": stateCountMethod(service.cache, service.name, map[string]string{
+			"":           "PRs",
+			"merged":     "merged PRs",
+			"superseded": "superseded PRs",
+			"open":       "open PRs",
+			"declined":   "declined PRs",
+		}, service.getPullRequestCount),
+		"release": versionMethod(service.cache, service.name, "release", service.getLatestRelease),
+		"stars":   countMethod(service.cache, service.name, "stars", service.getStarCount),
+		"tag":     versionMethod(service.cache, service.name, "tag", service.getLatestTag),
 	}
+}
 
-	if !service.config.ExcludeCacheControlHeaders {
-		// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
-		w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
-	}
-	w.Header().Set("Content-Type", "image/svg+xml;utf-8")
-	_, err = w.Write([]byte(generatedBadge))
-	if err != nil {
-		service.logger.Error("Failed to write HTTP response",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-	}
+func (service *bitbucketService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	service.handler.ServeHTTP(w, r)
 }
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+func newMockBitbucketService(t *testing.T, configuration *config.Config) GitProviderService {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		authorized := r.Header.Get("Authorization") == "Bearer testToken" ||
+			(ok && username == "testUser" && password == "testPassword")
+		switch {
+		case r.URL.Path == "/repositories/owner/public/forks":
+			_, _ = w.Write([]byte(`{"size": 12}`))
+		case r.URL.Path == "/repositories/owner/private/forks" && authorized:
+			_, _ = w.Write([]byte(`{"size": 34}`))
+		case r.URL.Path == "/repositories/owner/private/forks":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/repositories/owner/public":
+			_, _ = w.Write([]byte(`{"mainbranch": {"name": "master"}}`))
+		case r.URL.Path == "/repositories/owner/public/pipelines/" && r.URL.Query().Get("target.branch") == "master":
+			_, _ = w.Write([]byte(`{"values": [{"state": {"name": "COMPLETED", "result": {"name": "FAILED"}}}]}`))
+		case r.URL.Path == "/repositories/owner/public/pipelines/" && r.URL.Query().Get("target.branch") == "dev":
+			_, _ = w.Write([]byte(`{"values": [{"state": {"name": "IN_PROGRESS", "stage": {"name": "RUNNING"}}}]}`))
+		case r.URL.Path == "/repositories/owner/public/refs/tags":
+			_, _ = w.Write([]byte(`{"values": [{"name": "v3.0.0-beta"}, {"name": "v2.1.0"}, {"name": "v2.0.3"}]}`))
+		case r.URL.Path == "/repositories/owner/untagged/refs/tags":
+			_, _ = w.Write([]byte(`{"values": []}`))
+		case r.URL.Path == "/repositories/owner/forbidden/forks":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	service, err := NewBitbucketService(configuration, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.(*bitbucketService).baseURL = upstream.URL
+
+	return service
+}
+
+func TestBitbucketBadgeService(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		config       *config.Config
+		requestPath  string
+		expectedBody string
+	}{
+		{
+			name:         "Anonymous",
+			config:       &config.Config{},
+			requestPath:  "/forks/owner/public",
+			expectedBody: createBadge(&badge.Params{Subject: "forks", Status: "12"}),
+		},
+		{
+			name:         "AppPassword",
+			config:       &config.Config{BitbucketUsername: "testUser", BitbucketAppPassword: "testPassword"},
+			requestPath:  "/forks/owner/private",
+			expectedBody: createBadge(&badge.Params{Subject: "forks", Status: "34"}),
+		},
+		{
+			name:         "AccessToken",
+			config:       &config.Config{BitbucketAccessToken: "testToken"},
+			requestPath:  "/forks/owner/private",
+			expectedBody: createBadge(&badge.Params{Subject: "forks", Status: "34"}),
+		},
+		{
+			name:         "Unauthorized",
+			config:       &config.Config{BitbucketAccessToken: "badToken"},
+			requestPath:  "/forks/owner/private",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "unauthorized"}),
+		},
+		{
+			name:         "Forbidden",
+			config:       &config.Config{},
+			requestPath:  "/forks/owner/forbidden",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "forbidden"}),
+		},
+		{
+			name:         "RepoNotFound",
+			config:       &config.Config{},
+			requestPath:  "/forks/owner/missing",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "repo not found"}),
+		},
+		{
+			name:         "Pipeline",
+			config:       &config.Config{},
+			requestPath:  "/pipeline/owner/public",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "failing", Color: "#e05d44"}),
+		},
+		{
+			name:         "PipelineBranch",
+			config:       &config.Config{},
+			requestPath:  "/pipeline/owner/public?branch=dev",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "running", Color: "#dfb317"}),
+		},
+		{
+			name:         "Release",
+			config:       &config.Config{},
+			requestPath:  "/release/owner/public",
+			expectedBody: createBadge(&badge.Params{Subject: "release", Status: "v2.1.0", Color: "#007ec6"}),
+		},
+		{
+			name:         "Tag",
+			config:       &config.Config{},
+			requestPath:  "/tag/owner/public?include_prereleases=true",
+			expectedBody: createBadge(&badge.Params{Subject: "tag", Status: "v3.0.0-beta", Color: "#fe7d37"}),
+		},
+		{
+			name:         "NoTags",
+			config:       &config.Config{},
+			requestPath:  "/tag/owner/untagged",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no tags"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			service := newMockBitbucketService(t, testCase.config)
+			res := serveHTTPRequest(t, newGitProviderRouter(service), testCase.requestPath)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries is the number of cache entries above which the least recently used entries are evicted
+const maxCacheEntries = 10000
+
+// cacheKey identifies an upstream response of a git provider
+type cacheKey struct {
+	provider string
+	method   string
+	owner    string
+	repo     string
+	state    string
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	value     interface{}
+	fetchedAt time.Time
+}
+
+// cacheCall represents an in-flight upstream call shared by concurrent requests
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// responseCache caches upstream responses in memory.
+//
+// Entries younger than `ttl` are served as is. Entries younger than `ttl + staleTTL` are served
+// while being revalidated in the background. Concurrent misses for the same key are coalesced into
+// a single upstream call. Above `maxEntries` entries, the least recently used entries are evicted.
+type responseCache struct {
+	ttl        time.Duration
+	staleTTL   time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mutex   sync.Mutex
+	entries map[cacheKey]*list.Element
+	recency *list.List // entries from the most to the least recently used
+	calls   map[cacheKey]*cacheCall
+}
+
+// newResponseCache returns a response cache, or nil if caching is disabled (ie. `ttl` is zero)
+func newResponseCache(ttl time.Duration, staleTTL time.Duration) *responseCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &responseCache{
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		maxEntries: maxCacheEntries,
+		now:        time.Now,
+		entries:    make(map[cacheKey]*list.Element),
+		recency:    list.New(),
+		calls:      make(map[cacheKey]*cacheCall),
+	}
+}
+
+// loadResponseCache returns a response cache from the service state, carried over from the previous configuration if
+// it was loaded with the same keys (ie. the upstream & credentials of a provider) & TTLs
+func loadResponseCache(state *serviceState, ttl time.Duration, staleTTL time.Duration,
+	keys ...interface{}) *responseCache {
+	return carryOver(state, func() *responseCache {
+		return newResponseCache(ttl, staleTTL)
+	}, append([]interface{}{"cache", ttl, staleTTL}, keys...)...)
+}
+
+// get returns the cached value for the given key, calling `fetch` to populate the cache if needed
+func (cache *responseCache) get(key cacheKey, fetch func() (interface{}, error)) (interface{}, error) {
+	if cache == nil {
+		return fetch()
+	}
+
+	cache.mutex.Lock()
+	if element, ok := cache.entries[key]; ok {
+		cache.recency.MoveToFront(element)
+		entry := element.Value.(*cacheEntry)
+		age := cache.now().Sub(entry.fetchedAt)
+		if age < cache.ttl {
+			cache.mutex.Unlock()
+			cacheRequestsTotal.WithLabelValues(key.provider, "hit").Inc()
+			return entry.value, nil
+		}
+		if age < cache.ttl+cache.staleTTL {
+			if _, ok := cache.calls[key]; !ok {
+				go cache.call(key, cache.startCall(key), fetch)
+			}
+			cache.mutex.Unlock()
+			cacheRequestsTotal.WithLabelValues(key.provider, "stale").Inc()
+			return entry.value, nil
+		}
+	}
+	if inflight, ok := cache.calls[key]; ok {
+		cache.mutex.Unlock()
+		cacheRequestsTotal.WithLabelValues(key.provider, "shared").Inc()
+		<-inflight.done
+		return inflight.value, inflight.err
+	}
+	inflight := cache.startCall(key)
+	cache.mutex.Unlock()
+	cacheRequestsTotal.WithLabelValues(key.provider, "miss").Inc()
+
+	cache.call(key, inflight, fetch)
+	return inflight.value, inflight.err
+}
+
+// startCall registers an in-flight call for the given key, must be called with the lock held
+func (cache *responseCache) startCall(key cacheKey) *cacheCall {
+	inflight := &cacheCall{done: make(chan struct{})}
+	cache.calls[key] = inflight
+	return inflight
+}
+
+// call executes `fetch` on behalf of all requests waiting on the in-flight call & stores the result
+func (cache *responseCache) call(key cacheKey, inflight *cacheCall, fetch func() (interface{}, error)) {
+	inflight.value, inflight.err = fetch()
+
+	cache.mutex.Lock()
+	delete(cache.calls, key)
+	if inflight.err == nil {
+		cache.store(key, inflight.value)
+	}
+	cache.mutex.Unlock()
+
+	close(inflight.done)
+}
+
+// store sets the entry of the given key as the most recently used one, evicting the least recently used entry if
+// the cache is full, must be called with the lock held
+func (cache *responseCache) store(key cacheKey, value interface{}) {
+	entry := &cacheEntry{key: key, value: value, fetchedAt: cache.now()}
+	if element, ok := cache.entries[key]; ok {
+		element.Value = entry
+		cache.recency.MoveToFront(element)
+		return
+	}
+
+	cache.entries[key] = cache.recency.PushFront(entry)
+	if len(cache.entries) > cache.maxEntries {
+		oldest := cache.recency.Remove(cache.recency.Back()).(*cacheEntry)
+		delete(cache.entries, oldest.key)
+	}
+}
+
+// cachedFetch returns the value of `fetch` through the given cache
+func cachedFetch[T any](cache *responseCache, key cacheKey, fetch func() (T, error)) (T, error) {
+	value, err := cache.get(key, func() (interface{}, error) {
+		return fetch()
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return value.(T), nil
+}
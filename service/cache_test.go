@@ -0,0 +1,205 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClock struct {
+	mutex sync.Mutex
+	time  time.Time
+}
+
+func (clock *mockClock) now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.time
+}
+
+func (clock *mockClock) advance(d time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.time = clock.time.Add(d)
+}
+
+func newMockCache(ttl time.Duration, staleTTL time.Duration) (*responseCache, *mockClock) {
+	clock := &mockClock{time: time.Unix(0, 0)}
+	cache := newResponseCache(ttl, staleTTL)
+	cache.now = clock.now
+	return cache, clock
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	t.Parallel()
+
+	cache := newResponseCache(0, time.Minute)
+	assert.Nil(t, cache)
+
+	var calls int32
+	for i := 0; i < 3; i++ {
+		value, err := cachedFetch(cache, cacheKey{}, func() (int, error) {
+			return int(atomic.AddInt32(&calls, 1)), nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, value)
+	}
+}
+
+func TestResponseCacheFreshEntry(t *testing.T) {
+	t.Parallel()
+
+	cache, clock := newMockCache(time.Minute, 0)
+	key := cacheKey{provider: "github", method: "stars", owner: "owner", repo: "repo"}
+
+	var calls int32
+	fetch := func() (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}
+
+	value, err := cachedFetch(cache, key, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	clock.advance(30 * time.Second)
+	value, err = cachedFetch(cache, key, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+
+	// entries are keyed by state
+	value, err = cachedFetch(cache, cacheKey{provider: "github", method: "stars", owner: "owner", repo: "repo", state: "open"}, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, value)
+
+	// expired entries are refetched
+	clock.advance(time.Minute)
+	value, err = cachedFetch(cache, key, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestResponseCacheStaleEntry(t *testing.T) {
+	t.Parallel()
+
+	cache, clock := newMockCache(time.Minute, time.Hour)
+	key := cacheKey{provider: "gitlab", method: "forks", owner: "owner", repo: "repo"}
+
+	var calls int32
+	refreshed := make(chan struct{}, 1)
+	fetch := func() (int, error) {
+		defer func() { refreshed <- struct{}{} }()
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}
+
+	value, err := cachedFetch(cache, key, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	<-refreshed
+
+	// stale entries are served while being revalidated in the background
+	clock.advance(2 * time.Minute)
+	value, err = cachedFetch(cache, key, fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	<-refreshed
+
+	assert.Eventually(t, func() bool {
+		value, err := cachedFetch(cache, key, fetch)
+		return err == nil && value == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestResponseCacheErrorsAreNotCached(t *testing.T) {
+	t.Parallel()
+
+	cache, _ := newMockCache(time.Minute, 0)
+	key := cacheKey{provider: "bitbucket", method: "issues", owner: "owner", repo: "repo"}
+
+	_, err := cachedFetch(cache, key, func() (int, error) {
+		return 0, fmt.Errorf("upstream error")
+	})
+	assert.Error(t, err)
+
+	value, err := cachedFetch(cache, key, func() (int, error) {
+		return 42, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	cache, _ := newMockCache(time.Hour, 0)
+	cache.maxEntries = 2
+	key := func(repo string) cacheKey {
+		return cacheKey{provider: "gitlab", method: "stars", owner: "owner", repo: repo}
+	}
+
+	var calls int32
+	fetch := func() (int, error) {
+		return int(atomic.AddInt32(&calls, 1)), nil
+	}
+	for _, repo := range []string{"first", "second", "first", "third"} {
+		_, err := cachedFetch(cache, key(repo), fetch)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, cache.entries, 2)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// the least recently used entry is evicted, even if it hasn't expired yet
+	value, err := cachedFetch(cache, key("first"), fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, value)
+	value, err = cachedFetch(cache, key("second"), fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, value)
+	assert.Len(t, cache.entries, 2)
+}
+
+func TestResponseCacheCoalescesConcurrentMisses(t *testing.T) {
+	t.Parallel()
+
+	cache, _ := newMockCache(time.Minute, 0)
+	key := cacheKey{provider: "github", method: "forks", owner: "owner", repo: "repo"}
+
+	var calls int32
+	release := make(chan struct{})
+	fetch := func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 7, nil
+	}
+
+	const numRequests = 10
+	var wg sync.WaitGroup
+	results := make(chan int, numRequests)
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cachedFetch(cache, key, fetch)
+			assert.NoError(t, err)
+			results <- value
+		}()
+	}
+
+	// wait for the upstream call to start before releasing it
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&calls) > 0
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for value := range results {
+		assert.Equal(t, 7, value)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -5,69 +5,380 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	"go.uber.org/zap/zapcore"
 )
 
+const (
+	// DefaultGithubBaseURL is the base URL of the public GitHub REST API
+	DefaultGithubBaseURL = "https://api.github.com"
+	// DefaultGitlabBaseURL is the base URL of the public GitLab REST API
+	DefaultGitlabBaseURL = "https://gitlab.com/api/v4"
+)
+
+// instanceNamePattern matches valid names of git provider instances
+var instanceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
 const (
 	portCfg                       = "port"
+	logLevelCfg                   = "log-level"
 	readTimeoutCfg                = "read-timeout"
 	writeTimeoutCfg               = "write-timeout"
 	excludeCacheControlHeadersCfg = "exclude-cache-control-headers"
 	rootRedirectURLCfg            = "root-redirect-url"
 	githubAccessTokenCfg          = "github-access-token"
+	githubBatchWindowCfg          = "github-batch-window"
+	githubAppIDCfg                = "github-app-id"
+	githubAppInstallationIDCfg    = "github-app-installation-id"
+	githubAppPrivateKeyFileCfg    = "github-app-private-key-file"
+	bitbucketCacheTTLCfg          = "bitbucket-cache-ttl"
+	githubCacheTTLCfg             = "github-cache-ttl"
+	gitlabCacheTTLCfg             = "gitlab-cache-ttl"
+	cacheStaleTTLCfg              = "cache-stale-ttl"
+	endpointAllowedHostsCfg       = "endpoint-allowed-hosts"
+	githubBaseURLCfg              = "github-base-url"
+	githubInstancesCfg            = "github-instances"
+	githubInstanceTokensCfg       = "github-instance-tokens"
+	gitlabBaseURLCfg              = "gitlab-base-url"
+	gitlabInstancesCfg            = "gitlab-instances"
+	gitlabInstanceTokensCfg       = "gitlab-instance-tokens"
+	gitlabAccessTokenCfg          = "gitlab-access-token"
+	bitbucketUsernameCfg          = "bitbucket-username"
+	bitbucketAppPasswordCfg       = "bitbucket-app-password"
+	bitbucketAccessTokenCfg       = "bitbucket-access-token"
+	coverageDirCfg                = "coverage-dir"
+	coverageUploadTokenCfg        = "coverage-upload-token"
+	readinessProbesCfg            = "readiness-probes"
+	shutdownDelayCfg              = "shutdown-delay"
+	templateDirCfg                = "template-dir"
+	fontDirCfg                    = "font-dir"
+	iconDirCfg                    = "icon-dir"
 )
 
 var (
 	port                       *uint
+	logLevel                   *string
 	readTimeout                *uint
 	writeTimeout               *uint
 	excludeCacheControlHeaders *bool
 	rootRedirectURL            *string
 	githubAccessToken          *string
+	githubBatchWindow          *uint
+	githubAppID                *string
+	githubAppInstallationID    *string
+	githubAppPrivateKeyFile    *string
+	bitbucketCacheTTL          *uint
+	githubCacheTTL             *uint
+	gitlabCacheTTL             *uint
+	cacheStaleTTL              *uint
+	endpointAllowedHosts       *string
+	githubBaseURL              *string
+	githubInstances            *string
+	githubInstanceTokens       *string
+	gitlabBaseURL              *string
+	gitlabInstances            *string
+	gitlabInstanceTokens       *string
+	gitlabAccessToken          *string
+	bitbucketUsername          *string
+	bitbucketAppPassword       *string
+	bitbucketAccessToken       *string
+	coverageDir                *string
+	coverageUploadToken        *string
+	readinessProbes            *bool
+	shutdownDelay              *uint
+	templateDir                *string
+	fontDir                    *string
+	iconDir                    *string
 )
 
+// GitProviderInstance contains the configuration of a named (eg. self-hosted) git provider instance
+type GitProviderInstance struct {
+	Name        string
+	BaseURL     string
+	AccessToken string
+}
+
+// String formats the instance like the instance flags ("<NAME>=<BASE_URL>"), omitting its access token
+func (instance GitProviderInstance) String() string {
+	return instance.Name + "=" + instance.BaseURL
+}
+
 // Config contains all application configuration
 type Config struct {
 	Port                       uint
+	LogLevel                   string
 	ReadTimeout                time.Duration
 	WriteTimeout               time.Duration
 	ExcludeCacheControlHeaders bool
 	RootRedirectURL            string
-	GithubAccessToken          string
+	GithubAccessTokens         []string
+	GithubBatchWindow          time.Duration
+	GithubAppID                string
+	GithubAppInstallationID    string
+	GithubAppPrivateKeyFile    string
+	BitbucketCacheTTL          time.Duration
+	GithubCacheTTL             time.Duration
+	GitlabCacheTTL             time.Duration
+	CacheStaleTTL              time.Duration
+	EndpointAllowedHosts       []string
+	GithubBaseURL              string
+	GithubInstances            []GitProviderInstance
+	GitlabBaseURL              string
+	GitlabInstances            []GitProviderInstance
+	GitlabAccessToken          string
+	BitbucketUsername          string
+	BitbucketAppPassword       string
+	BitbucketAccessToken       string
+	CoverageDir                string
+	CoverageUploadToken        string
+	ReadinessProbes            bool
+	ShutdownDelay              time.Duration
+	TemplateDir                string
+	FontDir                    string
+	IconDir                    string
 }
 
 // Flags adds flags related to the application to the given flagset.
 func Flags(flags *flag.FlagSet) {
+	existingFlags := make(map[string]bool)
+	flags.VisitAll(func(f *flag.Flag) { existingFlags[f.Name] = true })
+
+	configFile = flags.String(configFileCfg, "", "Path of a YAML or TOML configuration file, overridden by \"AEGIS_\"-prefixed environment variables & command-line flags.")
+
 	// server configs
 	port = flags.Uint(portCfg, 8080, "Port exposing badge service.")
+	logLevel = flags.String(logLevelCfg, "INFO", "Output level of logs (DEBUG, INFO, WARN, ERROR, DPANIC, PANIC, FATAL)")
 	readTimeout = flags.Uint(readTimeoutCfg, 2000, "Maximum duration in milliseconds for reading the entire request, including the body.")
 	writeTimeout = flags.Uint(writeTimeoutCfg, 2000, "Maximum duration in milliseconds before timing out writes of the response.")
 	excludeCacheControlHeaders = flags.Bool(excludeCacheControlHeadersCfg, false, "Flag to exclude HTTP Cache-Control headers from responses.")
 	rootRedirectURL = flags.String(rootRedirectURLCfg, os.Getenv("ROOT_REDIRECT_URL"), "URL to redirect for all root path requests.")
+	readinessProbes = flags.Bool(readinessProbesCfg, false, "Flag to probe upstream providers (eg. GitHub, GitLab) in readiness checks.")
+	shutdownDelay = flags.Uint(shutdownDelayCfg, 5000, "Duration in milliseconds to keep serving requests after readiness checks start failing on shutdown.")
+	templateDir = flags.String(templateDirCfg, "", "Directory of SVG templates (\"<DIR>/<STYLE>.tmpl\") registered as additional badge styles.")
+	fontDir = flags.String(fontDirCfg, "", "Directory of TrueType fonts (\"<DIR>/<FAMILY>.ttf\" & optionally \"<DIR>/<FAMILY>-Bold.ttf\") registered as additional font families.")
+	iconDir = flags.String(iconDirCfg, "", "Directory of SVG icons (\"<DIR>/<NAME>.svg\") registered as \"custom/<NAME>\" icons.")
 
 	// service configs
-	githubAccessToken = flags.String(githubAccessTokenCfg, os.Getenv("GITHUB_ACCESS_TOKEN"), "GitHub Access Token for GitHub badge service.")
+	githubAccessToken = flags.String(githubAccessTokenCfg, os.Getenv("GITHUB_ACCESS_TOKEN"), "GitHub Access Token for GitHub badge service, or a comma-separated list of tokens to rotate between based on their remaining rate limits.")
+	githubAppID = flags.String(githubAppIDCfg, os.Getenv("GITHUB_APP_ID"), "ID of the GitHub App used to authenticate the GitHub badge service, in addition to any access tokens.")
+	githubAppInstallationID = flags.String(githubAppInstallationIDCfg, os.Getenv("GITHUB_APP_INSTALLATION_ID"), "ID of the GitHub App installation used to authenticate the GitHub badge service.")
+	githubAppPrivateKeyFile = flags.String(githubAppPrivateKeyFileCfg, os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"), "Path of the PEM-encoded private key of the GitHub App.")
+	gitlabAccessToken = flags.String(gitlabAccessTokenCfg, os.Getenv("GITLAB_ACCESS_TOKEN"), "GitLab personal/project access token for GitLab badge service (required for private projects).")
+	bitbucketUsername = flags.String(bitbucketUsernameCfg, os.Getenv("BITBUCKET_USERNAME"), "Bitbucket username used with the Bitbucket app password.")
+	bitbucketAppPassword = flags.String(bitbucketAppPasswordCfg, os.Getenv("BITBUCKET_APP_PASSWORD"), "Bitbucket app password for Bitbucket badge service (required for private repositories).")
+	bitbucketAccessToken = flags.String(bitbucketAccessTokenCfg, os.Getenv("BITBUCKET_ACCESS_TOKEN"), "Bitbucket OAuth access token for Bitbucket badge service, alternative to the Bitbucket app password.")
+	githubBaseURL = flags.String(githubBaseURLCfg, DefaultGithubBaseURL, "Base URL of the GitHub REST API (eg. \"https://github.example.com/api/v3\" for GitHub Enterprise Server).")
+	githubInstances = flags.String(githubInstancesCfg, "", "Comma-separated list of named GitHub instances served at \"/github/<NAME>/...\" (eg. \"corp=https://github.example.com/api/v3\").")
+	githubInstanceTokens = flags.String(githubInstanceTokensCfg, "", "Comma-separated list of access tokens for named GitHub instances (eg. \"corp=<TOKEN>\").")
+	gitlabBaseURL = flags.String(gitlabBaseURLCfg, DefaultGitlabBaseURL, "Base URL of the GitLab REST API (eg. \"https://gitlab.example.com/api/v4\" for self-managed GitLab).")
+	gitlabInstances = flags.String(gitlabInstancesCfg, "", "Comma-separated list of named GitLab instances served at \"/gitlab/<NAME>/...\" (eg. \"corp=https://gitlab.example.com/api/v4\").")
+	gitlabInstanceTokens = flags.String(gitlabInstanceTokensCfg, "", "Comma-separated list of access tokens for named GitLab instances (eg. \"corp=<TOKEN>\").")
+	coverageDir = flags.String(coverageDirCfg, os.Getenv("COVERAGE_DIR"), "Directory of coverage reports served at \"/coverage/<PROJECT>/<BRANCH>\", stored as \"<DIR>/<PROJECT>/<BRANCH>\".")
+	coverageUploadToken = flags.String(coverageUploadTokenCfg, os.Getenv("COVERAGE_UPLOAD_TOKEN"), "Bearer token required to upload coverage reports into the coverage directory (uploads are disabled if empty).")
+	endpointAllowedHosts = flags.String(endpointAllowedHostsCfg, "", "Comma-separated list of hosts the endpoint badge service is allowed to fetch from (\"*\" allows all public hosts, no hosts are allowed if empty).")
+
+	// cache configs
+	bitbucketCacheTTL = flags.Uint(bitbucketCacheTTLCfg, 300000, "Duration in milliseconds to cache responses from Bitbucket (0 disables caching).")
+	githubCacheTTL = flags.Uint(githubCacheTTLCfg, 300000, "Duration in milliseconds to cache responses from GitHub (0 disables caching).")
+	gitlabCacheTTL = flags.Uint(gitlabCacheTTLCfg, 300000, "Duration in milliseconds to cache responses from GitLab (0 disables caching).")
+	githubBatchWindow = flags.Uint(githubBatchWindowCfg, 10, "Duration in milliseconds to collect concurrent GitHub count requests into a single batched GraphQL query.")
+	cacheStaleTTL = flags.Uint(cacheStaleTTLCfg, 3600000, "Duration in milliseconds to keep serving expired cached responses while they are refreshed in the background.")
+
+	names := make(map[string]bool)
+	flags.VisitAll(func(f *flag.Flag) {
+		if !existingFlags[f.Name] {
+			names[f.Name] = true
+		}
+	})
+	trackFlags(flags, names)
 }
 
 // New returns an instance of all application configuration
 func New() (*Config, error) {
-	if port == nil || readTimeout == nil || writeTimeout == nil ||
-		excludeCacheControlHeaders == nil || githubAccessToken == nil {
+	// all configuration flags are added to the flag set at once by `Flags`, so an empty flag set means none is set
+	if len(configFlags) == 0 {
 		return nil, fmt.Errorf("configuration flags are not set")
 	}
+	if err := load(); err != nil {
+		return nil, err
+	}
 
-	if *rootRedirectURL != "" {
-		if _, err := url.ParseRequestURI(*rootRedirectURL); err != nil {
-			return nil, fmt.Errorf("Config.RootRedirectURL URL is invalid: %s", *rootRedirectURL)
+	githubInstanceList, err := parseInstances(*githubInstances, *githubInstanceTokens)
+	if err != nil {
+		return nil, fmt.Errorf("Config.GithubInstances is invalid: %v", err)
+	}
+	gitlabInstanceList, err := parseInstances(*gitlabInstances, *gitlabInstanceTokens)
+	if err != nil {
+		return nil, fmt.Errorf("Config.GitlabInstances is invalid: %v", err)
+	}
+
+	var githubAccessTokenList []string
+	for _, token := range strings.Split(*githubAccessToken, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			githubAccessTokenList = append(githubAccessTokenList, token)
+		}
+	}
+
+	var allowedHosts []string
+	for _, host := range strings.Split(*endpointAllowedHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			allowedHosts = append(allowedHosts, strings.ToLower(host))
 		}
 	}
 
-	return &Config{
+	configuration := &Config{
 		Port:                       *port,
+		LogLevel:                   *logLevel,
 		ReadTimeout:                time.Duration(*readTimeout) * time.Millisecond,
 		WriteTimeout:               time.Duration(*writeTimeout) * time.Millisecond,
 		ExcludeCacheControlHeaders: *excludeCacheControlHeaders,
 		RootRedirectURL:            *rootRedirectURL,
-		GithubAccessToken:          *githubAccessToken,
-	}, nil
+		GithubAccessTokens:         githubAccessTokenList,
+		GithubBatchWindow:          time.Duration(*githubBatchWindow) * time.Millisecond,
+		GithubAppID:                *githubAppID,
+		GithubAppInstallationID:    *githubAppInstallationID,
+		GithubAppPrivateKeyFile:    *githubAppPrivateKeyFile,
+		BitbucketCacheTTL:          time.Duration(*bitbucketCacheTTL) * time.Millisecond,
+		GithubCacheTTL:             time.Duration(*githubCacheTTL) * time.Millisecond,
+		GitlabCacheTTL:             time.Duration(*gitlabCacheTTL) * time.Millisecond,
+		CacheStaleTTL:              time.Duration(*cacheStaleTTL) * time.Millisecond,
+		EndpointAllowedHosts:       allowedHosts,
+		GithubBaseURL:              *githubBaseURL,
+		GithubInstances:            githubInstanceList,
+		GitlabBaseURL:              *gitlabBaseURL,
+		GitlabInstances:            gitlabInstanceList,
+		GitlabAccessToken:          *gitlabAccessToken,
+		BitbucketUsername:          *bitbucketUsername,
+		BitbucketAppPassword:       *bitbucketAppPassword,
+		BitbucketAccessToken:       *bitbucketAccessToken,
+		CoverageDir:                *coverageDir,
+		CoverageUploadToken:        *coverageUploadToken,
+		ReadinessProbes:            *readinessProbes,
+		ShutdownDelay:              time.Duration(*shutdownDelay) * time.Millisecond,
+		TemplateDir:                *templateDir,
+		FontDir:                    *fontDir,
+		IconDir:                    *iconDir,
+	}
+	if err := configuration.Validate(); err != nil {
+		return nil, err
+	}
+
+	return configuration, nil
+}
+
+// Validate checks that the configuration is valid
+func (configuration *Config) Validate() error {
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(configuration.LogLevel)); err != nil {
+		return fmt.Errorf("Config.LogLevel is invalid: %s", configuration.LogLevel)
+	}
+
+	if configuration.RootRedirectURL != "" {
+		if _, err := url.ParseRequestURI(configuration.RootRedirectURL); err != nil {
+			return fmt.Errorf("Config.RootRedirectURL URL is invalid: %s", configuration.RootRedirectURL)
+		}
+	}
+
+	// base URLs default to the public APIs (see the flag defaults), but configurations created without `New` may leave
+	// them empty, in which case the services fall back to the public APIs as well
+	if configuration.GithubBaseURL != "" {
+		if _, err := url.ParseRequestURI(configuration.GithubBaseURL); err != nil {
+			return fmt.Errorf("Config.GithubBaseURL URL is invalid: %s", configuration.GithubBaseURL)
+		}
+	}
+	if configuration.GitlabBaseURL != "" {
+		if _, err := url.ParseRequestURI(configuration.GitlabBaseURL); err != nil {
+			return fmt.Errorf("Config.GitlabBaseURL URL is invalid: %s", configuration.GitlabBaseURL)
+		}
+	}
+	for _, instances := range [][]GitProviderInstance{configuration.GithubInstances, configuration.GitlabInstances} {
+		for _, instance := range instances {
+			if _, err := url.ParseRequestURI(instance.BaseURL); err != nil {
+				return fmt.Errorf("base URL of instance %s is invalid: %s", instance.Name, instance.BaseURL)
+			}
+		}
+	}
+
+	if (configuration.GithubAppID == "") != (configuration.GithubAppInstallationID == "") ||
+		(configuration.GithubAppID == "") != (configuration.GithubAppPrivateKeyFile == "") {
+		return fmt.Errorf("Config.GithubAppID, Config.GithubAppInstallationID & Config.GithubAppPrivateKeyFile must be set together")
+	}
+	if configuration.GithubAppInstallationID != "" {
+		if _, err := strconv.ParseUint(configuration.GithubAppInstallationID, 10, 64); err != nil {
+			return fmt.Errorf("Config.GithubAppInstallationID is invalid: %s", configuration.GithubAppInstallationID)
+		}
+	}
+
+	if (configuration.BitbucketUsername == "") != (configuration.BitbucketAppPassword == "") {
+		return fmt.Errorf("Config.BitbucketUsername & Config.BitbucketAppPassword must be set together")
+	}
+	if configuration.BitbucketAppPassword != "" && configuration.BitbucketAccessToken != "" {
+		return fmt.Errorf("Config.BitbucketAppPassword & Config.BitbucketAccessToken cannot be set together")
+	}
+
+	if configuration.CoverageUploadToken != "" && configuration.CoverageDir == "" {
+		return fmt.Errorf("Config.CoverageUploadToken requires Config.CoverageDir to be set")
+	}
+
+	return nil
+}
+
+// parseKeyValues parses a comma-separated list of "key=value" pairs, preserving their order
+func parseKeyValues(str string) ([][2]string, error) {
+	var result [][2]string
+	for _, pair := range strings.Split(str, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected \"<NAME>=<VALUE>\": %s", pair)
+		}
+		result = append(result, [2]string{strings.TrimSpace(key), strings.TrimSpace(value)})
+	}
+
+	return result, nil
+}
+
+// parseInstances parses named git provider instances & their access tokens
+func parseInstances(instancesStr string, tokensStr string) ([]GitProviderInstance, error) {
+	instancePairs, err := parseKeyValues(instancesStr)
+	if err != nil {
+		return nil, err
+	}
+	tokenPairs, err := parseKeyValues(tokensStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []GitProviderInstance
+	instanceIndices := make(map[string]int)
+	for _, pair := range instancePairs {
+		name, baseURL := pair[0], pair[1]
+		if !instanceNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid instance name: %s", name)
+		}
+		if _, ok := instanceIndices[name]; ok {
+			return nil, fmt.Errorf("duplicate instance name: %s", name)
+		}
+		if _, err := url.ParseRequestURI(baseURL); err != nil {
+			return nil, fmt.Errorf("invalid base URL for instance %s: %s", name, baseURL)
+		}
+		instanceIndices[name] = len(instances)
+		instances = append(instances, GitProviderInstance{Name: name, BaseURL: baseURL})
+	}
+	for _, pair := range tokenPairs {
+		name, token := pair[0], pair[1]
+		index, ok := instanceIndices[name]
+		if !ok {
+			return nil, fmt.Errorf("access token for unknown instance: %s", name)
+		}
+		instances[index].AccessToken = token
+	}
+
+	return instances, nil
 }
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// secretFields lists the fields of the configuration holding credentials (see `secretFlags`), whose values are omitted
+// from diffs
+var secretFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	for _, field := range secretFlags {
+		if field != "" {
+			fields[field] = true
+		}
+	}
+	return fields
+}()
+
+// Diff describes the settings that differ between two configurations, omitting the values of secrets
+func Diff(previous *Config, next *Config) []string {
+	previousValue, nextValue := reflect.ValueOf(*previous), reflect.ValueOf(*next)
+
+	var changes []string
+	for i := 0; i < previousValue.NumField(); i++ {
+		name := previousValue.Type().Field(i).Name
+		previousField, nextField := previousValue.Field(i).Interface(), nextValue.Field(i).Interface()
+		if reflect.DeepEqual(previousField, nextField) {
+			continue
+		}
+		// values formatted alike are reported without values, eg. instances only differing in their access tokens
+		// (which are formatted without them, see `GitProviderInstance.String`)
+		if fmt.Sprint(previousField) == fmt.Sprint(nextField) || secretFields[name] {
+			changes = append(changes, fmt.Sprintf("%s changed", name))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, previousField, nextField))
+	}
+
+	return changes
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	previous := &Config{
+		GithubCacheTTL:     5 * time.Minute,
+		GithubAccessTokens: []string{"token1"},
+		GithubInstances:    []GitProviderInstance{{Name: "corp", BaseURL: "https://a.example.com", AccessToken: "token1"}},
+		GitlabInstances:    []GitProviderInstance{{Name: "corp", BaseURL: "https://a.example.com", AccessToken: "token1"}},
+		RootRedirectURL:    "https://a.example.com",
+	}
+	next := &Config{
+		GithubCacheTTL:     time.Minute,
+		GithubAccessTokens: []string{"token2"},
+		GithubInstances: []GitProviderInstance{
+			{Name: "corp", BaseURL: "https://b.example.com", AccessToken: "token2"},
+			{Name: "oss", BaseURL: "https://c.example.com"},
+		},
+		GitlabInstances: []GitProviderInstance{{Name: "corp", BaseURL: "https://a.example.com", AccessToken: "token2"}},
+		RootRedirectURL: "https://a.example.com",
+	}
+
+	assert.Empty(t, Diff(previous, previous))
+	assert.Equal(t, []string{
+		"GithubAccessTokens changed",
+		"GithubCacheTTL: 5m0s -> 1m0s",
+		"GithubInstances: [corp=https://a.example.com] -> [corp=https://b.example.com oss=https://c.example.com]",
+		"GitlabInstances changed",
+	}, Diff(previous, next))
+}
+
+func TestSecretFields(t *testing.T) {
+	t.Parallel()
+
+	configType := reflect.TypeOf(Config{})
+	for name := range secretFields {
+		_, ok := configType.FieldByName(name)
+		assert.True(t, ok, "unknown secret field: %s", name)
+	}
+	assert.Len(t, secretFields, 5)
+}
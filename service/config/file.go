@@ -0,0 +1,262 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileCfg = "config"
+
+// envPrefix is the prefix of environment variables overriding configuration flags (eg. "AEGIS_PORT")
+const envPrefix = "AEGIS_"
+
+// redactedValue replaces secrets when printing the configuration
+const redactedValue = "<redacted>"
+
+var (
+	configFile *string
+	// configFlags holds the flags of the application configuration
+	configFlags []*flag.Flag
+	// cliFlags records the flags set on the command line
+	cliFlags = make(map[string]bool)
+)
+
+// secretFlags maps the flags holding credentials, which are redacted when printing the configuration, onto the
+// fields of the configuration holding them (see `secretFields`). Instance tokens have no such field: they are held
+// by instances, which are formatted without them (see `GitProviderInstance.String`).
+var secretFlags = map[string]string{
+	githubAccessTokenCfg:    "GithubAccessTokens",
+	githubInstanceTokensCfg: "",
+	gitlabAccessTokenCfg:    "GitlabAccessToken",
+	gitlabInstanceTokensCfg: "",
+	bitbucketAppPasswordCfg: "BitbucketAppPassword",
+	bitbucketAccessTokenCfg: "BitbucketAccessToken",
+	coverageUploadTokenCfg:  "CoverageUploadToken",
+}
+
+// trackedValue records whether a flag is set on the command line
+type trackedValue struct {
+	flag.Value
+	name string
+}
+
+func (value *trackedValue) Set(str string) error {
+	cliFlags[value.name] = true
+	return value.Value.Set(str)
+}
+
+func (value *trackedValue) Get() interface{} {
+	if getter, ok := value.Value.(flag.Getter); ok {
+		return getter.Get()
+	}
+	return value.Value.String()
+}
+
+func (value *trackedValue) IsBoolFlag() bool {
+	boolFlag, ok := value.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// trackFlags wraps the given flags to record whether they are set on the command line
+func trackFlags(flags *flag.FlagSet, names map[string]bool) {
+	configFlags = nil
+	flags.VisitAll(func(f *flag.Flag) {
+		if !names[f.Name] {
+			return
+		}
+		f.Value = &trackedValue{Value: f.Value, name: f.Name}
+		configFlags = append(configFlags, f)
+	})
+}
+
+// envName returns the name of the environment variable overriding a flag (eg. "AEGIS_GITHUB_BASE_URL")
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// load merges the configuration file & environment variables into the flags that aren't set on the
+// command line. Settings are applied in order of precedence: command-line flags, `AEGIS_` environment
+// variables, the configuration file & finally the flag defaults.
+func load() error {
+	path := *configFile
+	if value, ok := os.LookupEnv(envName(configFileCfg)); ok && !cliFlags[configFileCfg] {
+		path = value
+	}
+	fileSettings := make(map[string]string)
+	if path != "" {
+		var err error
+		if fileSettings, err = readFile(path); err != nil {
+			return fmt.Errorf("failed to read config file %s: %v", path, err)
+		}
+	}
+
+	for _, f := range configFlags {
+		if cliFlags[f.Name] || f.Name == configFileCfg {
+			continue
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			value, ok = fileSettings[f.Name]
+		}
+		if !ok {
+			// reset settings removed from the configuration file since it was last loaded
+			value = f.DefValue
+		}
+		if err := f.Value.(*trackedValue).Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %v", value, f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// readFile reads a YAML or TOML configuration file, returning its settings by flag name
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &document)
+	case ".toml":
+		err = toml.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q (expected .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, "", document); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// isFileSetting checks whether a flag can be set in the configuration file
+func isFileSetting(name string) bool {
+	for _, f := range configFlags {
+		if f.Name == name {
+			return name != configFileCfg
+		}
+	}
+	return false
+}
+
+// flatten maps the (nested) settings of a configuration file onto flag names, joining the keys of
+// nested tables with dashes (eg. `github: {base-url: ...}` sets the "github-base-url" flag)
+func flatten(settings map[string]string, key string, value interface{}) error {
+	if key != "" && isFileSetting(key) {
+		str, err := settingValue(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %v", key, err)
+		}
+		settings[key] = str
+		return nil
+	}
+
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unknown setting: %s", key)
+	}
+	for childKey, childValue := range table {
+		if key != "" {
+			childKey = key + "-" + childKey
+		}
+		if err := flatten(settings, childKey, childValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settingValue converts a setting into its flag value, joining lists into comma-separated values & tables
+// into comma-separated "key=value" pairs
+func settingValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			str, err := scalarValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, str)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(value))
+		for key, item := range value {
+			str, err := scalarValue(item)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+"="+str)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ","), nil
+	default:
+		return scalarValue(value)
+	}
+}
+
+func scalarValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string, bool, int, int64, uint64, float64:
+		return fmt.Sprint(value), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// redact replaces the secrets of a flag value, keeping the names of "name=secret" pairs
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if name, _, ok := strings.Cut(item, "="); ok {
+			items = append(items, name+"="+redactedValue)
+			continue
+		}
+		items = append(items, redactedValue)
+	}
+	return strings.Join(items, ",")
+}
+
+// Print writes the effective configuration as YAML with secrets redacted, it must be called after `New`
+func Print(w io.Writer) error {
+	settings := make(map[string]interface{})
+	for _, f := range configFlags {
+		if f.Name == configFileCfg {
+			continue
+		}
+		value := f.Value.(*trackedValue).Get()
+		if _, ok := secretFlags[f.Name]; ok {
+			value = redact(f.Value.String())
+		}
+		settings[f.Name] = value
+	}
+
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(settings); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
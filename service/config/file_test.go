@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	flags := flag.NewFlagSet("aegis", flag.ContinueOnError)
+	Flags(flags)
+
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "aegis.yaml")
+	if err := os.WriteFile(yamlFile, []byte(`
+port: 9090
+cache-stale-ttl: 60000
+github:
+  access-token: [token1, token2]
+  instances:
+    corp: https://github.example.com/api/v3
+  instance-tokens:
+    corp: corpToken
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tomlFile := filepath.Join(dir, "aegis.toml")
+	if err := os.WriteFile(tomlFile, []byte(`
+port = 9091
+endpoint-allowed-hosts = ["example.com", "example.org"]
+
+[gitlab]
+access-token = "gitlabToken"
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// command-line flags take precedence over environment variables, which take precedence over the file
+	if err := flags.Parse([]string{"--config", yamlFile, "--cache-stale-ttl", "30000"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AEGIS_PORT", "7070")
+	configuration, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint(7070), configuration.Port)
+	assert.Equal(t, 30*time.Second, configuration.CacheStaleTTL)
+	assert.Equal(t, []string{"token1", "token2"}, configuration.GithubAccessTokens)
+	assert.Equal(t, []GitProviderInstance{{
+		Name:        "corp",
+		BaseURL:     "https://github.example.com/api/v3",
+		AccessToken: "corpToken",
+	}}, configuration.GithubInstances)
+
+	var output bytes.Buffer
+	assert.NoError(t, Print(&output))
+	assert.Contains(t, output.String(), "github-access-token: <redacted>,<redacted>\n")
+	assert.Contains(t, output.String(), "github-instance-tokens: corp=<redacted>\n")
+	assert.NotContains(t, output.String(), "corpToken")
+
+	// settings removed from the configuration file are reset to their defaults when reloaded
+	os.Unsetenv("AEGIS_PORT")
+	if err := flags.Set("config", tomlFile); err != nil {
+		t.Fatal(err)
+	}
+	configuration, err = New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint(9091), configuration.Port)
+	assert.Equal(t, []string{"example.com", "example.org"}, configuration.EndpointAllowedHosts)
+	assert.Equal(t, "gitlabToken", configuration.GitlabAccessToken)
+	assert.Nil(t, configuration.GithubInstances)
+
+	for _, document := range []string{"unknown: 1\n", "port: [1, [2]]\n", "port: -1\n"} {
+		if err := os.WriteFile(yamlFile, []byte(document), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := flags.Set("config", yamlFile); err != nil {
+			t.Fatal(err)
+		}
+		_, err := New()
+		assert.Error(t, err, document)
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// maxCoverageReportSize is the maximum size in bytes of an uploaded coverage report
+const maxCoverageReportSize = 10 * 1024 * 1024
+
+type coverageService struct {
+	name   string
+	config *config.Config
+	logger *zap.Logger
+}
+
+// NewCoverageService returns a HTTP handler for the coverage badge service
+func NewCoverageService(configuration *config.Config,
+	logger *zap.Logger) (BadgeService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("missing logger dependency")
+	}
+
+	return &coverageService{
+		name:   "coverage",
+		config: configuration,
+		logger: logger,
+	}, nil
+}
+
+// reportPath returns the path of the coverage report of a project branch in the coverage directory
+func (service *coverageService) reportPath(project string, branch string) (string, error) {
+	if service.config.CoverageDir == "" {
+		return "", fmt.Errorf("coverage directory is not configured")
+	}
+
+	var segments []string
+	for _, segment := range []string{project, branch} {
+		segment, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", err
+		}
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("invalid path segment: %q", segment)
+		}
+		// escape path separators so that every segment maps onto a single path element
+		segments = append(segments, url.PathEscape(segment))
+	}
+
+	return filepath.Join(service.config.CoverageDir, segments[0], segments[1]), nil
+}
+
+// isAuthorizedUpload checks the bearer token of a coverage report upload
+func (service *coverageService) isAuthorizedUpload(r *http.Request) bool {
+	token := service.config.CoverageUploadToken
+	if token == "" {
+		return false
+	}
+	requestToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) == 1
+}
+
+// storeReport atomically writes a coverage report into the coverage directory
+func storeReport(path string, report []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(report); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// upload handles coverage report uploads, storing the report if a project & branch are given. Failed
+// uploads are answered with plain HTTP errors as they are made by CI jobs rather than embedded as badges.
+func (service *coverageService) upload(w http.ResponseWriter, r *http.Request,
+	project string, branch string) ([]byte, bool) {
+	var path string
+	if project != "" || branch != "" {
+		if !service.isAuthorizedUpload(r) {
+			service.logger.Info("Unauthorized coverage report upload",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return nil, false
+		}
+		reportPath, err := service.reportPath(project, branch)
+		if err != nil {
+			service.logger.Info("Invalid coverage report path",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return nil, false
+		}
+		path = reportPath
+	}
+
+	report, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCoverageReportSize))
+	if err != nil {
+		service.logger.Info("Failed to read coverage report",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return nil, false
+	}
+	if _, err := parseCoverageReport(report); err != nil {
+		service.logger.Info("Invalid coverage report",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		http.Error(w, "Unprocessable Entity: "+err.Error(), http.StatusUnprocessableEntity)
+		return nil, false
+	}
+
+	if path != "" {
+		if err := storeReport(path, report); err != nil {
+			service.logger.Error("Failed to store coverage report",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return nil, false
+		}
+	}
+
+	return report, true
+}
+
+func (service *coverageService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	routeVariables := routeVariables(r)
+	project := routeVariables["project"]
+	branch := routeVariables["branch"]
+
+	format, err := requestFormat(r)
+	if err != nil {
+		service.logger.Info("Unsupported format",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+		}
+		return
+	}
+
+	// Fetch data
+	var report []byte
+	if r.Method == http.MethodPost {
+		var ok bool
+		if report, ok = service.upload(w, r, project, branch); !ok {
+			return
+		}
+	} else {
+		path, err := service.reportPath(project, branch)
+		if err == nil {
+			report, err = os.ReadFile(path)
+		}
+		if err != nil {
+			service.logger.Info("Failed to read coverage report",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+			errorBadge := badRequest
+			if service.config.CoverageDir == "" || errors.Is(err, fs.ErrNotExist) {
+				errorBadge = reportNotFound
+			}
+			if err := errorBadge(w, r, service.config); err != nil {
+				service.logger.Error("Failed to create error badge",
+					zap.String("url", r.URL.RequestURI()),
+					zap.String("service", service.name),
+					zap.Error(err))
+			}
+			return
+		}
+	}
+	percentage, err := parseCoverageReport(report)
+	if err != nil {
+		service.logger.Info("Invalid coverage report",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		if err := invalidReport(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+		}
+		return
+	}
+	// Overwrite any badge texts
+	params := queryParams(r.URL.Query(), badge.Params{
+		Subject: "coverage",
+		Status:  formatCoverage(percentage),
+		Color:   coverageColor(percentage),
+	})
+
+	// Generate badge
+	generatedBadge, contentType, err := renderBadge(format, params, percentage)
+	if err != nil {
+		service.logger.Error("Failed to create badge",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if !service.config.ExcludeCacheControlHeaders {
+		if r.Method == http.MethodPost {
+			w.Header().Set("Cache-Control", "no-store")
+		} else {
+			// reports change on every CI run, cache response in browser & CDN for 5 minutes (300)
+			w.Header().Set("Cache-Control", "public, max-age=300, s-maxage=300")
+		}
+	}
+	setBadgeContentType(w, contentType)
+	_, err = w.Write(generatedBadge)
+	if err != nil {
+		service.logger.Error("Failed to write HTTP response",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// errEmptyReport is returned when a coverage report does not contain any lines or statements
+var errEmptyReport = errors.New("coverage report is empty")
+
+// coverageThresholds maps minimum coverage percentages onto badge colors, in descending order
+var coverageThresholds = []struct {
+	minimum float64
+	color   string
+}{
+	{90, "#4c1"},
+	{80, "#97ca00"},
+	{70, "#a4a61d"},
+	{60, "#dfb317"},
+	{50, "#fe7d37"},
+	{0, "#e05d44"},
+}
+
+// coverageColor returns the badge color of a coverage percentage
+func coverageColor(percentage float64) string {
+	for _, threshold := range coverageThresholds {
+		if percentage >= threshold.minimum {
+			return threshold.color
+		}
+	}
+
+	return coverageThresholds[len(coverageThresholds)-1].color
+}
+
+// formatCoverage formats a coverage percentage with up to 1 decimal place (eg. "85.3%")
+func formatCoverage(percentage float64) string {
+	return strconv.FormatFloat(math.Floor(percentage*10)/10, 'f', -1, 64) + "%"
+}
+
+// parseCoverageReport returns the line/statement coverage percentage of a Go coverprofile, Cobertura
+// XML or LCOV report, detecting the format from its contents
+func parseCoverageReport(data []byte) (float64, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseCoverprofile(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseCobertura(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("TN:")), bytes.HasPrefix(trimmed, []byte("SF:")):
+		return parseLCOV(trimmed)
+	default:
+		return 0, fmt.Errorf("unsupported coverage report format")
+	}
+}
+
+// parseCoverprofile parses a Go coverage profile (`go test -coverprofile`), merging duplicate blocks
+// of concatenated profiles
+func parseCoverprofile(data []byte) (float64, error) {
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := make(map[string]*block)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// eg. "github.com/owner/repo/file.go:10.2,12.3 2 1"
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return 0, fmt.Errorf("invalid coverprofile line %d: %s", lineNumber, line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid coverprofile line %d: %s", lineNumber, line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid coverprofile line %d: %s", lineNumber, line)
+		}
+
+		if existing, ok := blocks[fields[0]]; ok {
+			existing.covered = existing.covered || count > 0
+			continue
+		}
+		blocks[fields[0]] = &block{statements: statements, covered: count > 0}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	var total, covered int
+	for _, block := range blocks {
+		total += block.statements
+		if block.covered {
+			covered += block.statements
+		}
+	}
+
+	return coveragePercentage(covered, total)
+}
+
+// coberturaReport is the root element of a Cobertura XML report
+type coberturaReport struct {
+	XMLName      xml.Name `xml:"coverage"`
+	LineRate     string   `xml:"line-rate,attr"`
+	LinesCovered string   `xml:"lines-covered,attr"`
+	LinesValid   string   `xml:"lines-valid,attr"`
+}
+
+// parseCobertura parses a Cobertura XML report, preferring line counts over the line rate
+func parseCobertura(data []byte) (float64, error) {
+	var report coberturaReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return 0, err
+	}
+
+	if report.LinesValid != "" && report.LinesCovered != "" {
+		linesValid, err := strconv.Atoi(report.LinesValid)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lines-valid attribute: %s", report.LinesValid)
+		}
+		linesCovered, err := strconv.Atoi(report.LinesCovered)
+		if err != nil {
+			return 0, fmt.Errorf("invalid lines-covered attribute: %s", report.LinesCovered)
+		}
+		if linesValid > 0 {
+			return coveragePercentage(linesCovered, linesValid)
+		}
+	}
+
+	lineRate, err := strconv.ParseFloat(report.LineRate, 64)
+	if err != nil || lineRate < 0 || lineRate > 1 {
+		return 0, fmt.Errorf("invalid line-rate attribute: %s", report.LineRate)
+	}
+
+	return lineRate * 100, nil
+}
+
+// parseLCOV parses a LCOV tracefile, using the line totals (LF/LH) of each record if present &
+// falling back to the line entries (DA) otherwise
+func parseLCOV(data []byte) (float64, error) {
+	var total, covered int
+	var recordLines, recordHits int
+	var recordFound, recordHit int
+	hasSummary := false
+
+	endRecord := func() {
+		if hasSummary {
+			total += recordFound
+			covered += recordHit
+		} else {
+			total += recordLines
+			covered += recordHits
+		}
+		recordLines, recordHits, recordFound, recordHit, hasSummary = 0, 0, 0, 0, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "DA":
+			// eg. "DA:<LINE>,<EXECUTION_COUNT>[,<CHECKSUM>]"
+			fields := strings.Split(value, ",")
+			if len(fields) < 2 {
+				return 0, fmt.Errorf("invalid LCOV line %d: %s", lineNumber, line)
+			}
+			count, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid LCOV line %d: %s", lineNumber, line)
+			}
+			recordLines++
+			if count > 0 {
+				recordHits++
+			}
+		case "LF", "LH":
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return 0, fmt.Errorf("invalid LCOV line %d: %s", lineNumber, line)
+			}
+			hasSummary = true
+			if key == "LF" {
+				recordFound = count
+			} else {
+				recordHit = count
+			}
+		case "end_of_record":
+			endRecord()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	// tolerate a missing "end_of_record" on the last record
+	endRecord()
+
+	return coveragePercentage(covered, total)
+}
+
+func coveragePercentage(covered int, total int) (float64, error) {
+	if total <= 0 {
+		return 0, errEmptyReport
+	}
+
+	return float64(covered) / float64(total) * 100, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+const (
+	mockCoverprofile = `mode: set
+github.com/owner/repo/a.go:3.24,5.2 2 1
+github.com/owner/repo/a.go:7.24,9.2 2 0
+github.com/owner/repo/b.go:3.24,6.2 3 1
+mode: set
+github.com/owner/repo/a.go:7.24,9.2 2 1
+github.com/owner/repo/c.go:3.24,6.2 3 0
+`
+	mockCobertura = `<?xml version="1.0" ?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.5" branch-rate="0" lines-covered="853" lines-valid="1000" version="1.9" timestamp="1">
+	<packages></packages>
+</coverage>`
+	mockLCOV = `TN:
+SF:src/a.js
+DA:1,1
+DA:2,0
+LF:2
+LH:1
+end_of_record
+SF:src/b.js
+DA:1,5
+DA:2,1
+DA:3,0
+end_of_record
+`
+)
+
+func TestParseCoverageReport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		report   string
+		expected float64
+		isValid  bool
+	}{
+		{name: "Coverprofile", report: mockCoverprofile, expected: 70, isValid: true},
+		{name: "Cobertura", report: mockCobertura, expected: 85.3, isValid: true},
+		{name: "CoberturaLineRate", report: `<coverage line-rate="0.25"></coverage>`, expected: 25, isValid: true},
+		{name: "LCOV", report: mockLCOV, expected: 60, isValid: true},
+		{name: "LCOVWithoutEndOfRecord", report: "SF:a.js\nDA:1,1\nDA:2,0", expected: 50, isValid: true},
+		{name: "EmptyCoverprofile", report: "mode: atomic\n", isValid: false},
+		{name: "InvalidCoverprofile", report: "mode: set\na.go:1.1,2.2 x 1\n", isValid: false},
+		{name: "InvalidCobertura", report: `<coverage line-rate="2"></coverage>`, isValid: false},
+		{name: "InvalidLCOV", report: "SF:a.js\nDA:1\n", isValid: false},
+		{name: "UnsupportedFormat", report: `{"coverage": 50}`, isValid: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			percentage, err := parseCoverageReport([]byte(testCase.report))
+			if !testCase.isValid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, testCase.expected, percentage, 0.0001)
+		})
+	}
+}
+
+func TestCoverageColor(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "#4c1", coverageColor(100))
+	assert.Equal(t, "#4c1", coverageColor(90))
+	assert.Equal(t, "#97ca00", coverageColor(89.9))
+	assert.Equal(t, "#dfb317", coverageColor(65))
+	assert.Equal(t, "#e05d44", coverageColor(0))
+	assert.Equal(t, "85.3%", formatCoverage(85.35))
+	assert.Equal(t, "100%", formatCoverage(100))
+}
+
+func newCoverageRouter(t *testing.T, configuration *config.Config) http.Handler {
+	service, err := NewCoverageService(configuration, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	router.UseEncodedPath()
+	router.Handle(`/coverage`, service).Methods("POST")
+	router.Handle(`/coverage/{project}/{branch}`, service).Methods("GET", "POST")
+	return router
+}
+
+func uploadReport(handler http.Handler, path string, token string, report string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(report))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func TestCoverageBadgeService(t *testing.T) {
+	t.Parallel()
+
+	coverageDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(coverageDir, "aegis"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(coverageDir, "aegis", "master"), []byte(mockLCOV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(coverageDir, "aegis", "broken"), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	router := newCoverageRouter(t, &config.Config{CoverageDir: coverageDir, CoverageUploadToken: "testToken"})
+
+	// Read reports from the coverage directory
+	testCases := []struct {
+		requestPath  string
+		expectedBody string
+	}{
+		{
+			requestPath:  "/coverage/aegis/master",
+			expectedBody: createBadge(&badge.Params{Subject: "coverage", Status: "60%", Color: "#dfb317"}),
+		},
+		{
+			requestPath:  "/coverage/aegis/master?format=json",
+			expectedBody: `{"subject":"coverage","status":"60%","color":"#dfb317","style":"classic","value":60}`,
+		},
+		{
+			requestPath:  "/coverage/aegis/master?color=blue&subject=tests",
+			expectedBody: createBadge(&badge.Params{Subject: "tests", Status: "60%", Color: "blue"}),
+		},
+		{
+			requestPath:  "/coverage/aegis/master.png",
+			expectedBody: createPNGBadge(&badge.Params{Subject: "coverage", Status: "60%", Color: "#dfb317"}),
+		},
+		{
+			requestPath:  "/coverage/aegis/master.png?format=svg",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no report"}),
+		},
+		{
+			requestPath:  "/coverage/aegis.png/master",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no report"}),
+		},
+		{
+			requestPath: "/coverage/aegis/master?link=https%3A%2F%2Fexample.com",
+			expectedBody: createBadge(&badge.Params{Subject: "coverage", Status: "60%", Color: "#dfb317",
+				Link: "https://example.com"}),
+		},
+		{
+			requestPath: "/coverage/aegis/master?font-size=13&font-family=Verdana",
+			expectedBody: createBadge(&badge.Params{Subject: "coverage", Status: "60%", Color: "#dfb317",
+				FontFamily: "Verdana", FontSize: 13}),
+		},
+		{
+			requestPath:  "/coverage/aegis/develop",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no report"}),
+		},
+		{
+			requestPath:  "/coverage/aegis/broken",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "invalid report"}),
+		},
+		{
+			requestPath:  "/coverage/aegis/%2E%2E",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "bad request"}),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.requestPath, func(t *testing.T) {
+			res := serveHTTPRequest(t, router, testCase.requestPath)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+
+	// Upload reports into the coverage directory
+	res := uploadReport(router, "/coverage/aegis/feature%2Fx", "testToken", mockCoverprofile)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+	assert.Equal(t, createBadge(&badge.Params{Subject: "coverage", Status: "70%", Color: "#a4a61d"}), res.Body.String())
+	res = serveHTTPRequest(t, router, "/coverage/aegis/feature%2Fx")
+	assert.Equal(t, createBadge(&badge.Params{Subject: "coverage", Status: "70%", Color: "#a4a61d"}), res.Body.String())
+	_, err := os.Stat(filepath.Join(coverageDir, "aegis", "feature%2Fx"))
+	assert.NoError(t, err)
+
+	res = uploadReport(router, "/coverage/aegis/master", "badToken", mockCoverprofile)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	res = uploadReport(router, "/coverage/aegis/master", "testToken", "garbage")
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	res = serveHTTPRequest(t, router, "/coverage/aegis/master")
+	assert.Equal(t, createBadge(&badge.Params{Subject: "coverage", Status: "60%", Color: "#dfb317"}), res.Body.String())
+
+	// Render uploaded reports without storing them
+	res = uploadReport(router, "/coverage", "", mockCobertura)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, createBadge(&badge.Params{Subject: "coverage", Status: "85.3%", Color: "#97ca00"}), res.Body.String())
+}
+
+func TestCoverageBadgeServiceWithoutDirectory(t *testing.T) {
+	t.Parallel()
+
+	router := newCoverageRouter(t, &config.Config{})
+
+	res := serveHTTPRequest(t, router, "/coverage/aegis/master")
+	assert.Equal(t, createBadge(&badge.Params{Subject: "aegis", Status: "no report"}), res.Body.String())
+	res = uploadReport(router, "/coverage/aegis/master", "", mockCoverprofile)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
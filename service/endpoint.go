@@ -0,0 +1,351 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// maxEndpointResponseSize is the maximum size in bytes of an endpoint JSON document
+const maxEndpointResponseSize = 64 * 1024
+
+// anyEndpointHost allows the endpoint badge service to fetch from any (public) host
+const anyEndpointHost = "*"
+
+// maxEndpointTimeout is the maximum duration of requests to endpoints, further bounded by the write timeout of the
+// server (if any) so that requests never outlive the responses waiting for them
+const maxEndpointTimeout = 10 * time.Second
+
+// sharedAddressSpace is the address space shared by carrier-grade NATs (RFC 6598), not covered by `IsPrivate`
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// endpointColors maps color names of the shields.io endpoint schema onto badge colors
+var endpointColors = map[string]string{
+	"brightgreen":   "#4c1",
+	"green":         "#97ca00",
+	"yellow":        "#dfb317",
+	"yellowgreen":   "#a4a61d",
+	"orange":        "#fe7d37",
+	"red":           "#e05d44",
+	"blue":          "#007ec6",
+	"grey":          "#555",
+	"gray":          "#555",
+	"lightgrey":     "#9f9f9f",
+	"lightgray":     "#9f9f9f",
+	"success":       "#4c1",
+	"important":     "#fe7d37",
+	"critical":      "#e05d44",
+	"informational": "#007ec6",
+	"inactive":      "#9f9f9f",
+}
+
+// endpointStyles maps styles of the shields.io endpoint schema onto badge styles
+var endpointStyles = map[string]badge.Style{
+	"flat":          badge.ClassicStyle,
+	"flat-square":   badge.FlatStyle,
+	"plastic":       badge.PlasticStyle,
+	"for-the-badge": badge.ForTheBadgeStyle,
+	"social":        badge.SocialStyle,
+}
+
+type endpointService struct {
+	name   string
+	client *http.Client
+	config *config.Config
+	logger *zap.Logger
+}
+
+// endpointResponse represents a JSON document in the shields.io endpoint schema
+type endpointResponse struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	Label         *string `json:"label"`
+	Message       *string `json:"message"`
+	Color         string  `json:"color"`
+	IsError       bool    `json:"isError"`
+	NamedLogo     string  `json:"namedLogo"`
+	LogoSvg       string  `json:"logoSvg"`
+	Style         string  `json:"style"`
+	CacheSeconds  int     `json:"cacheSeconds"`
+}
+
+// NewEndpointService returns a HTTP handler for the endpoint badge service
+func NewEndpointService(configuration *config.Config,
+	logger *zap.Logger) (BadgeService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("missing logger dependency")
+	}
+
+	timeout := maxEndpointTimeout
+	if configuration.WriteTimeout > 0 {
+		timeout = min(timeout, configuration.WriteTimeout)
+	}
+
+	return &endpointService{
+		name: "endpoint",
+		client: &http.Client{
+			Transport: newInstrumentedTransport("endpoint", newEndpointTransport()),
+			Timeout:   timeout,
+		},
+		config: configuration,
+		logger: logger,
+	}, nil
+}
+
+// newEndpointTransport returns a HTTP transport that only connects to public addresses. Addresses are checked
+// when connecting (rather than when resolving host names), so that DNS rebinding cannot bypass the check.
+func newEndpointTransport() http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkPublicAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// connections to proxies would only check the addresses of the proxies
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
+
+// checkPublicAddress rejects connections to loopback, private, shared (ie. carrier-grade NAT), link-local &
+// unspecified addresses
+func checkPublicAddress(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("non-public address is not allowed: %s", addr)
+	}
+
+	return nil
+}
+
+// parseEndpointURL validates the URL of an endpoint JSON document against the allowed hosts, no hosts are allowed
+// unless configured
+func (service *endpointService) parseEndpointURL(rawURL string) (*url.URL, error) {
+	endpointURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if endpointURL.Scheme != "http" && endpointURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme: %s", endpointURL.Scheme)
+	}
+	if endpointURL.Hostname() == "" {
+		return nil, fmt.Errorf("missing URL host")
+	}
+
+	for _, allowedHost := range service.config.EndpointAllowedHosts {
+		if allowedHost == anyEndpointHost || strings.EqualFold(endpointURL.Hostname(), allowedHost) {
+			return endpointURL, nil
+		}
+	}
+
+	return nil, fmt.Errorf("URL host is not allowed: %s", endpointURL.Hostname())
+}
+
+// fetch retrieves & validates an endpoint JSON document
+func (service *endpointService) fetch(r *http.Request, endpointURL *url.URL) (*endpointResponse, error) {
+	req, err := http.NewRequestWithContext(r.Context(), "GET", endpointURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := service.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var endpoint endpointResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxEndpointResponseSize)).Decode(&endpoint); err != nil {
+		return nil, &endpointSchemaError{err.Error()}
+	}
+	if err := endpoint.validate(); err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// endpointSchemaError represents an endpoint JSON document that does not match the schema
+type endpointSchemaError struct {
+	reason string
+}
+
+func (err *endpointSchemaError) Error() string {
+	return "invalid endpoint schema: " + err.reason
+}
+
+func (endpoint *endpointResponse) validate() error {
+	if endpoint.SchemaVersion != 1 {
+		return &endpointSchemaError{fmt.Sprintf("unsupported schemaVersion: %d", endpoint.SchemaVersion)}
+	}
+	if endpoint.Label == nil {
+		return &endpointSchemaError{"missing label"}
+	}
+	if endpoint.Message == nil {
+		return &endpointSchemaError{"missing message"}
+	}
+	if endpoint.CacheSeconds < 0 {
+		return &endpointSchemaError{fmt.Sprintf("invalid cacheSeconds: %d", endpoint.CacheSeconds)}
+	}
+
+	return nil
+}
+
+// params maps the endpoint JSON document onto badge parameters
+func (endpoint *endpointResponse) params() *badge.Params {
+	color := endpoint.Color
+	if mappedColor, ok := endpointColors[strings.ToLower(color)]; ok {
+		color = mappedColor
+	}
+	if color == "" && endpoint.IsError {
+		color = endpointColors["critical"]
+	}
+
+	return &badge.Params{
+		Style:   endpointStyles[endpoint.Style],
+		Subject: *endpoint.Label,
+		Status:  *endpoint.Message,
+		Color:   color,
+		Icon:    resolveNamedLogo(endpoint.NamedLogo),
+		Logo:    logoDataURI(endpoint.LogoSvg),
+	}
+}
+
+// resolveNamedLogo maps a shields.io logo name onto a Font Awesome icon (eg. "github" -> "brands/github")
+func resolveNamedLogo(namedLogo string) string {
+	name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(namedLogo)), " ", "-")
+	if name == "" {
+		return ""
+	}
+	if strings.Contains(name, "/") {
+		return name
+	}
+	for _, iconStyle := range []string{"brands", "solid", "regular"} {
+		if icon := iconStyle + "/" + name; badge.HasIcon(icon) {
+			return icon
+		}
+	}
+
+	return ""
+}
+
+// logoDataURI converts a SVG logo into a base64 data URI, which overrides any named logo
+func logoDataURI(logoSvg string) string {
+	if logoSvg == "" {
+		return ""
+	}
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(logoSvg))
+}
+
+func (service *endpointService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format, err := requestFormat(r)
+	if err != nil {
+		service.logger.Info("Unsupported format",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+		}
+		return
+	}
+
+	endpointURL, err := service.parseEndpointURL(r.URL.Query().Get("url"))
+	if err != nil {
+		service.logger.Info("Invalid endpoint URL",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+		}
+		return
+	}
+
+	// Fetch data
+	endpoint, err := service.fetch(r, endpointURL)
+	if err != nil {
+		service.logger.Info("Failed to fetch endpoint",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("endpoint", endpointURL.String()),
+			zap.Error(err))
+		errorBadge := inaccessible
+		var schemaErr *endpointSchemaError
+		if errors.As(err, &schemaErr) {
+			errorBadge = invalidResponse
+		}
+		if err := errorBadge(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+	// Overwrite any badge texts
+	params := queryParams(r.URL.Query(), *endpoint.params())
+
+	// Generate badge
+	generatedBadge, contentType, err := renderBadge(format, params, nil)
+	if err != nil {
+		service.logger.Error("Failed to create badge",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if !service.config.ExcludeCacheControlHeaders {
+		if endpoint.CacheSeconds > 0 {
+			w.Header().Set("Cache-Control",
+				fmt.Sprintf("public, max-age=%d, s-maxage=%d", endpoint.CacheSeconds, endpoint.CacheSeconds))
+		} else {
+			// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
+			w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
+		}
+	}
+	setBadgeContentType(w, contentType)
+	_, err = w.Write(generatedBadge)
+	if err != nil {
+		service.logger.Error("Failed to write HTTP response",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+func newMockEndpointServer(t *testing.T) *httptest.Server {
+	documents := map[string]string{
+		"/basic":         `{"schemaVersion": 1, "label": "hello", "message": "sweet world", "color": "orange"}`,
+		"/full":          `{"schemaVersion": 1, "label": "build", "message": "passing", "color": "brightgreen", "namedLogo": "GitHub", "style": "flat-square", "cacheSeconds": 300}`,
+		"/error":         `{"schemaVersion": 1, "label": "build", "message": "failing", "isError": true}`,
+		"/bad-version":   `{"schemaVersion": 2, "label": "hello", "message": "world"}`,
+		"/no-message":    `{"schemaVersion": 1, "label": "hello"}`,
+		"/wrong-type":    `{"schemaVersion": 1, "label": "hello", "message": 42}`,
+		"/not-json":      `<html></html>`,
+		"/empty-label":   `{"schemaVersion": 1, "label": "", "message": "world"}`,
+		"/unknown-logo":  `{"schemaVersion": 1, "label": "hello", "message": "world", "namedLogo": "unknown-logo"}`,
+		"/custom-colors": `{"schemaVersion": 1, "label": "hello", "message": "world", "color": "1bacbf"}`,
+		"/logo-svg":      `{"schemaVersion": 1, "label": "hello", "message": "world", "namedLogo": "github", "logoSvg": "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 16 16\"><path d=\"M0 0h16v16H0z\"/></svg>"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		document, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(document))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// newMockEndpointService returns an endpoint badge service allowed to fetch from the (loopback) mock endpoint server
+func newMockEndpointService(t *testing.T, server *httptest.Server) BadgeService {
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewEndpointService(&config.Config{
+		EndpointAllowedHosts: []string{serverURL.Hostname()},
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.(*endpointService).client = server.Client()
+
+	return service
+}
+
+func TestEndpointBadgeService(t *testing.T) {
+	t.Parallel()
+
+	server := newMockEndpointServer(t)
+	service := newMockEndpointService(t, server)
+	endpointPath := func(path string) string {
+		return "/endpoint?url=" + url.QueryEscape(server.URL+path)
+	}
+
+	testCases := []struct {
+		name            string
+		requestPath     string
+		expectedHeaders map[string]string
+		expectedBody    string
+	}{
+		{
+			name:        "Basic",
+			requestPath: endpointPath("/basic"),
+			expectedHeaders: map[string]string{
+				"Cache-Control": "public, max-age=3600, s-maxage=3600",
+				"Content-Type":  "image/svg+xml;utf-8",
+			},
+			expectedBody: createBadge(&badge.Params{
+				Subject: "hello",
+				Status:  "sweet world",
+				Color:   "#fe7d37",
+			}),
+		},
+		{
+			name:        "AllFields",
+			requestPath: endpointPath("/full"),
+			expectedHeaders: map[string]string{
+				"Cache-Control": "public, max-age=300, s-maxage=300",
+				"Content-Type":  "image/svg+xml;utf-8",
+			},
+			expectedBody: createBadge(&badge.Params{
+				Style:   badge.FlatStyle,
+				Subject: "build",
+				Status:  "passing",
+				Color:   "#4c1",
+				Icon:    "brands/github",
+			}),
+		},
+		{
+			name:        "QueryOverrides",
+			requestPath: endpointPath("/full") + "&subject=ci&color=blue&style=plastic&icon=solid/star",
+			expectedHeaders: map[string]string{
+				"Cache-Control": "public, max-age=300, s-maxage=300",
+			},
+			expectedBody: createBadge(&badge.Params{
+				Style:   badge.PlasticStyle,
+				Subject: "ci",
+				Status:  "passing",
+				Color:   "blue",
+				Icon:    "solid/star",
+			}),
+		},
+		{
+			name: "Links",
+			requestPath: endpointPath("/basic") + "&link=" + url.QueryEscape("https://example.com") +
+				"&status-link=" + url.QueryEscape("https://example.com/status"),
+			expectedBody: createBadge(&badge.Params{
+				Subject:    "hello",
+				Status:     "sweet world",
+				Color:      "#fe7d37",
+				Link:       "https://example.com",
+				StatusLink: "https://example.com/status",
+			}),
+		},
+		{
+			name:         "IsError",
+			requestPath:  endpointPath("/error"),
+			expectedBody: createBadge(&badge.Params{Subject: "build", Status: "failing", Color: "#e05d44"}),
+		},
+		{
+			name:         "EmptyLabel",
+			requestPath:  endpointPath("/empty-label"),
+			expectedBody: createBadge(&badge.Params{Status: "world"}),
+		},
+		{
+			name:         "UnknownNamedLogo",
+			requestPath:  endpointPath("/unknown-logo"),
+			expectedBody: createBadge(&badge.Params{Subject: "hello", Status: "world"}),
+		},
+		{
+			name:        "LogoSvg",
+			requestPath: endpointPath("/logo-svg"),
+			expectedBody: createBadge(&badge.Params{
+				Subject: "hello",
+				Status:  "world",
+				Logo: "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(
+					[]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><path d="M0 0h16v16H0z"/></svg>`)),
+			}),
+		},
+		{
+			name:        "LogoQueryOverride",
+			requestPath: endpointPath("/logo-svg") + "&logo=" + url.QueryEscape(testLogo),
+			expectedBody: createBadge(&badge.Params{
+				Subject: "hello",
+				Status:  "world",
+				Logo:    testLogo,
+			}),
+		},
+		{
+			name:         "HexColor",
+			requestPath:  endpointPath("/custom-colors"),
+			expectedBody: createBadge(&badge.Params{Subject: "hello", Status: "world", Color: "1bacbf"}),
+		},
+		{
+			name:        "JSONFormat",
+			requestPath: endpointPath("/basic") + "&format=json",
+			expectedHeaders: map[string]string{
+				"Content-Type": "application/json",
+			},
+			expectedBody: `{"subject":"hello","status":"sweet world","color":"#fe7d37","style":"classic"}`,
+		},
+		{
+			name:         "UnsupportedSchemaVersion",
+			requestPath:  endpointPath("/bad-version"),
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "invalid response"}),
+		},
+		{
+			name:         "MissingMessage",
+			requestPath:  endpointPath("/no-message"),
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "invalid response"}),
+		},
+		{
+			name:         "WrongFieldType",
+			requestPath:  endpointPath("/wrong-type"),
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "invalid response"}),
+		},
+		{
+			name:         "NotJSON",
+			requestPath:  endpointPath("/not-json"),
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "invalid response"}),
+		},
+		{
+			name:         "NotFound",
+			requestPath:  endpointPath("/missing"),
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "inaccessible"}),
+		},
+		{
+			name:         "MissingURL",
+			requestPath:  "/endpoint",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "bad request"}),
+		},
+		{
+			name:         "UnsupportedURLScheme",
+			requestPath:  "/endpoint?url=" + url.QueryEscape("file:///etc/passwd"),
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "bad request"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			res := serveHTTPRequest(t, service, testCase.requestPath)
+			assert.Equal(t, 200, res.Code)
+			for fieldName, fieldValue := range testCase.expectedHeaders {
+				assert.Equal(t, fieldValue, res.Header().Get(fieldName), fieldName)
+			}
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+}
+
+func TestEndpointBadgeServiceAllowedHosts(t *testing.T) {
+	t.Parallel()
+
+	service, err := NewEndpointService(&config.Config{
+		EndpointAllowedHosts: []string{"example.com"},
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := service.(*endpointService)
+
+	_, err = endpoint.parseEndpointURL("https://example.com/badge.json")
+	assert.NoError(t, err)
+	_, err = endpoint.parseEndpointURL("https://EXAMPLE.com:8443/badge.json")
+	assert.NoError(t, err)
+	_, err = endpoint.parseEndpointURL("https://evil.com/badge.json")
+	assert.Error(t, err)
+	_, err = endpoint.parseEndpointURL("https://example.com.evil.com/badge.json")
+	assert.Error(t, err)
+}
+
+func TestEndpointBadgeServiceNoAllowedHosts(t *testing.T) {
+	t.Parallel()
+
+	service, err := NewEndpointService(&config.Config{}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.(*endpointService).parseEndpointURL("https://example.com/badge.json")
+	assert.EqualError(t, err, "URL host is not allowed: example.com")
+
+	service, err = NewEndpointService(&config.Config{EndpointAllowedHosts: []string{"*"}}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.(*endpointService).parseEndpointURL("https://example.com/badge.json")
+	assert.NoError(t, err)
+}
+
+func TestEndpointBadgeServiceTimeout(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		writeTimeout time.Duration
+		expected     time.Duration
+	}{
+		{0, maxEndpointTimeout},
+		{2 * time.Second, 2 * time.Second},
+		{time.Minute, maxEndpointTimeout},
+	} {
+		service, err := NewEndpointService(&config.Config{WriteTimeout: testCase.writeTimeout}, zaptest.NewLogger(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, testCase.expected, service.(*endpointService).client.Timeout, testCase.writeTimeout)
+	}
+}
+
+func TestEndpointBadgeServicePrivateAddresses(t *testing.T) {
+	t.Parallel()
+
+	// allowed hosts resolving to private addresses (eg. by DNS rebinding) are rejected when connecting
+	server := newMockEndpointServer(t)
+	service, err := NewEndpointService(&config.Config{EndpointAllowedHosts: []string{"*"}}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := serveHTTPRequest(t, service, "/endpoint?url="+url.QueryEscape(server.URL+"/basic"))
+	assert.Equal(t, createBadge(&badge.Params{Subject: "aegis", Status: "inaccessible"}), res.Body.String())
+
+	for _, address := range []string{"127.0.0.1:80", "[::1]:80", "10.0.0.1:80", "192.168.1.1:443", "172.16.0.1:80",
+		"169.254.169.254:80", "[fe80::1]:80", "[fd00::1]:80", "[::ffff:127.0.0.1]:80", "0.0.0.0:80", "100.64.0.1:80",
+		"100.127.255.254:443", "[::ffff:100.100.100.200]:80"} {
+		assert.Error(t, checkPublicAddress("tcp", address, nil), address)
+	}
+	for _, address := range []string{"93.184.216.34:443", "[2606:2800:220:1:248:1893:25c8:1946]:443", "100.128.0.1:443"} {
+		assert.NoError(t, checkPublicAddress("tcp", address, nil), address)
+	}
+}
@@ -1,51 +1,154 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/tohjustin/aegis/pkg/badge"
 	"github.com/tohjustin/aegis/service/config"
 )
 
-func generateErrorBadge(w http.ResponseWriter,
+// upstreamStatusError represents an unsuccessful HTTP response from an upstream service
+type upstreamStatusError struct {
+	statusCode int
+}
+
+// NewUpstreamStatusError returns an error for an unsuccessful HTTP response from an upstream service,
+// rendered as an "unauthorized", "forbidden" or "repo not found" badge where applicable
+func NewUpstreamStatusError(statusCode int) error {
+	return &upstreamStatusError{statusCode: statusCode}
+}
+
+func (err *upstreamStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code from upstream: %d %s",
+		err.statusCode, http.StatusText(err.statusCode))
+}
+
+// rateLimitError represents a request to an upstream service that is rejected by (or withheld due to)
+// its exhausted rate limits
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (err *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limited by upstream, retry after %s", err.retryAfter)
+}
+
+func generateErrorBadge(w http.ResponseWriter, r *http.Request,
 	configuration *config.Config, status string) error {
-	generatedBadge, err := badge.Create(&badge.Params{
+	format, err := requestFormat(r)
+	if err != nil {
+		// fallback to SVG badges for unsupported formats
+		format = svgFormat
+	}
+	generatedBadge, contentType, err := renderBadge(format, &badge.Params{
 		Subject: "aegis",
 		Status:  status,
-	})
+	}, nil)
 	if err != nil {
 		return err
 	}
 
-	if !configuration.ExcludeCacheControlHeaders {
+	if !configuration.ExcludeCacheControlHeaders && w.Header().Get("Cache-Control") == "" {
 		// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
 		w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
 	}
-	w.Header().Set("Content-Type", "image/svg+xml;utf-8")
-	_, err = w.Write([]byte(generatedBadge))
+	setBadgeContentType(w, contentType)
+	_, err = w.Write(generatedBadge)
 	return err
 }
 
 // badRequest handles HTTP requests that are malformed
-func badRequest(w http.ResponseWriter,
+func badRequest(w http.ResponseWriter, r *http.Request,
 	configuration *config.Config) error {
-	return generateErrorBadge(w, configuration, "bad request")
+	return generateErrorBadge(w, r, configuration, "bad request")
 }
 
 // internalServerError handles HTTP requests that results in internal server error
-func internalServerError(w http.ResponseWriter,
+func internalServerError(w http.ResponseWriter, r *http.Request,
+	configuration *config.Config) error {
+	return generateErrorBadge(w, r, configuration, "internal server error")
+}
+
+// inaccessible handles HTTP requests for upstream resources that cannot be fetched
+func inaccessible(w http.ResponseWriter, r *http.Request,
+	configuration *config.Config) error {
+	return generateErrorBadge(w, r, configuration, "inaccessible")
+}
+
+// invalidResponse handles HTTP requests for upstream resources that returned an invalid response
+func invalidResponse(w http.ResponseWriter, r *http.Request,
 	configuration *config.Config) error {
-	return generateErrorBadge(w, configuration, "internal server error")
+	return generateErrorBadge(w, r, configuration, "invalid response")
+}
+
+// invalidReport handles HTTP requests for reports that cannot be parsed
+func invalidReport(w http.ResponseWriter, r *http.Request,
+	configuration *config.Config) error {
+	return generateErrorBadge(w, r, configuration, "invalid report")
+}
+
+// reportNotFound handles HTTP requests for reports that don't exist
+func reportNotFound(w http.ResponseWriter, r *http.Request,
+	configuration *config.Config) error {
+	return generateErrorBadge(w, r, configuration, "no report")
+}
+
+// rateLimited handles HTTP requests that failed as the rate limits of an upstream service are exhausted
+func rateLimited(w http.ResponseWriter, r *http.Request,
+	configuration *config.Config, retryAfter time.Duration) error {
+	seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	w.Header().Set("Retry-After", seconds)
+	if !configuration.ExcludeCacheControlHeaders {
+		// cache response until the rate limits are reset
+		w.Header().Set("Cache-Control", "public, max-age="+seconds+", s-maxage="+seconds)
+	}
+
+	return generateErrorBadge(w, r, configuration, "rate limited")
 }
 
 // notFound handles HTTP requests for methods that don't exist
-func notFound(w http.ResponseWriter,
+func notFound(w http.ResponseWriter, r *http.Request,
 	configuration *config.Config) error {
-	return generateErrorBadge(w, configuration, "not found")
+	return generateErrorBadge(w, r, configuration, "not found")
 }
 
 // serviceNotFound handles HTTP requests for services that don't exist
-func serviceNotFound(w http.ResponseWriter,
+func serviceNotFound(w http.ResponseWriter, r *http.Request,
 	configuration *config.Config) error {
-	return generateErrorBadge(w, configuration, "service not found")
+	return generateErrorBadge(w, r, configuration, "service not found")
+}
+
+// upstreamError handles HTTP requests that failed to fetch data from an upstream service
+func upstreamError(w http.ResponseWriter, r *http.Request,
+	configuration *config.Config, fetchErr error) error {
+	var rateLimitErr *rateLimitError
+	if errors.As(fetchErr, &rateLimitErr) {
+		return rateLimited(w, r, configuration, rateLimitErr.retryAfter)
+	}
+	var statusErr *upstreamStatusError
+	if errors.As(fetchErr, &statusErr) {
+		switch statusErr.statusCode {
+		case http.StatusUnauthorized:
+			return generateErrorBadge(w, r, configuration, "unauthorized")
+		case http.StatusForbidden:
+			return generateErrorBadge(w, r, configuration, "forbidden")
+		case http.StatusNotFound:
+			return generateErrorBadge(w, r, configuration, "repo not found")
+		}
+	}
+	switch {
+	case errors.Is(fetchErr, errNoReleases):
+		return generateErrorBadge(w, r, configuration, "no releases")
+	case errors.Is(fetchErr, errNoTags):
+		return generateErrorBadge(w, r, configuration, "no tags")
+	case errors.Is(fetchErr, errNoPipelines):
+		return generateErrorBadge(w, r, configuration, "no pipelines")
+	}
+
+	return internalServerError(w, r, configuration)
 }
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+// fontExt is the file extension of TrueType fonts
+const fontExt = ".ttf"
+
+// boldFontSuffix is the file name suffix of the bold weight of a font family (eg. "<DIR>/Inter-Bold.ttf")
+const boldFontSuffix = "-Bold"
+
+// loadFonts validates the TrueType fonts in the given directory as font families named after their files
+// (eg. "<DIR>/Inter.ttf" & "<DIR>/Inter-Bold.ttf" as the "Inter" font family), returning the valid fonts by family
+func loadFonts(dir string) (map[string]fontFiles, error) {
+	fonts := make(map[string]fontFiles)
+	err := registerDir(dir, "*"+fontExt, func(path string, regular []byte) error {
+		family := strings.TrimSuffix(filepath.Base(path), fontExt)
+		if strings.HasSuffix(family, boldFontSuffix) {
+			return nil
+		}
+		bold, err := os.ReadFile(filepath.Join(dir, family+boldFontSuffix+fontExt))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := badge.ValidateFont(family, regular, bold); err != nil {
+			return err
+		}
+		fonts[family] = fontFiles{regular: regular, bold: bold}
+		return nil
+	})
+
+	return fonts, err
+}
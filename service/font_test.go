@@ -0,0 +1,36 @@
+package service
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+func TestLoadFonts(t *testing.T) {
+	t.Parallel()
+
+	dir := newTestDir(t, map[string]string{
+		"Service Go.ttf":         string(goregular.TTF),
+		"Service Go-Bold.ttf":    string(gobold.TTF),
+		"Service Go Regular.ttf": string(goregular.TTF),
+		"Service Invalid.ttf":    "not a font",
+		"README.md":              "not a font",
+	})
+
+	fonts, err := loadFonts(dir)
+	assert.ErrorContains(t, err, filepath.Join(dir, "Service Invalid.ttf")+": Invalid font of font family Service Invalid")
+	assert.ElementsMatch(t, []string{"Service Go", "Service Go Regular"}, slices.Collect(maps.Keys(fonts)))
+	assert.Equal(t, gobold.TTF, fonts["Service Go"].bold)
+	assert.Nil(t, fonts["Service Go Regular"].bold)
+	assert.NotContains(t, badge.Fonts(), "Service Go")
+
+	_, err = loadFonts(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+// badgeFormat represents the output format of a badge
+type badgeFormat string
+
+// List of supported badge formats
+const (
+	svgFormat  badgeFormat = "svg"
+	pngFormat  badgeFormat = "png"
+	jsonFormat badgeFormat = "json"
+)
+
+// svgContentType is the content type of SVG badges
+const svgContentType = "image/svg+xml;utf-8"
+
+// contentSecurityPolicy only allows badges to load the images (ie. icons) & styles embedded in them
+const contentSecurityPolicy = "default-src 'none'; img-src data:; style-src 'unsafe-inline'"
+
+// pngPathSuffix is the path suffix for requesting PNG badges (eg. "/static.png")
+const pngPathSuffix = ".png"
+
+// badgeJSON is the JSON representation of a badge
+type badgeJSON struct {
+	Subject     string      `json:"subject"`
+	Status      string      `json:"status"`
+	Color       string      `json:"color"`
+	Style       badge.Style `json:"style"`
+	Icon        string      `json:"icon,omitempty"`
+	Logo        string      `json:"logo,omitempty"`
+	Link        string      `json:"link,omitempty"`
+	SubjectLink string      `json:"subjectLink,omitempty"`
+	StatusLink  string      `json:"statusLink,omitempty"`
+	Value       interface{} `json:"value,omitempty"`
+}
+
+// segmentJSON is the JSON representation of a segment of a multi-segment badge
+type segmentJSON struct {
+	Text  string `json:"text"`
+	Color string `json:"color,omitempty"`
+}
+
+// segmentedBadgeJSON is the JSON representation of a multi-segment badge
+type segmentedBadgeJSON struct {
+	Segments []segmentJSON `json:"segments"`
+	Style    badge.Style   `json:"style"`
+	Icon     string        `json:"icon,omitempty"`
+	Logo     string        `json:"logo,omitempty"`
+	Link     string        `json:"link,omitempty"`
+}
+
+// requestFormat returns the badge format requested via the `format` query parameter, path suffix or
+// `Accept` header
+func requestFormat(r *http.Request) (badgeFormat, error) {
+	switch format := badgeFormat(r.URL.Query().Get("format")); format {
+	case "":
+		if hasFormatSuffix(r) {
+			return pngFormat, nil
+		}
+		for _, mediaType := range strings.Split(r.Header.Get("Accept"), ",") {
+			if strings.TrimSpace(strings.Split(mediaType, ";")[0]) == "application/json" {
+				return jsonFormat, nil
+			}
+		}
+		return svgFormat, nil
+	case svgFormat, pngFormat, jsonFormat:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported badge format: %s", format)
+	}
+}
+
+// hasFormatSuffix reports whether the badge format of a request is selected by its path suffix, ie. the path ends
+// with the suffix & no format is requested via the `format` query parameter
+func hasFormatSuffix(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "" && strings.HasSuffix(r.URL.Path, pngPathSuffix)
+}
+
+// routeVariables returns the route variables of a request, removing the badge format suffix from the variable of
+// the last path segment if the suffix selects the badge format (eg. "repo" for "/github/stars/owner/repo.png", but
+// "repo.png" for "/github/stars/owner/repo.png?format=svg"). Other path segments are left untouched.
+func routeVariables(r *http.Request) map[string]string {
+	variables := make(map[string]string)
+	for name, value := range mux.Vars(r) {
+		variables[name] = value
+	}
+	if name := lastRouteVariable(r); name != "" && hasFormatSuffix(r) {
+		variables[name] = strings.TrimSuffix(variables[name], pngPathSuffix)
+	}
+
+	return variables
+}
+
+// lastRouteVariable returns the name of the route variable matching the last path segment of a request (eg. "repo"
+// for the `/github/{method}/{owner}/{repo}` route), if any
+func lastRouteVariable(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	pathTemplate, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	segment := pathTemplate[strings.LastIndex(pathTemplate, "/")+1:]
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return ""
+	}
+	name, _, _ := strings.Cut(segment[1:len(segment)-1], ":")
+
+	return name
+}
+
+// setBadgeContentType sets the content type of a badge response. SVG badges opened directly are documents of our
+// domain, so they are served with a Content-Security-Policy preventing them from running scripts or loading resources.
+func setBadgeContentType(w http.ResponseWriter, contentType string) {
+	w.Header().Set("Content-Type", contentType)
+	if contentType == svgContentType {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+	}
+}
+
+// queryParams returns the badge parameters of a badge service (eg. its fetched subject, status & color), overridden by
+// the non-empty badge query parameters of a request (see `README.md`)
+func queryParams(query url.Values, params badge.Params) *badge.Params {
+	overrides := map[string]*string{
+		"subject":      &params.Subject,
+		"status":       &params.Status,
+		"color":        &params.Color,
+		"icon":         &params.Icon,
+		"logo":         &params.Logo,
+		"font-family":  &params.FontFamily,
+		"link":         &params.Link,
+		"subject-link": &params.SubjectLink,
+		"status-link":  &params.StatusLink,
+	}
+	for name, value := range overrides {
+		if queryValue := query.Get(name); queryValue != "" {
+			*value = queryValue
+		}
+	}
+	if queryStyle := query.Get("style"); queryStyle != "" {
+		params.Style = badge.Style(queryStyle)
+	}
+	// Unsupported font sizes are discarded, like other unsupported badge parameters
+	if fontSize, err := strconv.Atoi(query.Get("font-size")); err == nil {
+		params.FontSize = fontSize
+	}
+
+	return &params
+}
+
+// renderBadge generates a badge in the given format & returns it with its content type. The raw
+// value fetched from the badge service (if any) is only included in JSON badges.
+func renderBadge(format badgeFormat, params *badge.Params, value interface{}) ([]byte, string, error) {
+	start := time.Now()
+	defer func() {
+		badgeRenderDuration.WithLabelValues(string(format)).Observe(time.Since(start).Seconds())
+	}()
+
+	switch format {
+	case jsonFormat:
+		resolvedParams, err := badge.Resolve(params)
+		if err != nil {
+			return nil, "", err
+		}
+		generatedBadge, err := json.Marshal(badgeJSON{
+			Subject:     resolvedParams.Subject,
+			Status:      resolvedParams.Status,
+			Color:       resolvedParams.Color,
+			Style:       resolvedParams.Style,
+			Icon:        resolvedParams.Icon,
+			Logo:        resolvedParams.Logo,
+			Link:        resolvedParams.Link,
+			SubjectLink: resolvedParams.SubjectLink,
+			StatusLink:  resolvedParams.StatusLink,
+			Value:       value,
+		})
+		return generatedBadge, "application/json", err
+	case pngFormat:
+		generatedBadge, err := badge.CreatePNG(params)
+		return generatedBadge, "image/png", err
+	default:
+		generatedBadge, err := badge.Create(params)
+		return []byte(generatedBadge), svgContentType, err
+	}
+}
+
+// renderSegmentedBadge generates a multi-segment badge in the given format & returns it with its content type
+func renderSegmentedBadge(format badgeFormat, params *badge.SegmentParams) ([]byte, string, error) {
+	start := time.Now()
+	defer func() {
+		badgeRenderDuration.WithLabelValues(string(format)).Observe(time.Since(start).Seconds())
+	}()
+
+	switch format {
+	case jsonFormat:
+		resolvedParams, err := badge.ResolveSegments(params)
+		if err != nil {
+			return nil, "", err
+		}
+		segments := make([]segmentJSON, len(resolvedParams.Segments))
+		for i, segment := range resolvedParams.Segments {
+			segments[i] = segmentJSON{Text: segment.Text, Color: segment.Color}
+		}
+		generatedBadge, err := json.Marshal(segmentedBadgeJSON{
+			Segments: segments,
+			Style:    resolvedParams.Style,
+			Icon:     resolvedParams.Icon,
+			Logo:     resolvedParams.Logo,
+			Link:     resolvedParams.Link,
+		})
+		return generatedBadge, "application/json", err
+	case pngFormat:
+		generatedBadge, err := badge.CreateSegmentsPNG(params)
+		return generatedBadge, "image/png", err
+	default:
+		generatedBadge, err := badge.CreateSegments(params)
+		return []byte(generatedBadge), svgContentType, err
+	}
+}
@@ -2,290 +2,409 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"strings"
 
-	"github.com/gorilla/mux"
 	"github.com/shurcooL/githubv4"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 
-	"github.com/tohjustin/aegis/pkg/badge"
 	"github.com/tohjustin/aegis/service/config"
 )
 
 type githubService struct {
-	name   string
-	client *githubv4.Client
-	config *config.Config
-	logger *zap.Logger
+	name       string
+	baseURL    string
+	graphqlURL string
+	cache      *responseCache
+	batcher    *githubBatcher
+	client     *githubv4.Client
+	httpClient *http.Client
+	handler    BadgeService
+	config     *config.Config
+	logger     *zap.Logger
+}
+
+// githubRateLimitField selects the GraphQL rate limit of a query, recorded by the token pool of the service (see
+// `readGraphQLRateLimit`)
+type githubRateLimitField struct {
+	Remaining int
+	ResetAt   githubv4.DateTime
+	Cost      int
+}
+
+type githubRepositoryResponse struct {
+	DefaultBranch string `json:"default_branch"`
+}
+
+type githubWorkflowRunsResponse struct {
+	WorkflowRuns []struct {
+		Name       string `json:"name"`
+		Path       string `json:"path"`
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"workflow_runs"`
 }
 
 // NewGithubService returns a HTTP handler for the Github badge service
 func NewGithubService(configuration *config.Config,
 	logger *zap.Logger) (GitProviderService, error) {
+	return newDefaultGithubService(configuration, logger, nil)
+}
+
+// NewGithubInstanceService returns a HTTP handler for the Github badge service of a named GitHub instance
+func NewGithubInstanceService(configuration *config.Config,
+	logger *zap.Logger, instance config.GitProviderInstance) (GitProviderService, error) {
+	return newGithubInstanceService(configuration, logger, nil, instance)
+}
+
+func newDefaultGithubService(configuration *config.Config,
+	logger *zap.Logger, state *serviceState) (GitProviderService, error) {
 	if configuration == nil {
 		return nil, fmt.Errorf("missing config dependency")
 	}
+
+	var tokenSources []oauth2.TokenSource
+	for _, accessToken := range configuration.GithubAccessTokens {
+		tokenSources = append(tokenSources, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}))
+	}
+	var privateKey []byte
+	if configuration.GithubAppID != "" {
+		var err error
+		privateKey, err = os.ReadFile(configuration.GithubAppPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %v", err)
+		}
+		baseURL := configuration.GithubBaseURL
+		if baseURL == "" {
+			baseURL = config.DefaultGithubBaseURL
+		}
+		appTokenSource, err := newGithubAppTokenSource(baseURL, configuration.GithubAppID,
+			configuration.GithubAppInstallationID, privateKey,
+			&http.Client{Transport: newInstrumentedTransport("github", nil)})
+		if err != nil {
+			return nil, err
+		}
+		tokenSources = append(tokenSources, appTokenSource)
+	}
+
+	credentials := fmt.Sprint(configuration.GithubAccessTokens, configuration.GithubAppID,
+		configuration.GithubAppInstallationID, string(privateKey))
+	return newGithubService(configuration, logger, state, "github", configuration.GithubBaseURL,
+		credentials, tokenSources)
+}
+
+func newGithubInstanceService(configuration *config.Config,
+	logger *zap.Logger, state *serviceState, instance config.GitProviderInstance) (GitProviderService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+
+	var tokenSources []oauth2.TokenSource
+	if instance.AccessToken != "" {
+		tokenSources = append(tokenSources, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: instance.AccessToken}))
+	}
+
+	return newGithubService(configuration, logger, state, "github/"+instance.Name, instance.BaseURL,
+		instance.AccessToken, tokenSources)
+}
+
+// newGithubService returns a HTTP handler for the Github badge service of a GitHub instance, carrying over its
+// response cache, token pool & batcher from the given service state if its upstream & `credentials` (ie. a
+// representation of the token sources) are unchanged
+func newGithubService(configuration *config.Config, logger *zap.Logger, state *serviceState,
+	name string, baseURL string, credentials string, tokenSources []oauth2.TokenSource) (GitProviderService, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("missing logger dependency")
 	}
-
-	accessToken := configuration.GithubAccessToken
-	if accessToken == "" {
+	if len(tokenSources) == 0 {
 		return nil, fmt.Errorf("missing GitHub access token")
 	}
+	if baseURL == "" {
+		baseURL = config.DefaultGithubBaseURL
+	}
+
+	// Create new Github GraphQL client, rotating requests between the access tokens
+	pool := carryOver(state, func() *githubTokenPool {
+		return newGithubTokenPool(tokenSources...)
+	}, "pool", name, baseURL, credentials)
+	httpClient := &http.Client{Transport: &githubTokenTransport{
+		pool: pool,
+		next: newInstrumentedTransport(name, nil),
+	}}
 
-	// Create new Github GraphQL client
-	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
-	httpClient := oauth2.NewClient(context.Background(), tokenSource)
+	cache := loadResponseCache(state, configuration.GithubCacheTTL, configuration.CacheStaleTTL,
+		name, baseURL, credentials)
+	service := &githubService{
+		name:       name,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		graphqlURL: githubGraphQLURL(baseURL),
+		cache:      cache,
+		client:     githubv4.NewEnterpriseClient(githubGraphQLURL(baseURL), httpClient),
+		httpClient: httpClient,
+		config:     configuration,
+		logger:     logger,
+	}
+	// a carried over batcher keeps fetching with the service that created it, which queries the same upstream with
+	// the same token pool
+	service.batcher = carryOver(state, func() *githubBatcher {
+		return newGithubBatcher(configuration.GithubBatchWindow, configuration.WriteTimeout,
+			service.fetchRepositoryCounts)
+	}, "batcher", name, baseURL, credentials, configuration.GithubBatchWindow, configuration.WriteTimeout)
+	handler, err := newProviderService(configuration, logger, name, service.methods())
+	if err != nil {
+		return nil, err
+	}
+	service.handler = handler
 
-	return &githubService{
-		name:   "github",
-		client: githubv4.NewClient(httpClient),
-		config: configuration,
-		logger: logger,
-	}, nil
+	return service, nil
 }
 
-func (service *githubService) getForkCount(owner string, repo string) (int, error) {
-	var query struct {
-		Repository struct {
-			Forks struct {
-				TotalCount int
-			}
-		} `graphql:"repository(owner: $owner, name: $repo)"`
-	}
-	variables := map[string]interface{}{
-		"owner": githubv4.String(owner),
-		"repo":  githubv4.String(repo),
+// githubGraphQLURL returns the GraphQL API endpoint for the given GitHub REST API base URL
+// (eg. "https://github.example.com/api/v3" -> "https://github.example.com/api/graphql")
+func githubGraphQLURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/api/v3") {
+		return strings.TrimSuffix(baseURL, "/v3") + "/graphql"
 	}
 
-	err := service.client.Query(context.Background(), &query, variables)
-	return query.Repository.Forks.TotalCount, err
+	return baseURL + "/graphql"
+}
+
+func (service *githubService) getForkCount(owner string, repo string) (int, error) {
+	counts, err := service.batcher.load(owner, repo)
+	return counts.Forks.TotalCount, err
 }
 
 func (service *githubService) getIssueCount(owner string, repo string, issueState string) (int, error) {
-	var issueStates []githubv4.IssueState
-	var query struct {
-		Repository struct {
-			Issues struct {
-				TotalCount int
-			} `graphql:"issues(states: $states)"`
-		} `graphql:"repository(owner: $owner, name: $repo)"`
-	}
+	counts, err := service.batcher.load(owner, repo)
 	switch issueState {
 	case "open":
-		issueStates = []githubv4.IssueState{githubv4.IssueStateOpen}
+		return counts.OpenIssues.TotalCount, err
 	case "closed":
-		issueStates = []githubv4.IssueState{githubv4.IssueStateClosed}
+		return counts.ClosedIssues.TotalCount, err
 	default:
-		issueStates = []githubv4.IssueState{
-			githubv4.IssueStateOpen,
-			githubv4.IssueStateClosed,
-		}
-	}
-	variables := map[string]interface{}{
-		"owner":  githubv4.String(owner),
-		"repo":   githubv4.String(repo),
-		"states": issueStates,
+		return counts.Issues.TotalCount, err
 	}
-
-	err := service.client.Query(context.Background(), &query, variables)
-	return query.Repository.Issues.TotalCount, err
 }
 
-func (service *githubService) getPullRequestCount(owner string, repo string, pullRequestState string) (int, error) {
-	var pullRequestStates []githubv4.PullRequestState
+func (service *githubService) getLatestRelease(owner string, repo string, includePrereleases bool) (string, error) {
 	var query struct {
 		Repository struct {
-			PullRequests struct {
-				TotalCount int
-			} `graphql:"pullRequests(states: $states)"`
+			Releases struct {
+				Nodes []struct {
+					TagName      string
+					IsDraft      bool
+					IsPrerelease bool
+				}
+			} `graphql:"releases(first: 100, orderBy: {field: CREATED_AT, direction: DESC})"`
 		} `graphql:"repository(owner: $owner, name: $repo)"`
-	}
-	switch pullRequestState {
-	case "open":
-		pullRequestStates = []githubv4.PullRequestState{githubv4.PullRequestStateOpen}
-	case "closed":
-		pullRequestStates = []githubv4.PullRequestState{githubv4.PullRequestStateClosed}
-	case "merged":
-		pullRequestStates = []githubv4.PullRequestState{githubv4.PullRequestStateMerged}
-	default:
-		pullRequestStates = []githubv4.PullRequestState{
-			githubv4.PullRequestStateOpen,
-			githubv4.PullRequestStateClosed,
-			githubv4.PullRequestStateMerged,
-		}
+		RateLimit githubRateLimitField
 	}
 	variables := map[string]interface{}{
-		"owner":  githubv4.String(owner),
-		"repo":   githubv4.String(repo),
-		"states": pullRequestStates,
+		"owner": githubv4.String(owner),
+		"repo":  githubv4.String(repo),
 	}
 
-	err := service.client.Query(context.Background(), &query, variables)
-	return query.Repository.PullRequests.TotalCount, err
+	if err := service.client.Query(context.Background(), &query, variables); err != nil {
+		return "", err
+	}
+	releases := make([]repositoryRelease, 0, len(query.Repository.Releases.Nodes))
+	for _, node := range query.Repository.Releases.Nodes {
+		releases = append(releases, repositoryRelease{
+			tagName:      node.TagName,
+			isDraft:      node.IsDraft,
+			isPrerelease: node.IsPrerelease,
+		})
+	}
+
+	return latestRelease(releases, includePrereleases)
 }
 
-func (service *githubService) getStarCount(owner string, repo string) (int, error) {
+func (service *githubService) getLatestTag(owner string, repo string, includePrereleases bool) (string, error) {
 	var query struct {
 		Repository struct {
-			Stargazers struct {
-				TotalCount int
-			}
+			Refs struct {
+				Nodes []struct {
+					Name string
+				}
+			} `graphql:"refs(refPrefix: \"refs/tags/\", first: 100, orderBy: {field: TAG_COMMIT_DATE, direction: DESC})"`
 		} `graphql:"repository(owner: $owner, name: $repo)"`
+		RateLimit githubRateLimitField
 	}
 	variables := map[string]interface{}{
 		"owner": githubv4.String(owner),
 		"repo":  githubv4.String(repo),
 	}
 
-	err := service.client.Query(context.Background(), &query, variables)
-	return query.Repository.Stargazers.TotalCount, err
+	if err := service.client.Query(context.Background(), &query, variables); err != nil {
+		return "", err
+	}
+	tags := make([]string, 0, len(query.Repository.Refs.Nodes))
+	for _, node := range query.Repository.Refs.Nodes {
+		tags = append(tags, node.Name)
+	}
+
+	return latestTag(tags, includePrereleases)
 }
 
-func (service *githubService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	routeVariables := mux.Vars(r)
-	owner := routeVariables["owner"]
-	repo := routeVariables["repo"]
-	method := routeVariables["method"]
-
-	// Fetch data
-	var color, status, subject string
-	var value int
-	var err error
-	switch method {
-	case "forks":
-		subject = "forks"
-		value, err = service.getForkCount(owner, repo)
-	case "issues":
-		state := r.URL.Query().Get("state")
-		switch state {
-		case "":
-			subject = "issues"
-		case "open":
-			subject = "open issues"
-		case "closed":
-			subject = "closed issues"
-		default:
-			service.logger.Info("Unsupported state",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.String("state", state))
-			if err := badRequest(w, service.config); err != nil {
-				service.logger.Error("Failed to create error badge",
-					zap.String("url", r.URL.RequestURI()),
-					zap.String("service", service.name),
-					zap.String("method", method),
-					zap.Error(err))
-			}
-			return
-		}
-		value, err = service.getIssueCount(owner, repo, state)
-	case "pull-requests":
-		state := r.URL.Query().Get("state")
-		switch state {
-		case "":
-			subject = "PRs"
-		case "open":
-			subject = "open PRs"
-		case "closed":
-			subject = "closed PRs"
-		case "merged":
-			subject = "merged PRs"
-		default:
-			service.logger.Info("Unsupported state",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.String("state", state))
-			if err := badRequest(w, service.config); err != nil {
-				service.logger.Error("Failed to create error badge",
-					zap.String("url", r.URL.RequestURI()),
-					zap.String("service", service.name),
-					zap.String("method", method),
-					zap.Error(err))
-			}
-			return
-		}
-		value, err = service.getPullRequestCount(owner, repo, state)
-	case "stars":
-		subject = "stars"
-		value, err = service.getStarCount(owner, repo)
-	default:
-		service.logger.Info("Unsupported method",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method))
-		if err := notFound(w, service.config); err != nil {
-			service.logger.Error("Failed to create error badge",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.Error(err))
-		}
-		return
+// fetch sends a request to the GitHub REST API
+func (service *githubService) fetch(url string) (*http.Response, error) {
+	return service.fetchContext(context.Background(), url)
+}
+
+// fetchContext sends a request to the GitHub REST API, bound to the given context
+func (service *githubService) fetchContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := service.httpClient.Do(req)
 	if err != nil {
-		service.logger.Error("Failed to fetch data",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-		if err := internalServerError(w, service.config); err != nil {
-			service.logger.Error("Failed to create error badge",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.Error(err))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		}
-		return
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &upstreamStatusError{statusCode: resp.StatusCode}
 	}
-	status = formatIntegerWithMetricPrefix(value)
 
-	// Overwrite any badge texts
-	if queryColor := r.URL.Query().Get("color"); queryColor != "" {
-		color = queryColor
+	return resp, err
+}
+
+func (service *githubService) getDefaultBranch(owner string, repo string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s", service.baseURL, owner, repo)
+	resp, err := service.fetch(url)
+	if err != nil {
+		return "", err
 	}
-	if queryStatus := r.URL.Query().Get("status"); queryStatus != "" {
-		status = queryStatus
+	defer resp.Body.Close()
+
+	var repository githubRepositoryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&repository); err != nil {
+		return "", err
 	}
-	if querySubject := r.URL.Query().Get("subject"); querySubject != "" {
-		subject = querySubject
+
+	return repository.DefaultBranch, nil
+}
+
+// getPipelineStatus returns the status of the latest GitHub Actions workflow run on a branch (defaults
+// to the default branch), optionally filtered by workflow name or file name (eg. "CI" or "ci.yml")
+func (service *githubService) getPipelineStatus(owner string, repo string, branch string, workflow string) (string, error) {
+	if branch == "" {
+		defaultBranch, err := service.getDefaultBranch(owner, repo)
+		if err != nil {
+			return "", err
+		}
+		branch = defaultBranch
 	}
 
-	// Generate badge
-	generatedBadge, err := badge.Create(&badge.Params{
-		Style:   badge.Style(r.URL.Query().Get("style")),
-		Subject: subject,
-		Status:  status,
-		Color:   color,
-		Icon:    r.URL.Query().Get("icon"),
-	})
+	perPage := 1
+	if workflow != "" {
+		perPage = 100
+	}
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/runs?branch=%s&per_page=%d",
+		service.baseURL, owner, repo, neturl.QueryEscape(branch), perPage)
+	resp, err := service.fetch(url)
 	if err != nil {
-		service.logger.Error("Failed to create badge",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+		return "", err
 	}
+	defer resp.Body.Close()
 
-	if !service.config.ExcludeCacheControlHeaders {
-		// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
-		w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
+	var runs githubWorkflowRunsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&runs); err != nil {
+		return "", err
 	}
-	w.Header().Set("Content-Type", "image/svg+xml;utf-8")
-	_, err = w.Write([]byte(generatedBadge))
-	if err != nil {
-		service.logger.Error("Failed to write HTTP response",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
+	for _, run := range runs.WorkflowRuns {
+		if workflow != "" && !strings.EqualFold(run.Name, workflow) && path.Base(run.Path) != workflow {
+			continue
+		}
+		return githubPipelineStatus(run.Status, run.Conclusion), nil
 	}
+
+	return "", errNoPipelines
+}
+
+// githubPipelineStatus normalizes the status & conclusion of a GitHub Actions workflow run
+func githubPipelineStatus(status string, conclusion string) string {
+	if status != "completed" {
+		return pipelineRunning
+	}
+
+	switch conclusion {
+	case "success":
+		return pipelinePassing
+	case "failure", "timed_out", "startup_failure", "action_required":
+		return pipelineFailing
+	case "cancelled":
+		return pipelineCancelled
+	default:
+		return pipelineUnknown
+	}
+}
+
+func (service *githubService) getPullRequestCount(owner string, repo string, pullRequestState string) (int, error) {
+	counts, err := service.batcher.load(owner, repo)
+	switch pullRequestState {
+	case "open":
+		return counts.OpenPullRequests.TotalCount, err
+	case "closed":
+		return counts.ClosedPullRequests.TotalCount, err
+	case "merged":
+		return counts.MergedPullRequests.TotalCount, err
+	default:
+		return counts.PullRequests.TotalCount, err
+	}
+}
+
+func (service *githubService) getStarCount(owner string, repo string) (int, error) {
+	counts, err := service.batcher.load(owner, repo)
+	return counts.Stargazers.TotalCount, err
+}
+
+// probe checks that the GitHub API is reachable, the access token is valid & not rate limited
+func (service *githubService) probe(ctx context.Context) error {
+	var query struct {
+		RateLimit githubRateLimitField
+	}
+	if err := service.client.Query(ctx, &query, nil); err != nil {
+		return err
+	}
+	if query.RateLimit.Remaining == 0 {
+		return fmt.Errorf("rate limit exceeded")
+	}
+
+	return nil
+}
+
+// methods returns the badge methods supported by the Github badge service
+func (service *githubService) methods() map[string]ProviderMethod {
+	return map[string]ProviderMethod{
+		"forks": countMethod(service.cache, service.name, "forks", service.getForkCount),
+		"issues": stateCountMethod(service.cache, service.name, map[string]string{
+			"":       "issues",
+			"open":   "open issues",
+			"closed": "closed issues",
+		}, service.getIssueCount),
+		"pipeline": pipelineMethod(service.cache, service.name, true, service.getPipelineStatus),
+		"pull-requests": stateCountMethod(service.cache, service.name, map[string]string{
+			"":       "PRs",
+			"open":   "open PRs",
+			"closed": "closed PRs",
+			"merged": "merged PRs",
+		}, service.getPullRequestCount),
+		"release": versionMethod(service.cache, service.name, "release", service.getLatestRelease),
+		"stars":   countMethod(service.cache, service.name, "stars", service.getStarCount),
+		"tag":     versionMethod(service.cache, service.name, "tag", service.getLatestTag),
+	}
+}
+
+func (service *githubService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	service.handler.ServeHTTP(w, r)
 }
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// githubBatchSize is the maximum number of repositories fetched by a single batched GraphQL query
+const githubBatchSize = 50
+
+// githubBatchRetries is the number of times repositories failed by errors without a path are queried again
+const githubBatchRetries = 1
+
+// githubRepositoryCountsFragment selects all counts of a repository supported by the Github badge service
+const githubRepositoryCountsFragment = `fragment counts on Repository {
+  forks { totalCount }
+  stargazers { totalCount }
+  issues { totalCount }
+  openIssues: issues(states: OPEN) { totalCount }
+  closedIssues: issues(states: CLOSED) { totalCount }
+  pullRequests { totalCount }
+  openPullRequests: pullRequests(states: OPEN) { totalCount }
+  closedPullRequests: pullRequests(states: CLOSED) { totalCount }
+  mergedPullRequests: pullRequests(states: MERGED) { totalCount }
+}`
+
+// githubRateLimitSelection selects the GraphQL rate limit of a query, recorded by the token pool (see
+// `githubRateLimitField`)
+const githubRateLimitSelection = `rateLimit { remaining resetAt cost }`
+
+type githubRepository struct {
+	owner string
+	name  string
+}
+
+type githubTotalCount struct {
+	TotalCount int `json:"totalCount"`
+}
+
+// githubRepositoryCounts holds the counts of a repository fetched by a batched GraphQL query
+type githubRepositoryCounts struct {
+	Forks              githubTotalCount `json:"forks"`
+	Stargazers         githubTotalCount `json:"stargazers"`
+	Issues             githubTotalCount `json:"issues"`
+	OpenIssues         githubTotalCount `json:"openIssues"`
+	ClosedIssues       githubTotalCount `json:"closedIssues"`
+	PullRequests       githubTotalCount `json:"pullRequests"`
+	OpenPullRequests   githubTotalCount `json:"openPullRequests"`
+	ClosedPullRequests githubTotalCount `json:"closedPullRequests"`
+	MergedPullRequests githubTotalCount `json:"mergedPullRequests"`
+}
+
+// githubGraphQLError represents an error of a GraphQL response, whose path starts with the alias of the
+// failed field & may contain list indices (eg. ["r0", "releases", "nodes", 0])
+type githubGraphQLError struct {
+	Type    string        `json:"type"`
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path"`
+}
+
+// alias returns the alias of the top-level field of an error, or false if it doesn't have a path
+func (queryErr githubGraphQLError) alias() (string, bool) {
+	if len(queryErr.Path) == 0 {
+		return "", false
+	}
+	alias, ok := queryErr.Path[0].(string)
+	return alias, ok
+}
+
+// githubBatchResponse represents the response of a batched GraphQL query, whose data holds the counts of each
+// repository by alias (or null if it failed) along with the rate limit of the query
+type githubBatchResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []githubGraphQLError       `json:"errors"`
+}
+
+// githubBatch represents the repositories of a batched GraphQL query & its results
+type githubBatch struct {
+	repositories []githubRepository
+	seen         map[githubRepository]bool
+	once         sync.Once
+	done         chan struct{}
+	counts       map[githubRepository]githubRepositoryCounts
+	errs         map[githubRepository]error
+	err          error
+}
+
+// githubBatcher collects the repositories requested within a short window & fetches their counts with a
+// single aliased GraphQL query, fanning the results back out to all waiting requests
+type githubBatcher struct {
+	window  time.Duration
+	timeout time.Duration
+	fetch   func(ctx context.Context, repositories []githubRepository) (map[githubRepository]githubRepositoryCounts, map[githubRepository]error, error)
+
+	mutex   sync.Mutex
+	pending *githubBatch
+}
+
+// newGithubBatcher returns a batcher fetching the counts of repositories requested within `window`, cancelling
+// queries that take longer than `timeout` (if non-zero), so that batches never outlive the requests waiting for them
+func newGithubBatcher(window time.Duration, timeout time.Duration,
+	fetch func(context.Context, []githubRepository) (map[githubRepository]githubRepositoryCounts, map[githubRepository]error, error)) *githubBatcher {
+	return &githubBatcher{window: window, timeout: timeout, fetch: fetch}
+}
+
+// load returns the counts of a repository, waiting for the batch it is added to
+func (batcher *githubBatcher) load(owner string, name string) (githubRepositoryCounts, error) {
+	repository := githubRepository{owner: owner, name: name}
+
+	batcher.mutex.Lock()
+	batch := batcher.pending
+	if batch == nil {
+		batch = &githubBatch{seen: make(map[githubRepository]bool), done: make(chan struct{})}
+		batcher.pending = batch
+		time.AfterFunc(batcher.window, func() { batcher.flush(batch) })
+	}
+	if !batch.seen[repository] {
+		batch.seen[repository] = true
+		batch.repositories = append(batch.repositories, repository)
+	}
+	full := len(batch.repositories) >= githubBatchSize
+	if full {
+		batcher.pending = nil
+	}
+	batcher.mutex.Unlock()
+
+	if full {
+		batcher.flush(batch)
+	}
+	<-batch.done
+
+	if batch.err != nil {
+		return githubRepositoryCounts{}, batch.err
+	}
+	if err := batch.errs[repository]; err != nil {
+		return githubRepositoryCounts{}, err
+	}
+
+	return batch.counts[repository], nil
+}
+
+// flush fetches the counts of a batch, if it hasn't been fetched yet
+func (batcher *githubBatcher) flush(batch *githubBatch) {
+	batcher.mutex.Lock()
+	if batcher.pending == batch {
+		batcher.pending = nil
+	}
+	batcher.mutex.Unlock()
+
+	batch.once.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		if batcher.timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), batcher.timeout)
+		}
+		defer cancel()
+		batch.counts, batch.errs, batch.err = batcher.fetch(ctx, batch.repositories)
+		close(batch.done)
+	})
+}
+
+// fetchRepositoryCounts fetches the counts of several repositories with a single aliased GraphQL query,
+// returning the errors of individual repositories (eg. missing repositories) separately
+func (service *githubService) fetchRepositoryCounts(ctx context.Context, repositories []githubRepository) (
+	map[githubRepository]githubRepositoryCounts, map[githubRepository]error, error) {
+	counts := make(map[githubRepository]githubRepositoryCounts)
+	errs := make(map[githubRepository]error)
+	for attempt := 0; len(repositories) > 0; attempt++ {
+		data, err := service.queryRepositoryCounts(ctx, repositories)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// errors without a path (eg. timeouts) don't tell which repositories failed, so the repositories
+		// without results are retried
+		var queryErr error
+		aliasErrs := make(map[string]error)
+		for _, dataErr := range data.Errors {
+			alias, ok := dataErr.alias()
+			switch {
+			case !ok:
+				queryErr = fmt.Errorf("GraphQL query failed: %s", dataErr.Message)
+			case dataErr.Type == "NOT_FOUND":
+				aliasErrs[alias] = &upstreamStatusError{statusCode: http.StatusNotFound}
+			case aliasErrs[alias] == nil:
+				aliasErrs[alias] = fmt.Errorf("GraphQL query failed: %s", dataErr.Message)
+			}
+		}
+		var retries []githubRepository
+		for i, repository := range repositories {
+			alias := fmt.Sprintf("r%d", i)
+			switch {
+			case aliasErrs[alias] != nil:
+				errs[repository] = aliasErrs[alias]
+			case hasData(data.Data[alias]):
+				var repositoryCounts githubRepositoryCounts
+				if err := json.Unmarshal(data.Data[alias], &repositoryCounts); err != nil {
+					return nil, nil, err
+				}
+				counts[repository] = repositoryCounts
+			case queryErr != nil && attempt < githubBatchRetries:
+				retries = append(retries, repository)
+			case queryErr != nil:
+				errs[repository] = queryErr
+			default:
+				errs[repository] = &upstreamStatusError{statusCode: http.StatusNotFound}
+			}
+		}
+		repositories = retries
+	}
+
+	return counts, errs, nil
+}
+
+// hasData reports whether a field of a GraphQL response has a value
+func hasData(field json.RawMessage) bool {
+	return len(field) > 0 && string(field) != "null"
+}
+
+// queryRepositoryCounts sends an aliased GraphQL query of the counts of several repositories ("r<INDEX>"), selecting
+// the rate limit of the query as well
+func (service *githubService) queryRepositoryCounts(ctx context.Context,
+	repositories []githubRepository) (*githubBatchResponse, error) {
+	var variableDefinitions, fields []string
+	variables := make(map[string]interface{})
+	for i, repository := range repositories {
+		variableDefinitions = append(variableDefinitions, fmt.Sprintf("$owner%d: String!, $name%d: String!", i, i))
+		fields = append(fields, fmt.Sprintf("r%d: repository(owner: $owner%d, name: $name%d) { ...counts }", i, i, i))
+		variables[fmt.Sprintf("owner%d", i)] = repository.owner
+		variables[fmt.Sprintf("name%d", i)] = repository.name
+	}
+	fields = append(fields, githubRateLimitSelection)
+	query := fmt.Sprintf("query(%s) {\n  %s\n}\n%s", strings.Join(variableDefinitions, ", "),
+		strings.Join(fields, "\n  "), githubRepositoryCountsFragment)
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", service.graphqlURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := service.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &upstreamStatusError{statusCode: resp.StatusCode}
+	}
+
+	var data githubBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
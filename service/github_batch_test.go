@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+func TestGithubBatchedCounts(t *testing.T) {
+	t.Parallel()
+
+	var requestCount atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount.Add(1)
+		var request struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		if r.URL.Path != "/graphql" || json.NewDecoder(r.Body).Decode(&request) != nil ||
+			!strings.Contains(request.Query, "fragment counts on Repository") ||
+			!strings.Contains(request.Query, githubRateLimitSelection) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data := map[string]interface{}{
+			"rateLimit": map[string]interface{}{"remaining": 4999, "resetAt": "2030-01-01T00:00:00Z", "cost": 1},
+		}
+		var errs []map[string]interface{}
+		for i := 0; i < len(request.Variables)/2; i++ {
+			alias := fmt.Sprintf("r%d", i)
+			repository := request.Variables[fmt.Sprintf("owner%d", i)] + "/" + request.Variables[fmt.Sprintf("name%d", i)]
+			switch repository {
+			case "owner/repo":
+				data[alias] = json.RawMessage(`{
+					"forks": {"totalCount": 56}, "stargazers": {"totalCount": 1234},
+					"issues": {"totalCount": 78}, "openIssues": {"totalCount": 12}, "closedIssues": {"totalCount": 66},
+					"pullRequests": {"totalCount": 90}, "openPullRequests": {"totalCount": 3},
+					"closedPullRequests": {"totalCount": 7}, "mergedPullRequests": {"totalCount": 80}
+				}`)
+			case "owner/other":
+				data[alias] = json.RawMessage(`{"stargazers": {"totalCount": 4321}}`)
+			case "owner/broken":
+				data[alias] = nil
+				errs = append(errs, map[string]interface{}{
+					"path":    []interface{}{alias, "issues", "nodes", 0},
+					"message": "Something went wrong while executing your query.",
+				})
+			default:
+				data[alias] = nil
+				errs = append(errs, map[string]interface{}{
+					"type":    "NOT_FOUND",
+					"path":    []string{alias},
+					"message": "Could not resolve to a Repository with the name '" + repository + "'.",
+				})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs})
+	}))
+	t.Cleanup(upstream.Close)
+
+	service, err := NewGithubInstanceService(&config.Config{GithubBatchWindow: 50 * time.Millisecond},
+		zaptest.NewLogger(t), config.GitProviderInstance{
+			Name:        "corp",
+			BaseURL:     upstream.URL,
+			AccessToken: "testToken",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		requestPath  string
+		expectedBody string
+	}{
+		{"/forks/owner/repo", createBadge(&badge.Params{Subject: "forks", Status: "56"})},
+		{"/stars/owner/repo", createBadge(&badge.Params{Subject: "stars", Status: "1.23k"})},
+		{"/issues/owner/repo", createBadge(&badge.Params{Subject: "issues", Status: "78"})},
+		{"/issues/owner/repo?state=closed", createBadge(&badge.Params{Subject: "closed issues", Status: "66"})},
+		{"/pull-requests/owner/repo?state=merged", createBadge(&badge.Params{Subject: "merged PRs", Status: "80"})},
+		{"/stars/owner/other", createBadge(&badge.Params{Subject: "stars", Status: "4.32k"})},
+		{"/stars/owner/missing", createBadge(&badge.Params{Subject: "aegis", Status: "repo not found"})},
+		{"/stars/owner/broken", createBadge(&badge.Params{Subject: "aegis", Status: "internal server error"})},
+	}
+
+	// concurrent requests are fetched with a single batched query
+	var wg sync.WaitGroup
+	bodies := make([]string, len(testCases))
+	for i, testCase := range testCases {
+		wg.Add(1)
+		go func(i int, requestPath string) {
+			defer wg.Done()
+			bodies[i] = serveHTTPRequest(t, newGitProviderRouter(service), requestPath).Body.String()
+		}(i, testCase.requestPath)
+	}
+	wg.Wait()
+
+	for i, testCase := range testCases {
+		assert.Equal(t, testCase.expectedBody, bodies[i], testCase.requestPath)
+	}
+	assert.Equal(t, int32(1), requestCount.Load())
+}
+
+func TestGithubBatchedCountsRetry(t *testing.T) {
+	t.Parallel()
+
+	var requestCount atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := requestCount.Add(1)
+		var request struct {
+			Variables map[string]string `json:"variables"`
+		}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data := make(map[string]interface{})
+		var errs []map[string]interface{}
+		for i := 0; i < len(request.Variables)/2; i++ {
+			alias := fmt.Sprintf("r%d", i)
+			switch request.Variables[fmt.Sprintf("name%d", i)] {
+			case "repo":
+				data[alias] = json.RawMessage(`{"stargazers": {"totalCount": 1234}}`)
+			case "flaky":
+				if attempt > 1 {
+					data[alias] = json.RawMessage(`{"stargazers": {"totalCount": 4321}}`)
+				} else {
+					data[alias] = nil
+				}
+			case "missing":
+				data[alias] = nil
+				errs = append(errs, map[string]interface{}{"type": "NOT_FOUND", "path": []string{alias}})
+			default:
+				data[alias] = nil
+			}
+		}
+		// errors without a path don't tell which repositories failed
+		errs = append(errs, map[string]interface{}{"message": "Timeout on validation of query"})
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs})
+	}))
+	t.Cleanup(upstream.Close)
+
+	service, err := NewGithubInstanceService(&config.Config{}, zaptest.NewLogger(t), config.GitProviderInstance{
+		Name:        "corp",
+		BaseURL:     upstream.URL,
+		AccessToken: "testToken",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := githubRepository{owner: "owner", name: "repo"}
+	flaky := githubRepository{owner: "owner", name: "flaky"}
+	missing := githubRepository{owner: "owner", name: "missing"}
+	failing := githubRepository{owner: "owner", name: "failing"}
+	counts, errs, err := service.(*githubService).fetchRepositoryCounts(
+		context.Background(), []githubRepository{repo, flaky, missing, failing})
+	assert.NoError(t, err)
+	assert.Equal(t, 1234, counts[repo].Stargazers.TotalCount)
+	assert.Equal(t, 4321, counts[flaky].Stargazers.TotalCount)
+	assert.Equal(t, &upstreamStatusError{statusCode: http.StatusNotFound}, errs[missing])
+	assert.EqualError(t, errs[failing], "GraphQL query failed: Timeout on validation of query")
+	assert.Len(t, errs, 2)
+	assert.Equal(t, int32(2), requestCount.Load())
+}
+
+func TestGithubBatchedCountsTimeout(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// client disconnects are only noticed once the request body is consumed
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(upstream.Close)
+
+	service, err := NewGithubInstanceService(&config.Config{WriteTimeout: 50 * time.Millisecond},
+		zaptest.NewLogger(t), config.GitProviderInstance{
+			Name:        "corp",
+			BaseURL:     upstream.URL,
+			AccessToken: "testToken",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.(*githubService).batcher.load("owner", "repo")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+func TestGithubGraphQLURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"https://api.github.com", "https://api.github.com/graphql"},
+		{"https://api.github.com/", "https://api.github.com/graphql"},
+		{"https://github.example.com/api/v3", "https://github.example.com/api/graphql"},
+		{"https://github.example.com/api/v3/", "https://github.example.com/api/graphql"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.input, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, githubGraphQLURL(testCase.input))
+		})
+	}
+}
+
+func TestGithubPipelineBadgeService(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer testToken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/repos/owner/repo?":
+			_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+		case "/repos/owner/repo/actions/runs?branch=main&per_page=1":
+			_, _ = w.Write([]byte(`{"workflow_runs": [{"name": "CI", "path": ".github/workflows/ci.yml", "status": "completed", "conclusion": "success"}]}`))
+		case "/repos/owner/repo/actions/runs?branch=dev&per_page=100":
+			_, _ = w.Write([]byte(`{"workflow_runs": [
+				{"name": "Release", "path": ".github/workflows/release.yml", "status": "in_progress", "conclusion": null},
+				{"name": "CI", "path": ".github/workflows/ci.yml", "status": "completed", "conclusion": "failure"},
+				{"name": "Lint", "path": ".github/workflows/lint.yml", "status": "completed", "conclusion": "cancelled"}
+			]}`))
+		case "/repos/owner/repo/actions/runs?branch=empty&per_page=1":
+			_, _ = w.Write([]byte(`{"workflow_runs": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	service, err := NewGithubInstanceService(&config.Config{}, zaptest.NewLogger(t), config.GitProviderInstance{
+		Name:        "corp",
+		BaseURL:     upstream.URL,
+		AccessToken: "testToken",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		requestPath  string
+		expectedBody string
+	}{
+		{
+			requestPath:  "/pipeline/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "passing", Color: "#4c1"}),
+		},
+		{
+			requestPath:  "/pipeline/owner/repo?branch=dev&workflow=ci",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "failing", Color: "#e05d44"}),
+		},
+		{
+			requestPath:  "/pipeline/owner/repo?branch=dev&workflow=release.yml",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "running", Color: "#dfb317"}),
+		},
+		{
+			requestPath:  "/pipeline/owner/repo?branch=dev&workflow=lint",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "cancelled", Color: "#9f9f9f"}),
+		},
+		{
+			requestPath:  "/pipeline/owner/repo?branch=dev&workflow=deploy",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no pipelines"}),
+		},
+		{
+			requestPath:  "/pipeline/owner/repo?branch=empty",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no pipelines"}),
+		},
+		{
+			requestPath:  "/pipeline/owner/missing",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "repo not found"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.requestPath, func(t *testing.T) {
+			res := serveHTTPRequest(t, newGitProviderRouter(service), testCase.requestPath)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultGithubRateLimitWait is the duration to withhold a rate limited token whose reset time is unknown
+const defaultGithubRateLimitWait = time.Minute
+
+// githubRateLimit represents the rate limit state of a token for a GitHub API resource, along with the cost of
+// the last GraphQL query (if known)
+type githubRateLimit struct {
+	remaining int
+	reset     time.Time
+	cost      int
+}
+
+// githubRateLimitResponse represents the `rateLimit` field of a GraphQL response, selected by all GraphQL queries
+// (see `githubRateLimitField`) to report the cost of the query along with the remaining budget
+type githubRateLimitResponse struct {
+	Data struct {
+		RateLimit *struct {
+			Remaining int       `json:"remaining"`
+			ResetAt   time.Time `json:"resetAt"`
+			Cost      int       `json:"cost"`
+		} `json:"rateLimit"`
+	} `json:"data"`
+}
+
+// githubToken represents an access token of the token pool & its rate limits by API resource
+type githubToken struct {
+	source oauth2.TokenSource
+	limits map[string]githubRateLimit
+}
+
+// githubTokenPool rotates requests to the GitHub API between access tokens, using the token with the
+// most remaining budget according to the rate limit headers (or GraphQL `rateLimit` field) of previous responses
+type githubTokenPool struct {
+	mutex  sync.Mutex
+	tokens []*githubToken
+	now    func() time.Time
+}
+
+func newGithubTokenPool(sources ...oauth2.TokenSource) *githubTokenPool {
+	pool := &githubTokenPool{now: time.Now}
+	for _, source := range sources {
+		pool.tokens = append(pool.tokens, &githubToken{
+			source: source,
+			limits: make(map[string]githubRateLimit),
+		})
+	}
+
+	return pool
+}
+
+// githubResource returns the rate-limited GitHub API resource of a request
+func githubResource(req *http.Request) string {
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		return "graphql"
+	}
+
+	return "core"
+}
+
+// acquire returns the token with the most remaining budget for a resource, or the duration until the
+// earliest rate limit reset if the budgets of all tokens are exhausted
+func (pool *githubTokenPool) acquire(resource string) (*githubToken, time.Duration) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := pool.now()
+	var selected *githubToken
+	selectedRemaining := 0
+	retryAfter := time.Duration(math.MaxInt64)
+	for _, token := range pool.tokens {
+		// tokens without (current) rate limit information are assumed to have their full budget
+		remaining := math.MaxInt
+		limit, ok := token.limits[resource]
+		if ok && now.Before(limit.reset) {
+			remaining = limit.remaining
+		}
+		// tokens whose budget is lower than the cost of their last query would likely be rejected
+		if remaining <= 0 || remaining < limit.cost {
+			retryAfter = min(retryAfter, limit.reset.Sub(now))
+			continue
+		}
+		if remaining > selectedRemaining {
+			selected, selectedRemaining = token, remaining
+		}
+	}
+	if selected == nil {
+		return nil, max(retryAfter, time.Second)
+	}
+
+	return selected, 0
+}
+
+// update records the rate limit state of a token from a response (or the `rateLimit` field of a GraphQL
+// response, if not nil), returning whether the request was rejected due to exhausted rate limits
+func (pool *githubTokenPool) update(token *githubToken, resource string, resp *http.Response,
+	queryLimit *githubRateLimit) bool {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := pool.now()
+	limit, hasLimit := githubRateLimit{}, false
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		limit.remaining, hasLimit = remaining, true
+		limit.reset = now.Add(defaultGithubRateLimitWait)
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			limit.reset = time.Unix(reset, 0)
+		}
+	}
+	if queryLimit != nil {
+		limit, hasLimit = *queryLimit, true
+	}
+
+	limited := false
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		// secondary rate limits are signalled by a Retry-After header
+		if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			limit = githubRateLimit{remaining: 0, reset: now.Add(time.Duration(retryAfter) * time.Second)}
+			hasLimit, limited = true, true
+		} else if hasLimit && limit.remaining == 0 {
+			limited = true
+		}
+	}
+	if hasLimit {
+		// exhausted tokens are withheld for at least a second, even if their reset time has passed (eg. with a
+		// `Retry-After: 0` header or a clock drift) so that they aren't retried right away
+		if minReset := now.Add(time.Second); limit.remaining <= 0 && limit.reset.Before(minReset) {
+			limit.reset = minReset
+		}
+		token.limits[resource] = limit
+	}
+
+	return limited
+}
+
+// githubTokenTransport authenticates requests to the GitHub API with the tokens of a token pool, retrying
+// rate limited requests with the next best token
+type githubTokenTransport struct {
+	pool *githubTokenPool
+	next http.RoundTripper
+}
+
+func (transport *githubTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := githubResource(req)
+	// each request is attempted at most once per token, even if the rate limits of rejected tokens are reset
+	// (or updated by concurrent requests) in the meantime
+	for attempt := 0; attempt < len(transport.pool.tokens); attempt++ {
+		token, retryAfter := transport.pool.acquire(resource)
+		if token == nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, &rateLimitError{retryAfter: retryAfter}
+		}
+		accessToken, err := token.source.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		authenticatedReq := req.Clone(req.Context())
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry rate limited request with a non-rewindable body")
+			}
+			if authenticatedReq.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		accessToken.SetAuthHeader(authenticatedReq)
+
+		resp, err := transport.next.RoundTrip(authenticatedReq)
+		if err != nil {
+			return nil, err
+		}
+		var queryLimit *githubRateLimit
+		if resource == "graphql" && resp.StatusCode == http.StatusOK {
+			if queryLimit, err = readGraphQLRateLimit(resp); err != nil {
+				return nil, err
+			}
+		}
+		if !transport.pool.update(token, resource, resp, queryLimit) {
+			return resp, nil
+		}
+		resp.Body.Close()
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	_, retryAfter := transport.pool.acquire(resource)
+	return nil, &rateLimitError{retryAfter: max(retryAfter, time.Second)}
+}
+
+// readGraphQLRateLimit returns the `rateLimit` field of a GraphQL response, or nil if the query didn't select it,
+// buffering the response body so that it can be read again
+func readGraphQLRateLimit(resp *http.Response) (*githubRateLimit, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var data githubRateLimitResponse
+	if err := json.Unmarshal(body, &data); err != nil || data.Data.RateLimit == nil {
+		return nil, nil
+	}
+	rateLimit := data.Data.RateLimit
+
+	return &githubRateLimit{remaining: rateLimit.Remaining, reset: rateLimit.ResetAt, cost: rateLimit.Cost}, nil
+}
+
+// githubAppTokenSource creates installation access tokens of a GitHub App
+type githubAppTokenSource struct {
+	baseURL        string
+	appID          string
+	installationID string
+	privateKey     *rsa.PrivateKey
+	client         *http.Client
+	now            func() time.Time
+}
+
+// newGithubAppTokenSource returns a token source of installation access tokens of a GitHub App, reusing
+// tokens until they expire
+func newGithubAppTokenSource(baseURL string, appID string, installationID string,
+	privateKeyPEM []byte, client *http.Client) (oauth2.TokenSource, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: no PEM data found")
+	}
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		key, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return nil, fmt.Errorf("invalid GitHub App private key: %v", err)
+		}
+		var ok bool
+		if privateKey, ok = key.(*rsa.PrivateKey); !ok {
+			return nil, fmt.Errorf("invalid GitHub App private key: not a RSA key")
+		}
+	}
+
+	return oauth2.ReuseTokenSource(nil, &githubAppTokenSource{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     privateKey,
+		client:         client,
+		now:            time.Now,
+	}), nil
+}
+
+// jwt returns a JSON Web Token authenticating as the GitHub App
+func (source *githubAppTokenSource) jwt() (string, error) {
+	now := source.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	// backdate the token to allow for clock drift, GitHub rejects tokens valid for more than 10 minutes
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": source.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, source.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (source *githubAppTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := source.jwt()
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", source.baseURL, source.installationID)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := source.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &upstreamStatusError{statusCode: resp.StatusCode}
+	}
+
+	var data struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Token{AccessToken: data.Token, TokenType: "Bearer", Expiry: data.ExpiresAt}, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/oauth2"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// newMockRateLimitedGithubServer returns a GitHub API server whose rate limit headers depend on the
+// access token, recording the tokens of all requests
+func newMockRateLimitedGithubServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mutex sync.Mutex
+	var usedTokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mutex.Lock()
+		usedTokens = append(usedTokens, token)
+		mutex.Unlock()
+
+		if body, _ := io.ReadAll(r.Body); r.Method == "POST" && string(body) != `{"query":"{viewer{login}}"}` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reset := strconv.FormatInt(time.Now().Add(2*time.Minute).Unix(), 10)
+		w.Header().Set("X-RateLimit-Reset", reset)
+		switch token {
+		case "exhaustedToken":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		case "lowToken":
+			w.Header().Set("X-RateLimit-Remaining", "10")
+			_, _ = w.Write([]byte(`{"data": {}}`))
+		case "highToken":
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+			_, _ = w.Write([]byte(`{"data": {}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), usedTokens...)
+	}
+}
+
+func TestGithubTokenTransport(t *testing.T) {
+	t.Parallel()
+
+	upstream, usedTokens := newMockRateLimitedGithubServer(t)
+	client := &http.Client{Transport: &githubTokenTransport{
+		pool: newGithubTokenPool(
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "exhaustedToken"}),
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "lowToken"}),
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "highToken"}),
+		),
+		next: http.DefaultTransport,
+	}}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Post(upstream.URL+"/graphql", "application/json",
+			strings.NewReader(`{"query":"{viewer{login}}"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	// rate limited requests are retried with the next token, then tokens are picked by their remaining budget
+	assert.Equal(t, []string{"exhaustedToken", "lowToken", "highToken", "highToken"}, usedTokens())
+}
+
+func TestGithubTokenTransportQueryRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var usedTokens []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		usedTokens = append(usedTokens, token)
+
+		// the budget of the rate limit headers is shared by queries of any cost
+		w.Header().Set("X-RateLimit-Remaining", "4000")
+		resetAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		switch token {
+		case "expensiveToken":
+			_, _ = fmt.Fprintf(w, `{"data": {"rateLimit": {"remaining": 5, "resetAt": %q, "cost": 10}}}`, resetAt)
+		default:
+			_, _ = fmt.Fprintf(w, `{"data": {"rateLimit": {"remaining": 4000, "resetAt": %q, "cost": 1}}}`, resetAt)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	client := &http.Client{Transport: &githubTokenTransport{
+		pool: newGithubTokenPool(
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "expensiveToken"}),
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "otherToken"}),
+		),
+		next: http.DefaultTransport,
+	}}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Post(upstream.URL+"/graphql", "application/json",
+			strings.NewReader(`{"query":"{rateLimit{remaining,resetAt,cost}}"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data githubRateLimitResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&data), "response bodies can be read again")
+		resp.Body.Close()
+		assert.NotNil(t, data.Data.RateLimit)
+	}
+
+	// tokens whose remaining GraphQL budget is lower than the cost of their last query are skipped
+	assert.Equal(t, []string{"expensiveToken", "otherToken", "otherToken"}, usedTokens)
+}
+
+func TestGithubTokenTransportRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	var usedTokens []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		usedTokens = append(usedTokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		mutex.Unlock()
+
+		// secondary rate limits without a wait & primary rate limits reset in the past
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+		if r.URL.Path == "/retry-after" {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(upstream.Close)
+
+	for _, path := range []string{"/retry-after", "/reset"} {
+		pool := newGithubTokenPool(
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "firstToken"}),
+			oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "secondToken"}),
+		)
+		client := &http.Client{Transport: &githubTokenTransport{pool: pool, next: http.DefaultTransport}}
+		mutex.Lock()
+		usedTokens = nil
+		mutex.Unlock()
+
+		// rate limited tokens are withheld for at least a second
+		for i := 0; i < 2; i++ {
+			_, err := client.Get(upstream.URL + path)
+			var rateLimitErr *rateLimitError
+			assert.ErrorAs(t, err, &rateLimitErr, path)
+		}
+		assert.Len(t, usedTokens, 2, path)
+
+		// requests are attempted at most once per token, even if rate limits are reset between attempts
+		now := time.Now()
+		pool.now = func() time.Time {
+			now = now.Add(time.Hour)
+			return now
+		}
+		_, err := client.Get(upstream.URL + path)
+		var rateLimitErr *rateLimitError
+		assert.ErrorAs(t, err, &rateLimitErr, path)
+		assert.Len(t, usedTokens, 4, path)
+	}
+}
+
+func TestGithubRateLimitedBadge(t *testing.T) {
+	t.Parallel()
+
+	upstream, usedTokens := newMockRateLimitedGithubServer(t)
+	service, err := NewGithubInstanceService(&config.Config{}, zaptest.NewLogger(t), config.GitProviderInstance{
+		Name:        "corp",
+		BaseURL:     upstream.URL,
+		AccessToken: "exhaustedToken",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res := serveHTTPRequest(t, newGitProviderRouter(service), "/pipeline/owner/repo")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, createBadge(&badge.Params{Subject: "aegis", Status: "rate limited"}), res.Body.String())
+
+		retryAfter, err := strconv.Atoi(res.Header().Get("Retry-After"))
+		assert.NoError(t, err)
+		assert.True(t, retryAfter > 0 && retryAfter <= 120, "unexpected Retry-After: %d", retryAfter)
+		assert.Equal(t, "public, max-age="+strconv.Itoa(retryAfter)+", s-maxage="+strconv.Itoa(retryAfter),
+			res.Header().Get("Cache-Control"))
+	}
+
+	// exhausted tokens are not used until their rate limits are reset
+	assert.Equal(t, []string{"exhaustedToken"}, usedTokens())
+}
+
+func TestGithubAppTokenSource(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestCount := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if r.Method != "POST" || r.URL.Path != "/app/installations/42/access_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+
+		// verify the JSON Web Token of the app
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		if len(parts) != 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims struct {
+			Issuer string `json:"iss"`
+		}
+		_ = json.Unmarshal(claimsJSON, &claims)
+		if rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, digest[:], signature) != nil || claims.Issuer != "7" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token": "installationToken", "expires_at": "` +
+			time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`))
+	}))
+	t.Cleanup(upstream.Close)
+
+	source, err := newGithubAppTokenSource(upstream.URL, "7", "42",
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}), upstream.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		token, err := source.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "installationToken", token.AccessToken)
+	}
+	// installation tokens are reused until they expire
+	assert.Equal(t, 1, requestCount)
+
+	_, err = newGithubAppTokenSource(upstream.URL, "7", "42", []byte("invalid"), upstream.Client())
+	assert.Error(t, err)
+}
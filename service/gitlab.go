@@ -1,23 +1,29 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
-	"github.com/tohjustin/aegis/pkg/badge"
 	"github.com/tohjustin/aegis/service/config"
 )
 
 type gitlabService struct {
-	name   string
-	config *config.Config
-	logger *zap.Logger
+	name        string
+	baseURL     string
+	accessToken string
+	cache       *responseCache
+	client      *http.Client
+	handler     BadgeService
+	config      *config.Config
+	logger      *zap.Logger
 }
 
 type gitlabProjectsResponse struct {
@@ -48,38 +54,108 @@ type gitlabProjectsResponse struct {
 	} `json:"namespace"`
 }
 
+type gitlabReleaseResponse struct {
+	TagName         string `json:"tag_name"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+}
+
+type gitlabPipelineResponse struct {
+	Status string `json:"status"`
+}
+
+type gitlabTagResponse struct {
+	Name string `json:"name"`
+}
+
 // NewGitlabService returns a HTTP handler for the Gitlab badge service
 func NewGitlabService(configuration *config.Config, logger *zap.Logger) (GitProviderService, error) {
+	return newDefaultGitlabService(configuration, logger, nil)
+}
+
+// NewGitlabInstanceService returns a HTTP handler for the Gitlab badge service of a named GitLab instance
+func NewGitlabInstanceService(configuration *config.Config,
+	logger *zap.Logger, instance config.GitProviderInstance) (GitProviderService, error) {
+	return newGitlabInstanceService(configuration, logger, nil, instance)
+}
+
+func newDefaultGitlabService(configuration *config.Config,
+	logger *zap.Logger, state *serviceState) (GitProviderService, error) {
 	if configuration == nil {
 		return nil, fmt.Errorf("missing config dependency")
 	}
+
+	return newGitlabService(configuration, logger, state, "gitlab",
+		configuration.GitlabBaseURL, configuration.GitlabAccessToken)
+}
+
+func newGitlabInstanceService(configuration *config.Config,
+	logger *zap.Logger, state *serviceState, instance config.GitProviderInstance) (GitProviderService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+
+	return newGitlabService(configuration, logger, state, "gitlab/"+instance.Name,
+		instance.BaseURL, instance.AccessToken)
+}
+
+// newGitlabService returns a HTTP handler for the Gitlab badge service of a GitLab instance, carrying over its response
+// cache from the given service state
+func newGitlabService(configuration *config.Config, logger *zap.Logger, state *serviceState,
+	name string, baseURL string, accessToken string) (GitProviderService, error) {
 	if logger == nil {
 		return nil, fmt.Errorf("missing logger dependency")
 	}
+	if baseURL == "" {
+		baseURL = config.DefaultGitlabBaseURL
+	}
+
+	cache := loadResponseCache(state, configuration.GitlabCacheTTL, configuration.CacheStaleTTL,
+		name, baseURL, accessToken)
+	service := &gitlabService{
+		name:        name,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		accessToken: accessToken,
+		cache:       cache,
+		client:      &http.Client{Transport: newInstrumentedTransport(name, nil)},
+		config:      configuration,
+		logger:      logger,
+	}
+	handler, err := newProviderService(configuration, logger, name, service.methods())
+	if err != nil {
+		return nil, err
+	}
+	service.handler = handler
 
-	return &gitlabService{
-		name:   "gitlab",
-		config: configuration,
-		logger: logger,
-	}, nil
+	return service, nil
 }
 
 func (service *gitlabService) fetch(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	return service.fetchContext(context.Background(), url)
+}
+
+func (service *gitlabService) fetchContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
+	if service.accessToken != "" {
+		req.Header.Set("PRIVATE-TOKEN", service.accessToken)
+	}
 
-	resp, err := (&http.Client{}).Do(req)
+	resp, err := service.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &upstreamStatusError{statusCode: resp.StatusCode}
+	}
 
 	return resp, err
 }
 
 func (service *gitlabService) getForkCount(owner string, repo string) (int, error) {
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s%%2F%s", owner, repo)
+	url := fmt.Sprintf("%s/projects/%s%%2F%s", service.baseURL, owner, repo)
 	resp, err := service.fetch(url)
 	if err != nil {
 		return 0, err
@@ -95,7 +171,7 @@ func (service *gitlabService) getForkCount(owner string, repo string) (int, erro
 }
 
 func (service *gitlabService) getIssueCount(owner string, repo string, issueState string) (int, error) {
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s%%2F%s/issues", owner, repo)
+	url := fmt.Sprintf("%s/projects/%s%%2F%s/issues", service.baseURL, owner, repo)
 	switch issueState {
 	case "opened":
 		url = fmt.Sprintf("%s?state=opened", url)
@@ -117,8 +193,106 @@ func (service *gitlabService) getIssueCount(owner string, repo string, issueStat
 	return issueCount, nil
 }
 
+func (service *gitlabService) getLatestRelease(owner string, repo string, includePrereleases bool) (string, error) {
+	url := fmt.Sprintf("%s/projects/%s%%2F%s/releases?order_by=released_at&per_page=100", service.baseURL, owner, repo)
+	resp, err := service.fetch(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var gitlabReleases []gitlabReleaseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gitlabReleases); err != nil {
+		return "", err
+	}
+	releases := make([]repositoryRelease, 0, len(gitlabReleases))
+	for _, release := range gitlabReleases {
+		// GitLab has no notion of pre-releases, use the semantic version of the tag instead
+		version, ok := parseSemanticVersion(release.TagName)
+		releases = append(releases, repositoryRelease{
+			tagName:      release.TagName,
+			isDraft:      release.UpcomingRelease,
+			isPrerelease: ok && version.isPrerelease(),
+		})
+	}
+
+	return latestRelease(releases, includePrereleases)
+}
+
+func (service *gitlabService) getLatestTag(owner string, repo string, includePrereleases bool) (string, error) {
+	url := fmt.Sprintf("%s/projects/%s%%2F%s/repository/tags?order_by=updated&per_page=100", service.baseURL, owner, repo)
+	resp, err := service.fetch(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var gitlabTags []gitlabTagResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gitlabTags); err != nil {
+		return "", err
+	}
+	tags := make([]string, 0, len(gitlabTags))
+	for _, tag := range gitlabTags {
+		tags = append(tags, tag.Name)
+	}
+
+	return latestTag(tags, includePrereleases)
+}
+
+// getPipelineStatus returns the status of the latest pipeline on a branch (defaults to the default branch)
+func (service *gitlabService) getPipelineStatus(owner string, repo string, branch string, workflow string) (string, error) {
+	if branch == "" {
+		url := fmt.Sprintf("%s/projects/%s%%2F%s", service.baseURL, owner, repo)
+		resp, err := service.fetch(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		var project gitlabProjectsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+			return "", err
+		}
+		branch = project.DefaultBranch
+	}
+
+	url := fmt.Sprintf("%s/projects/%s%%2F%s/pipelines?ref=%s&per_page=1",
+		service.baseURL, owner, repo, neturl.QueryEscape(branch))
+	resp, err := service.fetch(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var pipelines []gitlabPipelineResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pipelines); err != nil {
+		return "", err
+	}
+	if len(pipelines) == 0 {
+		return "", errNoPipelines
+	}
+
+	return gitlabPipelineStatus(pipelines[0].Status), nil
+}
+
+// gitlabPipelineStatus normalizes the status of a GitLab CI pipeline
+func gitlabPipelineStatus(status string) string {
+	switch status {
+	case "success":
+		return pipelinePassing
+	case "failed":
+		return pipelineFailing
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return pipelineRunning
+	case "canceled":
+		return pipelineCancelled
+	default:
+		return pipelineUnknown
+	}
+}
+
 func (service *gitlabService) getPullRequestCount(owner string, repo string, pullRequestState string) (int, error) {
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s%%2F%s/merge_requests", owner, repo)
+	url := fmt.Sprintf("%s/projects/%s%%2F%s/merge_requests", service.baseURL, owner, repo)
 	switch pullRequestState {
 	case "opened":
 		url = fmt.Sprintf("%s?state=opened", url)
@@ -145,7 +319,7 @@ func (service *gitlabService) getPullRequestCount(owner string, repo string, pul
 }
 
 func (service *gitlabService) getStarCount(owner string, repo string) (int, error) {
-	url := fmt.Sprintf("https://gitlab.com/api/v4/projects/%s%%2F%s", owner, repo)
+	url := fmt.Sprintf("%s/projects/%s%%2F%s", service.baseURL, owner, repo)
 	resp, err := service.fetch(url)
 	if err != nil {
 		return 0, err
@@ -160,148 +334,44 @@ func (service *gitlabService) getStarCount(owner string, repo string) (int, erro
 	return project.StarCount, nil
 }
 
-func (service *gitlabService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	routeVariables := mux.Vars(r)
-	owner := routeVariables["owner"]
-	repo := routeVariables["repo"]
-	method := routeVariables["method"]
-
-	// Fetch data
-	var color, status, subject string
-	var value int
-	var err error
-	switch method {
-	case "forks":
-		subject = "forks"
-		value, err = service.getForkCount(owner, repo)
-	case "issues":
-		state := r.URL.Query().Get("state")
-		switch state {
-		case "":
-			subject = "issues"
-		case "opened":
-			subject = "opened issues"
-		case "closed":
-			subject = "closed issues"
-		default:
-			service.logger.Info("Unsupported state",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.String("state", state))
-			if err := badRequest(w, service.config); err != nil {
-				service.logger.Error("Failed to create error badge",
-					zap.String("url", r.URL.RequestURI()),
-					zap.String("service", service.name),
-					zap.String("method", method),
-					zap.Error(err))
-			}
-			return
-		}
-		value, err = service.getIssueCount(owner, repo, state)
-	case "merge-requests":
-		state := r.URL.Query().Get("state")
-		switch state {
-		case "":
-			subject = "MRs"
-		case "opened":
-			subject = "opened MRs"
-		case "closed":
-			subject = "closed MRs"
-		case "locked":
-			subject = "locked MRs"
-		case "merged":
-			subject = "merged MRs"
-		default:
-			service.logger.Info("Unsupported state",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.String("state", state))
-			if err := badRequest(w, service.config); err != nil {
-				service.logger.Error("Failed to create error badge",
-					zap.String("url", r.URL.RequestURI()),
-					zap.String("service", service.name),
-					zap.String("method", method),
-					zap.Error(err))
-			}
-			return
-		}
-		value, err = service.getPullRequestCount(owner, repo, state)
-	case "stars":
-		subject = "stars"
-		value, err = service.getStarCount(owner, repo)
-	default:
-		service.logger.Info("Unsupported method",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method))
-		if err := notFound(w, service.config); err != nil {
-			service.logger.Error("Failed to create error badge",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.Error(err))
-		}
-		return
+// probe checks that the GitLab API is reachable & the access token (if any) is valid
+func (service *gitlabService) probe(ctx context.Context) error {
+	url := fmt.Sprintf("%s/version", service.baseURL)
+	if service.accessToken == "" {
+		// the version endpoint requires authentication
+		url = fmt.Sprintf("%s/projects?per_page=1&simple=true", service.baseURL)
 	}
+	resp, err := service.fetchContext(ctx, url)
 	if err != nil {
-		service.logger.Error("Failed to fetch data",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-		if err := internalServerError(w, service.config); err != nil {
-			service.logger.Error("Failed to create error badge",
-				zap.String("url", r.URL.RequestURI()),
-				zap.String("service", service.name),
-				zap.String("method", method),
-				zap.Error(err))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		}
-		return
+		return err
 	}
-	status = formatIntegerWithMetricPrefix(value)
 
-	// Overwrite any badge texts
-	if queryColor := r.URL.Query().Get("color"); queryColor != "" {
-		color = queryColor
-	}
-	if queryStatus := r.URL.Query().Get("status"); queryStatus != "" {
-		status = queryStatus
-	}
-	if querySubject := r.URL.Query().Get("subject"); querySubject != "" {
-		subject = querySubject
-	}
+	return resp.Body.Close()
+}
 
-	// Generate badge
-	generatedBadge, err := badge.Create(&badge.Params{
-		Style:   badge.Style(r.URL.Query().Get("style")),
-		Subject: subject,
-		Status:  status,
-		Color:   color,
-		Icon:    r.URL.Query().Get("icon"),
-	})
-	if err != nil {
-		service.logger.Error("Failed to create badge",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
+// methods returns the badge methods supported by the Gitlab badge service
+func (service *gitlabService) methods() map[string]ProviderMethod {
+	return map[string]ProviderMethod{
+		"forks": countMethod(service.cache, service.name, "forks", service.getForkCount),
+		"issues": stateCountMethod(service.cache, service.name, map[string]string{
+			"":       "issues",
+			"opened": "opened issues",
+			"closed": "closed issues",
+		}, service.getIssueCount),
+		"merge-requests": stateCountMethod(service.cache, service.name, map[string]string{
+			"":       "MRs",
+			"opened": "opened MRs",
+			"closed": "closed MRs",
+			"locked": "locked MRs",
+			"merged": "merged MRs",
+		}, service.getPullRequestCount),
+		"pipeline": pipelineMethod(service.cache, service.name, false, service.getPipelineStatus),
+		"release":  versionMethod(service.cache, service.name, "release", service.getLatestRelease),
+		"stars":    countMethod(service.cache, service.name, "stars", service.getStarCount),
+		"tag":      versionMethod(service.cache, service.name, "tag", service.getLatestTag),
 	}
+}
 
-	if !service.config.ExcludeCacheControlHeaders {
-		// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
-		w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
-	}
-	w.Header().Set("Content-Type", "image/svg+xml;utf-8")
-	_, err = w.Write([]byte(generatedBadge))
-	if err != nil {
-		service.logger.Error("Failed to write HTTP response",
-			zap.String("url", r.URL.RequestURI()),
-			zap.String("service", service.name),
-			zap.String("method", method),
-			zap.Error(err))
-	}
+func (service *gitlabService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	service.handler.ServeHTTP(w, r)
 }
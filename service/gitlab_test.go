@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+func newMockGitlabServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "testToken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/api/v4/version":
+			_, _ = w.Write([]byte(`{"version": "17.0.0"}`))
+		case "/api/v4/projects/owner%2Frepo":
+			_, _ = w.Write([]byte(`{"id": 1, "star_count": 1234, "forks_count": 56, "default_branch": "main"}`))
+		case "/api/v4/projects/owner%2Frepo/issues":
+			w.Header().Set("X-Total", "78")
+			_, _ = w.Write([]byte(`[]`))
+		case "/api/v4/projects/owner%2Frepo/pipelines":
+			switch r.URL.Query().Get("ref") {
+			case "main":
+				_, _ = w.Write([]byte(`[{"id": 2, "status": "success"}]`))
+			case "dev":
+				_, _ = w.Write([]byte(`[{"id": 3, "status": "canceled"}]`))
+			default:
+				_, _ = w.Write([]byte(`[]`))
+			}
+		case "/api/v4/projects/owner%2Frepo/releases":
+			_, _ = w.Write([]byte(`[{"tag_name": "v2.0.0-rc.1"}, {"tag_name": "v1.10.0"}, {"tag_name": "v1.9.0"}]`))
+		case "/api/v4/projects/owner%2Frepo/repository/tags":
+			_, _ = w.Write([]byte(`[{"name": "v1.9.1"}, {"name": "v1.10.0"}, {"name": "nightly"}]`))
+		case "/api/v4/projects/owner%2Fempty/releases":
+			_, _ = w.Write([]byte(`[]`))
+		case "/api/v4/projects/owner%2Fforbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case "/api/v4/projects/owner%2Fbroken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGitlabBadgeService(t *testing.T) {
+	t.Parallel()
+
+	upstream := newMockGitlabServer(t)
+	service, err := NewGitlabService(&config.Config{
+		GitlabBaseURL:     upstream.URL + "/api/v4",
+		GitlabAccessToken: "testToken",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unauthorizedService, err := NewGitlabService(&config.Config{
+		GitlabBaseURL: upstream.URL + "/api/v4",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name         string
+		service      GitProviderService
+		requestPath  string
+		expectedBody string
+	}{
+		{
+			name:         "Stars",
+			service:      service,
+			requestPath:  "/stars/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "stars", Status: "1.23k"}),
+		},
+		{
+			name:         "Pipeline",
+			service:      service,
+			requestPath:  "/pipeline/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "passing", Color: "#4c1"}),
+		},
+		{
+			name:         "PipelineBranch",
+			service:      service,
+			requestPath:  "/pipeline/owner/repo?branch=dev",
+			expectedBody: createBadge(&badge.Params{Subject: "pipeline", Status: "cancelled", Color: "#9f9f9f"}),
+		},
+		{
+			name:         "NoPipelines",
+			service:      service,
+			requestPath:  "/pipeline/owner/repo?branch=feature",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no pipelines"}),
+		},
+		{
+			name:         "Release",
+			service:      service,
+			requestPath:  "/release/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "release", Status: "v1.10.0", Color: "#007ec6"}),
+		},
+		{
+			name:         "PreRelease",
+			service:      service,
+			requestPath:  "/release/owner/repo?include_prereleases",
+			expectedBody: createBadge(&badge.Params{Subject: "release", Status: "v2.0.0-rc.1", Color: "#fe7d37"}),
+		},
+		{
+			name:         "NoReleases",
+			service:      service,
+			requestPath:  "/release/owner/empty",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "no releases"}),
+		},
+		{
+			name:         "Tag",
+			service:      service,
+			requestPath:  "/tag/owner/repo?prefix=none",
+			expectedBody: createBadge(&badge.Params{Subject: "tag", Status: "1.10.0", Color: "#007ec6"}),
+		},
+		{
+			name:         "InvalidVersionPrefix",
+			service:      service,
+			requestPath:  "/tag/owner/repo?prefix=x",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "bad request"}),
+		},
+		{
+			name:         "Unauthorized",
+			service:      unauthorizedService,
+			requestPath:  "/stars/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "unauthorized"}),
+		},
+		{
+			name:         "Forbidden",
+			service:      service,
+			requestPath:  "/stars/owner/forbidden",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "forbidden"}),
+		},
+		{
+			name:         "RepoNotFound",
+			service:      service,
+			requestPath:  "/stars/owner/missing",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "repo not found"}),
+		},
+		{
+			name:         "UpstreamError",
+			service:      service,
+			requestPath:  "/stars/owner/broken",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "internal server error"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			res := serveHTTPRequest(t, newGitProviderRouter(testCase.service), testCase.requestPath)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+}
+
+func TestGitlabInstanceBadgeService(t *testing.T) {
+	t.Parallel()
+
+	upstream := newMockGitlabServer(t)
+	mockLogger := zaptest.NewLogger(t)
+	mockConfig := &config.Config{}
+	instanceService, err := NewGitlabInstanceService(mockConfig, mockLogger, config.GitProviderInstance{
+		Name:        "corp",
+		BaseURL:     upstream.URL + "/api/v4/",
+		AccessToken: "testToken",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewGitProviderInstanceService(mockConfig, mockLogger, "gitlab",
+		map[string]GitProviderService{"corp": instanceService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := mux.NewRouter()
+	router.UseEncodedPath()
+	router.Handle(`/gitlab/{instance}/{method}/{owner}/{repo}`, service)
+
+	testCases := []struct {
+		requestPath  string
+		expectedBody string
+	}{
+		{
+			requestPath:  "/gitlab/corp/stars/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "stars", Status: "1.23k"}),
+		},
+		{
+			requestPath:  "/gitlab/corp/forks/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "forks", Status: "56"}),
+		},
+		{
+			requestPath:  "/gitlab/corp/issues/owner/repo?format=json",
+			expectedBody: `{"subject":"issues","status":"78","color":"#f7b137","style":"classic","value":78}`,
+		},
+		{
+			requestPath:  "/gitlab/unknown/stars/owner/repo",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "service not found"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.requestPath, func(t *testing.T) {
+			res := serveHTTPRequest(t, router, testCase.requestPath)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+}
+
+func TestGitlabProbe(t *testing.T) {
+	t.Parallel()
+
+	upstream := newMockGitlabServer(t)
+	service, err := NewGitlabService(&config.Config{
+		GitlabBaseURL:     upstream.URL + "/api/v4",
+		GitlabAccessToken: "testToken",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredTokenService, err := NewGitlabService(&config.Config{
+		GitlabBaseURL:     upstream.URL + "/api/v4",
+		GitlabAccessToken: "expiredToken",
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, service.probe(context.Background()))
+	assert.EqualError(t, expiredTokenService.probe(context.Background()),
+		"unexpected status code from upstream: 401 Unauthorized")
+}
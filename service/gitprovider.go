@@ -0,0 +1,137 @@
+package service
+
+// gitProviderCacheKey returns the cache key of a badge method request to a git provider
+func gitProviderCacheKey(provider string, request ProviderRequest, state string) cacheKey {
+	return cacheKey{
+		provider: provider,
+		method:   request.Method,
+		owner:    request.Vars["owner"],
+		repo:     request.Vars["repo"],
+		state:    state,
+	}
+}
+
+// countMethod returns a badge method displaying a count
+func countMethod(cache *responseCache, provider string, subject string,
+	fetch func(owner string, repo string) (int, error)) ProviderMethod {
+	return ProviderMethod{
+		Fetch: func(request ProviderRequest) (ProviderResult, error) {
+			owner, repo := request.Vars["owner"], request.Vars["repo"]
+			count, err := cachedFetch(cache, gitProviderCacheKey(provider, request, ""), func() (int, error) {
+				return fetch(owner, repo)
+			})
+			if err != nil {
+				return ProviderResult{}, err
+			}
+
+			return ProviderResult{
+				Subject: subject,
+				Status:  formatIntegerWithMetricPrefix(count),
+				Value:   count,
+			}, nil
+		},
+	}
+}
+
+// stateCountMethod returns a badge method displaying a count filtered by the `state` query parameter,
+// `subjects` maps the supported states onto badge subjects (the empty state being the unfiltered count)
+func stateCountMethod(cache *responseCache, provider string, subjects map[string]string,
+	fetch func(owner string, repo string, state string) (int, error)) ProviderMethod {
+	var states []string
+	for state := range subjects {
+		if state != "" {
+			states = append(states, state)
+		}
+	}
+
+	return ProviderMethod{
+		QueryParams: map[string][]string{"state": states},
+		Fetch: func(request ProviderRequest) (ProviderResult, error) {
+			owner, repo := request.Vars["owner"], request.Vars["repo"]
+			state := request.Query.Get("state")
+			count, err := cachedFetch(cache, gitProviderCacheKey(provider, request, state), func() (int, error) {
+				return fetch(owner, repo, state)
+			})
+			if err != nil {
+				return ProviderResult{}, err
+			}
+
+			return ProviderResult{
+				Subject: subjects[state],
+				Status:  formatIntegerWithMetricPrefix(count),
+				Value:   count,
+			}, nil
+		},
+	}
+}
+
+// pipelineMethod returns a badge method displaying the status of the latest CI pipeline on a branch,
+// optionally filtered by workflow
+func pipelineMethod(cache *responseCache, provider string, supportsWorkflows bool,
+	fetch func(owner string, repo string, branch string, workflow string) (string, error)) ProviderMethod {
+	queryParams := map[string][]string{"branch": nil}
+	if supportsWorkflows {
+		queryParams["workflow"] = nil
+	}
+
+	return ProviderMethod{
+		QueryParams: queryParams,
+		Fetch: func(request ProviderRequest) (ProviderResult, error) {
+			owner, repo := request.Vars["owner"], request.Vars["repo"]
+			branch := request.Query.Get("branch")
+			var workflow string
+			if supportsWorkflows {
+				workflow = request.Query.Get("workflow")
+			}
+			key := gitProviderCacheKey(provider, request, pipelineCacheState(branch, workflow))
+			status, err := cachedFetch(cache, key, func() (string, error) {
+				return fetch(owner, repo, branch, workflow)
+			})
+			if err != nil {
+				return ProviderResult{}, err
+			}
+
+			return ProviderResult{
+				Subject: "pipeline",
+				Status:  status,
+				Color:   pipelineColors[status],
+				Value:   status,
+			}, nil
+		},
+	}
+}
+
+// versionMethod returns a badge method displaying the latest version of a repository
+func versionMethod(cache *responseCache, provider string, subject string,
+	fetch func(owner string, repo string, includePrereleases bool) (string, error)) ProviderMethod {
+	return ProviderMethod{
+		QueryParams: map[string][]string{
+			"include_prereleases": {"true", "1", "false", "0"},
+			"prefix":              {"v", "none"},
+		},
+		Fetch: func(request ProviderRequest) (ProviderResult, error) {
+			owner, repo := request.Vars["owner"], request.Vars["repo"]
+			options, err := parseVersionQuery(request.Query)
+			if err != nil {
+				return ProviderResult{}, err
+			}
+			var state string
+			if options.includePrereleases {
+				state = "prereleases"
+			}
+			version, err := cachedFetch(cache, gitProviderCacheKey(provider, request, state), func() (string, error) {
+				return fetch(owner, repo, options.includePrereleases)
+			})
+			if err != nil {
+				return ProviderResult{}, err
+			}
+
+			return ProviderResult{
+				Subject: subject,
+				Status:  formatVersion(version, options.prefix),
+				Color:   versionColor(version),
+				Value:   version,
+			}, nil
+		},
+	}
+}
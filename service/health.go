@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/tohjustin/aegis/service/config"
+)
+
+const (
+	// livenessPath is the route reporting whether the process is alive
+	livenessPath = "/healthz"
+	// readinessPath is the route reporting whether the application is ready to serve requests
+	readinessPath = "/readyz"
+)
+
+const (
+	// readinessProbeTimeout is the maximum duration of a provider probe
+	readinessProbeTimeout = 5 * time.Second
+	// readinessProbeTTL is the duration to reuse the results of provider probes, so that frequent
+	// readiness checks don't exhaust the rate limits of upstream services
+	readinessProbeTTL = 30 * time.Second
+)
+
+const (
+	healthOK      = "ok"
+	healthFailing = "failing"
+)
+
+// healthCheck represents the result of a single readiness check
+type healthCheck struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// healthReport represents the JSON response of the health endpoints
+type healthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]healthCheck `json:"checks,omitempty"`
+}
+
+type healthService struct {
+	ready     *atomic.Bool
+	providers []Provider
+	config    *config.Config
+	logger    *zap.Logger
+
+	mutex       sync.Mutex
+	probedAt    time.Time
+	probeChecks map[string]healthCheck
+}
+
+// newHealthService returns a service serving the liveness & readiness endpoints of the application,
+// probing the upstream services of the given providers in readiness checks if configured
+func newHealthService(configuration *config.Config, logger *zap.Logger,
+	ready *atomic.Bool, providers []Provider) (*healthService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("missing logger dependency")
+	}
+
+	var probedProviders []Provider
+	for _, provider := range providers {
+		if provider.Probe != nil {
+			probedProviders = append(probedProviders, provider)
+		}
+	}
+
+	return &healthService{
+		ready:     ready,
+		providers: probedProviders,
+		config:    configuration,
+		logger:    logger,
+	}, nil
+}
+
+// probe probes the upstream services of all providers concurrently, reusing recent results
+func (service *healthService) probe(ctx context.Context) map[string]healthCheck {
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	if service.probeChecks != nil && time.Since(service.probedAt) < readinessProbeTTL {
+		return service.probeChecks
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(service.providers))
+	for i, provider := range service.providers {
+		wg.Add(1)
+		go func(i int, provider Provider) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, readinessProbeTimeout)
+			defer cancel()
+			errs[i] = provider.Probe(probeCtx)
+		}(i, provider)
+	}
+	wg.Wait()
+
+	checks := make(map[string]healthCheck, len(service.providers))
+	for i, provider := range service.providers {
+		if errs[i] != nil {
+			service.logger.Warn("Provider probe failed",
+				zap.String("service", provider.Name),
+				zap.Error(errs[i]))
+			checks[provider.Name] = healthCheck{Status: healthFailing, Error: errs[i].Error()}
+			continue
+		}
+		checks[provider.Name] = healthCheck{Status: healthOK}
+	}
+	// don't reuse results of probes interrupted by the readiness request itself
+	if ctx.Err() == nil {
+		service.probedAt = time.Now()
+		service.probeChecks = checks
+	}
+
+	return checks
+}
+
+// liveness reports that the process is alive
+func (service *healthService) liveness(w http.ResponseWriter, r *http.Request) {
+	service.writeReport(w, r, http.StatusOK, healthReport{Status: healthOK})
+}
+
+// readiness reports whether the application is accepting requests, its configuration is valid & (if
+// configured) the upstream services of all providers are reachable
+func (service *healthService) readiness(w http.ResponseWriter, r *http.Request) {
+	report := healthReport{Status: healthOK, Checks: make(map[string]healthCheck)}
+
+	report.Checks["server"] = healthCheck{Status: healthOK}
+	if !service.ready.Load() {
+		report.Checks["server"] = healthCheck{Status: healthFailing, Error: "not accepting requests"}
+	}
+	report.Checks["config"] = healthCheck{Status: healthOK}
+	if err := service.config.Validate(); err != nil {
+		report.Checks["config"] = healthCheck{Status: healthFailing, Error: err.Error()}
+	}
+	if service.config.ReadinessProbes {
+		for name, check := range service.probe(r.Context()) {
+			report.Checks[name] = check
+		}
+	}
+
+	statusCode := http.StatusOK
+	for _, check := range report.Checks {
+		if check.Status != healthOK {
+			report.Status = healthFailing
+			statusCode = http.StatusServiceUnavailable
+			break
+		}
+	}
+	service.writeReport(w, r, statusCode, report)
+}
+
+func (service *healthService) writeReport(w http.ResponseWriter, r *http.Request,
+	statusCode int, report healthReport) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		service.logger.Error("Failed to create health report",
+			zap.String("url", r.URL.RequestURI()),
+			zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		service.logger.Error("Failed to write HTTP response",
+			zap.String("url", r.URL.RequestURI()),
+			zap.Error(err))
+	}
+}
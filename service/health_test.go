@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// newMockHealthApplication returns an application serving providers with the given probe results
+func newMockHealthApplication(t *testing.T, configuration *config.Config,
+	probes map[string]func(ctx context.Context) error) *Application {
+	app := &Application{
+		logger:   zaptest.NewLogger(t),
+		config:   configuration,
+		registry: NewRegistry(),
+	}
+	for name, probe := range probes {
+		if err := app.RegisterProvider(Provider{
+			Name:    name,
+			Routes:  []string{"/" + name},
+			Handler: http.NotFoundHandler(),
+			Probe:   probe,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return app
+}
+
+func TestLiveness(t *testing.T) {
+	t.Parallel()
+
+	app := newMockHealthApplication(t, &config.Config{}, nil)
+	res := serveHTTPRequest(t, app.handler(), "/healthz")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+	assert.Empty(t, res.Header().Get("Content-Security-Policy"))
+	assert.JSONEq(t, `{"status":"ok"}`, res.Body.String())
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	var probeCount atomic.Int32
+	probes := map[string]func(ctx context.Context) error{
+		"healthy": func(ctx context.Context) error {
+			probeCount.Add(1)
+			return nil
+		},
+		"unhealthy": func(ctx context.Context) error {
+			return fmt.Errorf("unexpected status code from upstream: 401 Unauthorized")
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		config         *config.Config
+		ready          bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "ready",
+			config:         &config.Config{},
+			ready:          true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok","checks":{"config":{"status":"ok"},"server":{"status":"ok"}}}`,
+		},
+		{
+			name:           "shutting down",
+			config:         &config.Config{},
+			ready:          false,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"failing","checks":{"config":{"status":"ok"},` +
+				`"server":{"status":"failing","error":"not accepting requests"}}}`,
+		},
+		{
+			name:           "invalid config",
+			config:         &config.Config{CoverageUploadToken: "token"},
+			ready:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"failing","checks":{"config":{"status":"failing",` +
+				`"error":"Config.CoverageUploadToken requires Config.CoverageDir to be set"},"server":{"status":"ok"}}}`,
+		},
+		{
+			name:           "failing probe",
+			config:         &config.Config{ReadinessProbes: true},
+			ready:          true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"failing","checks":{"config":{"status":"ok"},"server":{"status":"ok"},` +
+				`"healthy":{"status":"ok"},` +
+				`"unhealthy":{"status":"failing","error":"unexpected status code from upstream: 401 Unauthorized"}}}`,
+		},
+	}
+	for _, testCase := range testCases {
+		app := newMockHealthApplication(t, testCase.config, probes)
+		app.ready.Store(testCase.ready)
+
+		res := serveHTTPRequest(t, app.handler(), "/readyz")
+		assert.Equal(t, testCase.expectedStatus, res.Code, testCase.name)
+		assert.JSONEq(t, testCase.expectedBody, res.Body.String(), testCase.name)
+	}
+	assert.Equal(t, int32(1), probeCount.Load())
+}
+
+func TestReadinessProbeResultsAreReused(t *testing.T) {
+	t.Parallel()
+
+	var probeCount atomic.Int32
+	app := newMockHealthApplication(t, &config.Config{ReadinessProbes: true},
+		map[string]func(ctx context.Context) error{
+			"healthy": func(ctx context.Context) error {
+				probeCount.Add(1)
+				return nil
+			},
+		})
+	app.ready.Store(true)
+	handler := app.handler()
+
+	for i := 0; i < 3; i++ {
+		res := serveHTTPRequest(t, handler, "/readyz")
+		assert.Equal(t, http.StatusOK, res.Code)
+	}
+	assert.Equal(t, int32(1), probeCount.Load())
+}
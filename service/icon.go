@@ -0,0 +1,30 @@
+package service
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+// iconExt is the file extension of SVG icons
+const iconExt = ".svg"
+
+// customIconNamespace is the namespace of icons registered from the icon directory (eg. "custom/our-logo")
+const customIconNamespace = "custom/"
+
+// loadIcons validates the SVG icons in the given directory as icons named after their files
+// (eg. "<DIR>/our-logo.svg" as the "custom/our-logo" icon), returning the valid icons by name
+func loadIcons(dir string) (map[string]string, error) {
+	icons := make(map[string]string)
+	err := registerDir(dir, "*"+iconExt, func(path string, content []byte) error {
+		name := customIconNamespace + strings.TrimSuffix(filepath.Base(path), iconExt)
+		if err := badge.ValidateIcon(name, string(content)); err != nil {
+			return err
+		}
+		icons[name] = string(content)
+		return nil
+	})
+
+	return icons, err
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+func TestLoadIcons(t *testing.T) {
+	t.Parallel()
+
+	dir := newTestDir(t, map[string]string{
+		"service-logo.svg":   `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><path d="M0 0h16v16H0z"/></svg>`,
+		"service-script.svg": `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><script>alert(1)</script></svg>`,
+		"Service Logo.svg":   `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"></svg>`,
+		"service-text.svg":   "not an icon",
+		"README.md":          "not an icon",
+	})
+
+	icons, err := loadIcons(dir)
+	assert.ErrorContains(t, err, filepath.Join(dir, "Service Logo.svg")+`: Invalid icon name: "custom/Service Logo"`)
+	assert.ErrorContains(t, err, filepath.Join(dir, "service-text.svg")+": Invalid icon custom/service-text")
+	assert.ElementsMatch(t, []string{"custom/service-logo", "custom/service-script"}, slices.Collect(maps.Keys(icons)))
+	assert.False(t, badge.HasIcon("custom/service-logo"))
+
+	_, err = loadIcons(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
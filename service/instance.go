@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/tohjustin/aegis/service/config"
+)
+
+type gitProviderInstanceService struct {
+	name      string
+	instances map[string]GitProviderService
+	config    *config.Config
+	logger    *zap.Logger
+}
+
+// NewGitProviderInstanceService returns a HTTP handler routing requests to the badge service of
+// the requested git provider instance
+func NewGitProviderInstanceService(configuration *config.Config, logger *zap.Logger,
+	name string, instances map[string]GitProviderService) (BadgeService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("missing logger dependency")
+	}
+
+	return &gitProviderInstanceService{
+		name:      name,
+		instances: instances,
+		config:    configuration,
+		logger:    logger,
+	}, nil
+}
+
+func (service *gitProviderInstanceService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	instance := mux.Vars(r)["instance"]
+	instanceService, ok := service.instances[instance]
+	if !ok {
+		service.logger.Info("Unsupported instance",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("instance", instance))
+		if err := serviceNotFound(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.String("instance", instance),
+				zap.Error(err))
+		}
+		return
+	}
+
+	instanceService.ServeHTTP(w, r)
+}
+
+// probeInstances returns a readiness probe checking every given git provider instance
+func probeInstances(instances map[string]GitProviderService) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		names := make([]string, 0, len(instances))
+		for name := range instances {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var errs []error
+		for _, name := range names {
+			if err := instances[name].probe(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
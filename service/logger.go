@@ -1,33 +1,19 @@
 package service
 
 import (
-	"flag"
-
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-const (
-	logLevelCfg = "log-level"
-)
-
-var (
-	// Command line pointer to logger level flag configuration.
-	loggerLevelPtr *string
-)
-
-func loggerFlags(flags *flag.FlagSet) {
-	loggerLevelPtr = flags.String(logLevelCfg, "INFO",
-		"Output level of logs (DEBUG, INFO, WARN, ERROR, DPANIC, PANIC, FATAL)")
-}
-
-func newLogger() (*zap.Logger, error) {
+// newLogger returns a logger & its level, which can be changed while the application is running
+func newLogger(logLevel string) (*zap.Logger, zap.AtomicLevel, error) {
 	var level zapcore.Level
-	err := (&level).UnmarshalText([]byte(*loggerLevelPtr))
+	err := (&level).UnmarshalText([]byte(logLevel))
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 	conf := zap.NewProductionConfig()
 	conf.Level.SetLevel(level)
-	return conf.Build()
+	logger, err := conf.Build()
+	return logger, conf.Level, err
 }
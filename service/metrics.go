@@ -0,0 +1,148 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsPath is the route exposing Prometheus metrics
+const metricsPath = "/metrics"
+
+// unmatchedRouteName is the route name of requests that don't match any provider
+const unmatchedRouteName = "unmatched"
+
+// metricsRegistry holds all metrics of the application
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aegis",
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by service, method & status code.",
+	}, []string{"service", "method", "code"})
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aegis",
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by service & method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method"})
+	upstreamRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aegis",
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of requests to upstream services by provider & API.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "api"})
+	upstreamErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aegis",
+		Name:      "upstream_errors_total",
+		Help:      "Number of failed requests (transport errors & 4xx/5xx responses) to upstream services by provider & API.",
+	}, []string{"provider", "api"})
+	badgeRenderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aegis",
+		Name:      "badge_render_duration_seconds",
+		Help:      "Duration of badge rendering by format.",
+		Buckets:   []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1},
+	}, []string{"format"})
+	cacheRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aegis",
+		Name:      "cache_requests_total",
+		Help:      "Number of response cache lookups by provider & result (hit, stale, shared or miss).",
+	}, []string{"provider", "result"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		upstreamRequestDuration,
+		upstreamErrorsTotal,
+		badgeRenderDuration,
+		cacheRequestsTotal,
+	)
+}
+
+// metricsHandler returns a HTTP handler exposing the application metrics
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// statusRecorder records the status code of a HTTP response
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+// metricsMiddleware records request counts & latencies, labelled by the name of the matched route
+// (ie. the provider name) & its method. `methods` lists the known methods of each provider to bound
+// the cardinality of the method label.
+func metricsMiddleware(methods map[string]map[string]bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			service := unmatchedRouteName
+			if route := mux.CurrentRoute(r); route != nil && route.GetName() != "" {
+				service = route.GetName()
+			}
+			if service == metricsPath || service == livenessPath || service == readinessPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+			method := mux.Vars(r)["method"]
+			if !methods[service][method] {
+				method = ""
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			start := time.Now()
+			next.ServeHTTP(recorder, r)
+
+			requestDuration.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+			requestsTotal.WithLabelValues(service, method, strconv.Itoa(recorder.statusCode)).Inc()
+		})
+	}
+}
+
+// instrumentedTransport records latencies & errors of requests to an upstream service
+type instrumentedTransport struct {
+	provider string
+	next     http.RoundTripper
+}
+
+// newInstrumentedTransport wraps a HTTP transport (defaults to `http.DefaultTransport`) to record
+// metrics of requests to an upstream provider
+func newInstrumentedTransport(provider string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &instrumentedTransport{provider: provider, next: next}
+}
+
+func (transport *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api := "rest"
+	if strings.HasSuffix(req.URL.Path, "/graphql") {
+		api = "graphql"
+	}
+
+	start := time.Now()
+	resp, err := transport.next.RoundTrip(req)
+	upstreamRequestDuration.WithLabelValues(transport.provider, api).Observe(time.Since(start).Seconds())
+	if err != nil || resp.StatusCode >= 400 {
+		upstreamErrorsTotal.WithLabelValues(transport.provider, api).Inc()
+	}
+
+	return resp, err
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/service/config"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		logger:   zaptest.NewLogger(t),
+		config:   &config.Config{},
+		registry: NewRegistry(),
+	}
+	if err := app.RegisterProvider(newMockProvider()); err != nil {
+		t.Fatal(err)
+	}
+	handler := app.handler()
+
+	serveHTTPRequest(t, handler, "/internal/deployments/aegis")
+	serveHTTPRequest(t, handler, "/internal/deployments/aegis.png")
+	serveHTTPRequest(t, handler, "/internal/random-method/aegis")
+	serveHTTPRequest(t, handler, "/unknown-service")
+
+	res := serveHTTPRequest(t, handler, "/metrics")
+	assert.Equal(t, http.StatusOK, res.Code)
+	body := res.Body.String()
+	assert.Contains(t, body, `aegis_http_requests_total{code="200",method="deployments",service="internal"}`)
+	assert.Contains(t, body, `aegis_http_requests_total{code="200",method="",service="internal"}`)
+	assert.Contains(t, body, `aegis_http_requests_total{code="200",method="",service="unmatched"}`)
+	assert.Contains(t, body, `aegis_http_request_duration_seconds_count{method="deployments",service="internal"}`)
+	assert.Contains(t, body, `aegis_badge_render_duration_seconds_count{format="png"}`)
+	assert.NotContains(t, body, `random-method`)
+	assert.NotContains(t, body, `service="/metrics"`)
+}
+
+func TestInstrumentedTransport(t *testing.T) {
+	t.Parallel()
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	client := &http.Client{Transport: newInstrumentedTransport("test-transport", nil)}
+
+	for _, path := range []string{"/graphql", "/repos/owner/repo"} {
+		resp, err := client.Get(upstream.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	res := serveHTTPRequest(t, metricsHandler(), "/metrics")
+	body := res.Body.String()
+	assert.Contains(t, body, `aegis_upstream_request_duration_seconds_count{api="graphql",provider="test-transport"} 1`)
+	assert.Contains(t, body, `aegis_upstream_request_duration_seconds_count{api="rest",provider="test-transport"} 1`)
+	assert.Contains(t, body, `aegis_upstream_errors_total{api="graphql",provider="test-transport"} 1`)
+	assert.NotContains(t, body, `aegis_upstream_errors_total{api="rest",provider="test-transport"}`)
+}
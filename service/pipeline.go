@@ -0,0 +1,31 @@
+package service
+
+import (
+	"errors"
+)
+
+// errNoPipelines is returned when a repository has no (matching) pipeline runs
+var errNoPipelines = errors.New("no pipelines found")
+
+// List of normalized pipeline statuses
+const (
+	pipelinePassing   = "passing"
+	pipelineFailing   = "failing"
+	pipelineRunning   = "running"
+	pipelineCancelled = "cancelled"
+	pipelineUnknown   = "unknown"
+)
+
+// pipelineColors maps normalized pipeline statuses onto badge colors
+var pipelineColors = map[string]string{
+	pipelinePassing:   "#4c1",
+	pipelineFailing:   "#e05d44",
+	pipelineRunning:   "#dfb317",
+	pipelineCancelled: "#9f9f9f",
+	pipelineUnknown:   "#9f9f9f",
+}
+
+// pipelineCacheState returns the cache key state of a pipeline status query
+func pipelineCacheState(branch string, workflow string) string {
+	return branch + "\x00" + workflow
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// Provider describes a badge provider served by the application
+type Provider struct {
+	// Name of the provider (eg. "github"), must be unique
+	Name string
+	// Routes lists the route patterns served by the provider (eg. "/github/{method}/{owner}/{repo}").
+	// Route patterns of providers with methods must contain a `{method}` variable.
+	Routes []string
+	// Methods maps the `{method}` route variable onto the badge methods of the provider
+	Methods map[string]ProviderMethod
+	// Handler serves all requests of the provider, used instead of methods
+	Handler http.Handler
+	// HTTPMethods lists the HTTP methods accepted by the handler (defaults to GET)
+	HTTPMethods []string
+	// Probe checks the upstream service of the provider in readiness checks (optional)
+	Probe func(ctx context.Context) error
+}
+
+// ProviderMethod describes a badge method of a provider
+type ProviderMethod struct {
+	// QueryParams maps the query parameters accepted by the method onto their allowed values (any value
+	// is allowed if there are none), in addition to the common `color`, `icon`, `status`, `style`,
+	// `subject` & `format` query parameters
+	QueryParams map[string][]string
+	// Fetch fetches the data of the badge
+	Fetch func(request ProviderRequest) (ProviderResult, error)
+}
+
+// ProviderRequest represents a request to a badge method
+type ProviderRequest struct {
+	Context context.Context
+	Method  string
+	// Vars holds the route variables, without any badge format suffix
+	Vars  map[string]string
+	Query url.Values
+}
+
+// ProviderResult represents the data fetched by a badge method
+type ProviderResult struct {
+	Subject string
+	Status  string
+	Color   string
+	// Value is the raw value of the badge, only included in JSON badges
+	Value interface{}
+}
+
+// Registry holds the badge providers served by the application
+type Registry struct {
+	providers []Provider
+}
+
+// NewRegistry returns an empty provider registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a provider to the registry
+func (registry *Registry) Register(provider Provider) error {
+	if provider.Name == "" {
+		return fmt.Errorf("missing provider name")
+	}
+	if len(provider.Routes) == 0 {
+		return fmt.Errorf("missing routes for provider %q", provider.Name)
+	}
+	if (provider.Handler == nil) == (len(provider.Methods) == 0) {
+		return fmt.Errorf("provider %q must have either methods or a handler", provider.Name)
+	}
+	if provider.Handler == nil && len(provider.HTTPMethods) > 0 {
+		return fmt.Errorf("HTTP methods of provider %q require a handler", provider.Name)
+	}
+	for name, method := range provider.Methods {
+		if method.Fetch == nil {
+			return fmt.Errorf("missing fetch function for method %q of provider %q", name, provider.Name)
+		}
+	}
+	for _, route := range provider.Routes {
+		if provider.Handler == nil && !strings.Contains(route, "{method}") {
+			return fmt.Errorf("route %q of provider %q is missing a {method} variable", route, provider.Name)
+		}
+	}
+
+	for _, registered := range registry.providers {
+		if registered.Name == provider.Name {
+			return fmt.Errorf("provider %q is already registered", provider.Name)
+		}
+		for _, registeredRoute := range registered.Routes {
+			for _, route := range provider.Routes {
+				if registeredRoute == route {
+					return fmt.Errorf("route %q of provider %q is already registered by provider %q",
+						route, provider.Name, registered.Name)
+				}
+			}
+		}
+	}
+	registry.providers = append(registry.providers, provider)
+
+	return nil
+}
+
+// Providers returns the registered providers in order of registration
+func (registry *Registry) Providers() []Provider {
+	return append([]Provider(nil), registry.providers...)
+}
+
+// httpMethods returns the HTTP methods accepted on the routes of a provider
+func (provider Provider) httpMethods() []string {
+	if len(provider.HTTPMethods) == 0 {
+		return []string{http.MethodGet}
+	}
+
+	return provider.HTTPMethods
+}
+
+// MethodNames returns the sorted names of the badge methods of a provider
+func (provider Provider) MethodNames() []string {
+	names := make([]string, 0, len(provider.Methods))
+	for name := range provider.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type providerService struct {
+	name    string
+	methods map[string]ProviderMethod
+	config  *config.Config
+	logger  *zap.Logger
+}
+
+// newProviderService returns a HTTP handler serving the badge methods of a provider
+func newProviderService(configuration *config.Config, logger *zap.Logger,
+	name string, methods map[string]ProviderMethod) (BadgeService, error) {
+	if configuration == nil {
+		return nil, fmt.Errorf("missing config dependency")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("missing logger dependency")
+	}
+
+	return &providerService{
+		name:    name,
+		methods: methods,
+		config:  configuration,
+		logger:  logger,
+	}, nil
+}
+
+// validateQuery checks the query parameters of a request against the allowed values of a method
+func (method ProviderMethod) validateQuery(query url.Values) error {
+	for name, allowedValues := range method.QueryParams {
+		value := query.Get(name)
+		if value == "" || len(allowedValues) == 0 {
+			continue
+		}
+		allowed := false
+		for _, allowedValue := range allowedValues {
+			if value == allowedValue {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("unsupported %s value: %s", name, value)
+		}
+	}
+
+	return nil
+}
+
+func (service *providerService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	routeVariables := routeVariables(r)
+	methodName := routeVariables["method"]
+
+	format, err := requestFormat(r)
+	if err != nil {
+		service.logger.Info("Unsupported format",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("method", methodName),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.String("method", methodName),
+				zap.Error(err))
+		}
+		return
+	}
+
+	method, ok := service.methods[methodName]
+	if !ok {
+		service.logger.Info("Unsupported method",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("method", methodName))
+		if err := notFound(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.String("method", methodName),
+				zap.Error(err))
+		}
+		return
+	}
+	if err := method.validateQuery(r.URL.Query()); err != nil {
+		service.logger.Info("Unsupported query",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("method", methodName),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.String("method", methodName),
+				zap.Error(err))
+		}
+		return
+	}
+
+	// Fetch data
+	result, err := method.Fetch(ProviderRequest{
+		Context: r.Context(),
+		Method:  methodName,
+		Vars:    routeVariables,
+		Query:   r.URL.Query(),
+	})
+	if err != nil {
+		service.logger.Error("Failed to fetch data",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("method", methodName),
+			zap.Error(err))
+		if err := upstreamError(w, r, service.config, err); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.String("method", methodName),
+				zap.Error(err))
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Generate badge, overwriting any badge texts
+	params := queryParams(r.URL.Query(), badge.Params{Subject: result.Subject, Status: result.Status, Color: result.Color})
+	generatedBadge, contentType, err := renderBadge(format, params, result.Value)
+	if err != nil {
+		service.logger.Error("Failed to create badge",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("method", methodName),
+			zap.Error(err))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if !service.config.ExcludeCacheControlHeaders {
+		// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
+		w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
+	}
+	setBadgeContentType(w, contentType)
+	_, err = w.Write(generatedBadge)
+	if err != nil {
+		service.logger.Error("Failed to write HTTP response",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.String("method", methodName),
+			zap.Error(err))
+	}
+}
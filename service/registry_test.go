@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+	"github.com/tohjustin/aegis/service/config"
+)
+
+// testLogo is a base64 SVG data URI of a circle icon
+var testLogo = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(
+	[]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><circle cx="8" cy="8" r="8"/></svg>`))
+
+func newMockProvider() Provider {
+	return Provider{
+		Name:   "internal",
+		Routes: []string{`/internal/{method}/{project}`},
+		Methods: map[string]ProviderMethod{
+			"deployments": {
+				QueryParams: map[string][]string{"env": {"staging", "production"}},
+				Fetch: func(request ProviderRequest) (ProviderResult, error) {
+					if request.Vars["project"] == "missing" {
+						return ProviderResult{}, NewUpstreamStatusError(http.StatusNotFound)
+					}
+					if request.Vars["project"] == "broken" {
+						return ProviderResult{}, errors.New("broken")
+					}
+					subject := "deployments"
+					if env := request.Query.Get("env"); env != "" {
+						subject = env + " deployments"
+					}
+					return ProviderResult{Subject: subject, Status: "42", Color: "blue", Value: 42}, nil
+				},
+			},
+		},
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(newMockProvider()))
+	assert.NoError(t, registry.Register(Provider{
+		Name:    "other",
+		Routes:  []string{`/other`},
+		Handler: http.NotFoundHandler(),
+	}))
+	assert.Len(t, registry.Providers(), 2)
+	assert.Equal(t, []string{"deployments"}, registry.Providers()[0].MethodNames())
+
+	invalidProviders := map[string]Provider{
+		"MissingName":    {Routes: []string{`/a/{method}`}, Handler: http.NotFoundHandler()},
+		"MissingRoutes":  {Name: "a", Handler: http.NotFoundHandler()},
+		"MissingHandler": {Name: "a", Routes: []string{`/a/{method}`}},
+		"MethodsAndHandler": {
+			Name:    "a",
+			Routes:  []string{`/a/{method}`},
+			Methods: newMockProvider().Methods,
+			Handler: http.NotFoundHandler(),
+		},
+		"MissingFetch": {
+			Name:    "a",
+			Routes:  []string{`/a/{method}`},
+			Methods: map[string]ProviderMethod{"b": {}},
+		},
+		"MissingMethodVariable": {Name: "a", Routes: []string{`/a`}, Methods: newMockProvider().Methods},
+		"DuplicateName":         {Name: "internal", Routes: []string{`/a`}, Handler: http.NotFoundHandler()},
+		"DuplicateRoute":        {Name: "a", Routes: []string{`/other`}, Handler: http.NotFoundHandler()},
+	}
+	for name, provider := range invalidProviders {
+		assert.Error(t, registry.Register(provider), name)
+	}
+	assert.Len(t, registry.Providers(), 2)
+}
+
+func TestRegisteredProvider(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		logger:   zaptest.NewLogger(t),
+		config:   &config.Config{},
+		registry: NewRegistry(),
+	}
+	if err := app.RegisterProvider(newMockProvider()); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		requestPath  string
+		expectedBody string
+	}{
+		{
+			requestPath:  "/internal/deployments/aegis",
+			expectedBody: createBadge(&badge.Params{Subject: "deployments", Status: "42", Color: "blue"}),
+		},
+		{
+			requestPath:  "/internal/deployments/aegis?env=staging&subject=deploys",
+			expectedBody: createBadge(&badge.Params{Subject: "deploys", Status: "42", Color: "blue"}),
+		},
+		{
+			requestPath:  "/internal/deployments/aegis?format=json",
+			expectedBody: `{"subject":"deployments","status":"42","color":"blue","style":"classic","value":42}`,
+		},
+		{
+			requestPath:  "/internal/deployments/missing.png",
+			expectedBody: createPNGBadge(&badge.Params{Subject: "aegis", Status: "repo not found"}),
+		},
+		{
+			// the path suffix is only removed if it selects the badge format
+			requestPath:  "/internal/deployments/missing.png?format=json",
+			expectedBody: `{"subject":"deployments","status":"42","color":"blue","style":"classic","value":42}`,
+		},
+		{
+			// the path suffix is only removed from the last path segment
+			requestPath:  "/internal/deployments.png/aegis",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "not found"}),
+		},
+		{
+			requestPath: "/internal/deployments/aegis?link=https%3A%2F%2Fexample.com&subject-link=https%3A%2F%2Fexample.com%2Fa",
+			expectedBody: createBadge(&badge.Params{Subject: "deployments", Status: "42", Color: "blue",
+				Link: "https://example.com", SubjectLink: "https://example.com/a"}),
+		},
+		{
+			requestPath: "/internal/deployments/aegis?logo=" + url.QueryEscape(testLogo),
+			expectedBody: createBadge(&badge.Params{Subject: "deployments", Status: "42", Color: "blue",
+				Logo: testLogo}),
+		},
+		{
+			requestPath: "/internal/deployments/aegis?font-family=Verdana&font-size=14",
+			expectedBody: createBadge(&badge.Params{Subject: "deployments", Status: "42", Color: "blue",
+				FontFamily: "Verdana", FontSize: 14}),
+		},
+		{
+			requestPath:  "/internal/deployments/aegis?env=dev",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "bad request"}),
+		},
+		{
+			requestPath:  "/internal/unknown/aegis",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "not found"}),
+		},
+		{
+			requestPath:  "/internal/deployments/missing",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "repo not found"}),
+		},
+		{
+			requestPath:  "/internal/deployments/broken",
+			expectedBody: createBadge(&badge.Params{Subject: "aegis", Status: "internal server error"}),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.requestPath, func(t *testing.T) {
+			res := serveHTTPRequest(t, app.handler(), testCase.requestPath)
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, testCase.expectedBody, res.Body.String())
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+// stdoutOutput is the output path writing badges to stdout
+const stdoutOutput = "-"
+
+// renderSpec describes a badge rendered by the render command
+type renderSpec struct {
+	Output      string `yaml:"output"`
+	Format      string `yaml:"format"`
+	Subject     string `yaml:"subject"`
+	Status      string `yaml:"status"`
+	Color       string `yaml:"color"`
+	Icon        string `yaml:"icon"`
+	Logo        string `yaml:"logo"`
+	Style       string `yaml:"style"`
+	FontFamily  string `yaml:"font-family"`
+	FontSize    int    `yaml:"font-size"`
+	Link        string `yaml:"link"`
+	SubjectLink string `yaml:"subject-link"`
+	StatusLink  string `yaml:"status-link"`
+}
+
+// renderManifest describes the badges rendered by the render command in batch mode
+type renderManifest struct {
+	Badges []renderSpec `yaml:"badges"`
+}
+
+// outputFormat returns the format of a rendered badge, inferred from the file extension of its output
+// path unless specified (defaults to SVG)
+func (spec renderSpec) outputFormat() (badgeFormat, error) {
+	switch format := badgeFormat(spec.Format); format {
+	case "":
+		switch strings.ToLower(filepath.Ext(spec.Output)) {
+		case "." + string(pngFormat):
+			return pngFormat, nil
+		case "." + string(jsonFormat):
+			return jsonFormat, nil
+		default:
+			return svgFormat, nil
+		}
+	case svgFormat, pngFormat, jsonFormat:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported badge format: %s", format)
+	}
+}
+
+// render renders a badge into its output file, or the given writer if the output is stdout
+func (spec renderSpec) render(stdout io.Writer) error {
+	format, err := spec.outputFormat()
+	if err != nil {
+		return err
+	}
+	generatedBadge, _, err := renderBadge(format, &badge.Params{
+		Style:       badge.Style(spec.Style),
+		Subject:     spec.Subject,
+		Status:      spec.Status,
+		Color:       spec.Color,
+		Icon:        spec.Icon,
+		Logo:        spec.Logo,
+		FontFamily:  spec.FontFamily,
+		FontSize:    spec.FontSize,
+		Link:        spec.Link,
+		SubjectLink: spec.SubjectLink,
+		StatusLink:  spec.StatusLink,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	if spec.Output == "" || spec.Output == stdoutOutput {
+		_, err = stdout.Write(generatedBadge)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(spec.Output), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(spec.Output, generatedBadge, 0o644)
+}
+
+// readRenderManifest reads a YAML (or JSON) manifest of badges, resolving their output paths relative
+// to the directory of the manifest
+func readRenderManifest(path string) (*renderManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest renderManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	for i, spec := range manifest.Badges {
+		if spec.Output == "" {
+			return nil, fmt.Errorf("missing output of badge #%d", i+1)
+		}
+		if spec.Output != stdoutOutput && !filepath.IsAbs(spec.Output) {
+			manifest.Badges[i].Output = filepath.Join(filepath.Dir(path), spec.Output)
+		}
+	}
+
+	return &manifest, nil
+}
+
+// newRenderCommand returns a command rendering badges into files without running the server
+func newRenderCommand() *cobra.Command {
+	var spec renderSpec
+	var manifestPath string
+
+	renderCmd := &cobra.Command{
+		Use:  "render",
+		Long: "Render badges into files (or stdout), either from flags or in batch mode from a manifest",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if manifestPath == "" {
+				return spec.render(cmd.OutOrStdout())
+			}
+
+			manifest, err := readRenderManifest(manifestPath)
+			if err != nil {
+				return fmt.Errorf("failed to read manifest %s: %v", manifestPath, err)
+			}
+			var errs []error
+			for _, manifestSpec := range manifest.Badges {
+				if err := manifestSpec.render(cmd.OutOrStdout()); err != nil {
+					errs = append(errs, fmt.Errorf("failed to render %s: %v", manifestSpec.Output, err))
+				}
+			}
+			return errors.Join(errs...)
+		},
+	}
+
+	flags := renderCmd.Flags()
+	flags.StringVar(&spec.Subject, "subject", "", "Subject (left text) of the badge.")
+	flags.StringVar(&spec.Status, "status", "", "Status (right text) of the badge.")
+	flags.StringVar(&spec.Color, "color", "", "Color of the badge (eg. \"green\" or \"#4c1\").")
+	flags.StringVar(&spec.Icon, "icon", "", "Icon of the badge (eg. \"brands/github\").")
+	flags.StringVar(&spec.Logo, "logo", "", "Logo of the badge as a base64 SVG data URI, overriding the icon.")
+	flags.StringVar(&spec.Style, "style", "", "Style of the badge (classic, flat, plastic, semaphoreci, for-the-badge or social).")
+	flags.StringVar(&spec.FontFamily, "font-family", "", "Font family of the badge texts (eg. \"Verdana\"), defaults to the font family of the style.")
+	flags.IntVar(&spec.FontSize, "font-size", 0, "Font size in px of the badge texts, defaults to the font size of the style.")
+	flags.StringVar(&spec.Link, "link", "", "URL opened by clicking the badge.")
+	flags.StringVar(&spec.SubjectLink, "subject-link", "", "URL opened by clicking the subject of the badge, overriding the link.")
+	flags.StringVar(&spec.StatusLink, "status-link", "", "URL opened by clicking the status of the badge, overriding the link.")
+	flags.StringVar(&spec.Format, "format", "", "Output format of the badge (svg, png or json), inferred from the output file extension by default.")
+	flags.StringVarP(&spec.Output, "output", "o", stdoutOutput, "Path of the output file, or \"-\" for stdout.")
+	flags.StringVar(&manifestPath, "manifest", "", "Path of a YAML or JSON manifest listing badges to render (with \"output\", \"format\" & keys named after the other flags, eg. \"subject\" or \"font-size\"), rendering them all instead of the badge described by flags.")
+
+	return renderCmd
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+func TestRenderCommand(t *testing.T) {
+	t.Parallel()
+
+	var stdout bytes.Buffer
+	renderCmd := newRenderCommand()
+	renderCmd.SetOut(&stdout)
+	renderCmd.SetArgs([]string{"--subject", "build", "--status", "passing", "--color", "green"})
+	assert.NoError(t, renderCmd.Execute())
+	assert.Equal(t, createBadge(&badge.Params{Subject: "build", Status: "passing", Color: "green"}), stdout.String())
+
+	stdout.Reset()
+	renderCmd = newRenderCommand()
+	renderCmd.SetOut(&stdout)
+	renderCmd.SetArgs([]string{"--subject", "build", "--status", "passing", "--logo", testLogo,
+		"--font-family", "Verdana", "--font-size", "14", "--link", "https://example.com",
+		"--subject-link", "https://example.com/subject", "--status-link", "https://example.com/status"})
+	assert.NoError(t, renderCmd.Execute())
+	assert.Equal(t, createBadge(&badge.Params{Subject: "build", Status: "passing", Logo: testLogo,
+		FontFamily: "Verdana", FontSize: 14, Link: "https://example.com", SubjectLink: "https://example.com/subject",
+		StatusLink: "https://example.com/status"}), stdout.String())
+
+	output := filepath.Join(t.TempDir(), "badge.json")
+	renderCmd = newRenderCommand()
+	renderCmd.SetArgs([]string{"--subject", "build", "--status", "passing", "--output", output})
+	assert.NoError(t, renderCmd.Execute())
+	generatedBadge, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"subject":"build","status":"passing","color":"#f7b137","style":"classic"}`, string(generatedBadge))
+}
+
+func TestRenderCommandWithManifest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "badges.yaml")
+	if err := os.WriteFile(manifest, []byte(`
+badges:
+  - output: badges/build.svg
+    subject: build
+    status: passing
+    style: flat
+  - output: badges/coverage.png
+    subject: coverage
+    status: 92%
+  - output: badges/docs.svg
+    subject: docs
+    status: latest
+    font-size: 13
+    link: https://example.com/docs
+    status-link: https://example.com/docs/latest
+  - output: badges/invalid.svg
+    format: gif
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	renderCmd := newRenderCommand()
+	renderCmd.SetOut(&bytes.Buffer{})
+	renderCmd.SetErr(&bytes.Buffer{})
+	renderCmd.SetArgs([]string{"--manifest", manifest})
+	err := renderCmd.Execute()
+	assert.EqualError(t, err, "failed to render "+filepath.Join(dir, "badges/invalid.svg")+": unsupported badge format: gif")
+
+	// valid badges are rendered despite errors in other badges
+	generatedBadge, err := os.ReadFile(filepath.Join(dir, "badges/build.svg"))
+	assert.NoError(t, err)
+	assert.Equal(t, createBadge(&badge.Params{Subject: "build", Status: "passing", Style: badge.FlatStyle}),
+		string(generatedBadge))
+	generatedBadge, err = os.ReadFile(filepath.Join(dir, "badges/docs.svg"))
+	assert.NoError(t, err)
+	assert.Equal(t, createBadge(&badge.Params{Subject: "docs", Status: "latest", FontSize: 13,
+		Link: "https://example.com/docs", StatusLink: "https://example.com/docs/latest"}), string(generatedBadge))
+	generatedBadge, err = os.ReadFile(filepath.Join(dir, "badges/coverage.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\x89PNG", string(generatedBadge[:4]))
+	assert.NoFileExists(t, filepath.Join(dir, "badges/invalid.svg"))
+}
@@ -9,10 +9,14 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/tohjustin/aegis/service/config"
 )
@@ -27,8 +31,13 @@ type GitProviderService interface {
 	BadgeService
 	getForkCount(owner string, repo string) (int, error)
 	getIssueCount(owner string, repo string, issueState string) (int, error)
+	getLatestRelease(owner string, repo string, includePrereleases bool) (string, error)
+	getLatestTag(owner string, repo string, includePrereleases bool) (string, error)
+	getPipelineStatus(owner string, repo string, branch string, workflow string) (string, error)
 	getPullRequestCount(owner string, repo string, pullRequestState string) (int, error)
 	getStarCount(owner string, repo string) (int, error)
+	methods() map[string]ProviderMethod
+	probe(ctx context.Context) error
 }
 
 // Info contains build information about the application
@@ -47,24 +56,41 @@ type Application struct {
 	logger  *zap.Logger
 	rootCmd *cobra.Command
 
-	staticService    *BadgeService
-	bitbucketService *GitProviderService
-	githubService    *GitProviderService
-	gitlabService    *GitProviderService
+	// logLevel is the level of the logger, updated on configuration reloads
+	logLevel zap.AtomicLevel
+	// registry holds the providers registered via `RegisterProvider`, served along the built-in providers
+	registry *Registry
+	// ready reports whether the application is accepting requests, used in readiness checks
+	ready atomic.Bool
+	// current holds the HTTP handler serving requests, swapped on configuration reloads
+	current atomic.Pointer[http.Handler]
+	// assets holds the badge assets registered from the configured directories, replaced on configuration reloads
+	assets *badgeAssets
+	// state holds the service state carried over on configuration reloads (eg. response caches)
+	state *serviceState
 }
 
-func (app *Application) init() {
-	logger, err := newLogger()
-	if err != nil {
-		log.Fatalf("Failed to get logger: %v", err)
-	}
-	app.logger = logger
+// generation holds the HTTP handler, badge assets & service state built for a configuration, committed once it is
+// applied
+type generation struct {
+	handler http.Handler
+	assets  *badgeAssets
+	state   *serviceState
+}
 
+func (app *Application) init() {
 	config, err := config.New()
 	if err != nil {
 		log.Fatalf("Failed to get config: %v", err)
 	}
 	app.config = config
+
+	logger, logLevel, err := newLogger(config.LogLevel)
+	if err != nil {
+		log.Fatalf("Failed to get logger: %v", err)
+	}
+	app.logger = logger
+	app.logLevel = logLevel
 }
 
 func (app *Application) execute() {
@@ -75,42 +101,39 @@ func (app *Application) execute() {
 
 	// Setup dependencies
 	app.logger.Info("Initializing services...")
-	staticService, err := NewStaticService(app.config, app.logger)
+	next, err := app.build(app.config)
 	if err != nil {
-		log.Fatalf("Failed to get static service: %v", err)
+		log.Fatalf("Failed to initialize services: %v", err)
 	}
-	bitbucketService, err := NewBitbucketService(app.config, app.logger)
-	if err != nil {
-		log.Fatalf("Failed to get Bitbucket service: %v", err)
-	}
-	githubService, err := NewGithubService(app.config, app.logger)
-	if err != nil {
-		log.Fatalf("Failed to get GitHub service: %v", err)
-	}
-	gitlabService, err := NewGitlabService(app.config, app.logger)
-	if err != nil {
-		log.Fatalf("Failed to get GitLab service: %v", err)
-	}
-	app.staticService = &staticService
-	app.bitbucketService = &bitbucketService
-	app.githubService = &githubService
-	app.gitlabService = &gitlabService
+	app.commit(next)
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", app.config.Port),
 		ReadTimeout:  app.config.ReadTimeout,
 		WriteTimeout: app.config.WriteTimeout,
-		Handler:      app.handler(),
+		// in-flight requests complete with the handler they started with when the configuration is reloaded
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			(*app.current.Load()).ServeHTTP(w, r)
+		}),
 	}
 
-	// gracefully shutdowns server
+	// reloads configuration on SIGHUP & gracefully shutdowns server on SIGINT/SIGTERM
 	idleConnsClosed := make(chan struct{})
 	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-		s := <-sigint
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		s := <-signals
+		for ; s == syscall.SIGHUP; s = <-signals {
+			app.logger.Info("Received signal from OS", zap.String("signal", s.String()))
+			app.reload()
+		}
 		app.logger.Info("Received signal from OS", zap.String("signal", s.String()))
 
+		// fail readiness checks first, so that load balancers stop routing requests before draining
+		app.ready.Store(false)
+		app.logger.Info("Waiting for readiness checks to fail...", zap.Duration("ShutdownDelay", app.config.ShutdownDelay))
+		time.Sleep(app.config.ShutdownDelay)
+
 		app.logger.Info("Starting shutdown...")
 		if err := httpServer.Shutdown(context.Background()); err != nil {
 			app.logger.Error("Encountered error during shutdown", zap.Error(err))
@@ -122,6 +145,7 @@ func (app *Application) execute() {
 
 	// Start HTTP server
 	app.logger.Info("HTTP server listening...", zap.Uint("Port", app.config.Port))
+	app.ready.Store(true)
 	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
 		app.logger.Error("HTTP server encountered an error", zap.Error(err))
 	}
@@ -129,34 +153,259 @@ func (app *Application) execute() {
 	<-idleConnsClosed
 }
 
+// build creates the services of all providers & returns a HTTP handler serving them with the given configuration,
+// along with the badge assets loaded from the configured directories, without registering them (see `commit`).
+// Response caches, GitHub token pools & batchers of the current services are reused if their configuration is
+// unchanged.
+func (app *Application) build(configuration *config.Config) (*generation, error) {
+	assets, err := loadBadgeAssets(configuration)
+	if err != nil {
+		return nil, err
+	}
+	state := newServiceState(app.state)
+	staticService, err := NewStaticService(configuration, app.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get static service: %v", err)
+	}
+	endpointService, err := NewEndpointService(configuration, app.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoint service: %v", err)
+	}
+	coverageService, err := NewCoverageService(configuration, app.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coverage service: %v", err)
+	}
+	bitbucketService, err := newBitbucketService(configuration, app.logger, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Bitbucket service: %v", err)
+	}
+	githubService, err := newDefaultGithubService(configuration, app.logger, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub service: %v", err)
+	}
+	gitlabService, err := newDefaultGitlabService(configuration, app.logger, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitLab service: %v", err)
+	}
+	githubInstances := make(map[string]GitProviderService)
+	for _, instance := range configuration.GithubInstances {
+		githubInstances[instance.Name], err = newGithubInstanceService(configuration, app.logger, state, instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitHub service for instance %q: %v", instance.Name, err)
+		}
+	}
+	githubInstanceService, err := NewGitProviderInstanceService(configuration, app.logger, "github", githubInstances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub instance service: %v", err)
+	}
+	gitlabInstances := make(map[string]GitProviderService)
+	for _, instance := range configuration.GitlabInstances {
+		gitlabInstances[instance.Name], err = newGitlabInstanceService(configuration, app.logger, state, instance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get GitLab service for instance %q: %v", instance.Name, err)
+		}
+	}
+	gitlabInstanceService, err := NewGitProviderInstanceService(configuration, app.logger, "gitlab", gitlabInstances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitLab instance service: %v", err)
+	}
+
+	// Register built-in providers
+	providers := []Provider{
+		{
+			Name:    "static",
+			Routes:  []string{`/static`, `/static` + pngPathSuffix},
+			Handler: staticService,
+		},
+		{
+			Name:    "endpoint",
+			Routes:  []string{`/endpoint`, `/endpoint` + pngPathSuffix},
+			Handler: endpointService,
+		},
+		{
+			Name:        "coverage",
+			Routes:      []string{`/coverage`, `/coverage/{project}/{branch}`},
+			Handler:     coverageService,
+			HTTPMethods: []string{http.MethodGet, http.MethodPost},
+		},
+		{
+			Name:    "bitbucket",
+			Routes:  []string{`/bitbucket/{method}/{owner}/{repo}`},
+			Methods: bitbucketService.methods(),
+			Probe:   bitbucketService.probe,
+		},
+		{
+			Name:    "github",
+			Routes:  []string{`/github/{method}/{owner}/{repo}`},
+			Methods: githubService.methods(),
+			Probe:   githubService.probe,
+		},
+		{
+			Name:    "gitlab",
+			Routes:  []string{`/gitlab/{method}/{owner}/{repo}`},
+			Methods: gitlabService.methods(),
+			Probe:   gitlabService.probe,
+		},
+	}
+	if len(githubInstances) > 0 {
+		providers = append(providers, Provider{
+			Name:    "github-instances",
+			Routes:  []string{`/github/{instance}/{method}/{owner}/{repo}`},
+			Handler: githubInstanceService,
+			Probe:   probeInstances(githubInstances),
+		})
+	}
+	if len(gitlabInstances) > 0 {
+		providers = append(providers, Provider{
+			Name:    "gitlab-instances",
+			Routes:  []string{`/gitlab/{instance}/{method}/{owner}/{repo}`},
+			Handler: gitlabInstanceService,
+			Probe:   probeInstances(gitlabInstances),
+		})
+	}
+	registry := NewRegistry()
+	for _, provider := range append(app.registry.Providers(), providers...) {
+		if err := registry.Register(provider); err != nil {
+			return nil, fmt.Errorf("failed to register %s provider: %v", provider.Name, err)
+		}
+	}
+
+	return &generation{handler: app.newHandler(configuration, registry), assets: assets, state: state}, nil
+}
+
+// commit registers the badge assets of a generation (unregistering the ones removed since the previous generation)
+// & swaps in its HTTP handler & service state
+func (app *Application) commit(next *generation) {
+	if err := next.assets.register(app.assets, app.logger); err != nil {
+		app.logger.Error("Failed to register badge assets", zap.Error(err))
+	}
+	app.assets = next.assets
+	next.state.release()
+	app.state = next.state
+	app.current.Store(&next.handler)
+}
+
 // handler setup routes & returns a HTTP handler for the application server
 func (app *Application) handler() http.Handler {
+	return app.newHandler(app.config, app.registry)
+}
+
+// newHandler setup routes of the given providers & returns a HTTP handler serving them with the given configuration
+func (app *Application) newHandler(configuration *config.Config, registry *Registry) http.Handler {
 	mux := mux.NewRouter()
 
 	mux.UseEncodedPath()
-	mux.Handle(`/static`, *app.staticService).Methods("GET")
-	mux.Handle(`/bitbucket/{method}/{owner}/{repo}`, *app.bitbucketService).Methods("GET")
-	mux.Handle(`/github/{method}/{owner}/{repo}`, *app.githubService).Methods("GET")
-	mux.Handle(`/gitlab/{method}/{owner}/{repo}`, *app.gitlabService).Methods("GET")
+	mux.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// badge format can be negotiated via the Accept header
+			w.Header().Set("Vary", "Accept")
+			next.ServeHTTP(w, r)
+		})
+	})
+	providerMethods := make(map[string]map[string]bool)
+	for _, provider := range registry.Providers() {
+		providerMethods[provider.Name] = make(map[string]bool)
+		for _, method := range provider.MethodNames() {
+			providerMethods[provider.Name][method] = true
+		}
+	}
+	mux.Use(metricsMiddleware(providerMethods))
+	mux.Handle(metricsPath, metricsHandler()).Methods("GET").Name(metricsPath)
+	healthService, err := newHealthService(configuration, app.logger, &app.ready, registry.Providers())
+	if err != nil {
+		app.logger.Error("Failed to create health service", zap.Error(err))
+	} else {
+		mux.HandleFunc(livenessPath, healthService.liveness).Methods("GET").Name(livenessPath)
+		mux.HandleFunc(readinessPath, healthService.readiness).Methods("GET").Name(readinessPath)
+	}
+
+	for _, provider := range registry.Providers() {
+		handler := provider.Handler
+		if handler == nil {
+			providerService, err := newProviderService(configuration, app.logger, provider.Name, provider.Methods)
+			if err != nil {
+				app.logger.Error("Failed to create provider service",
+					zap.String("service", provider.Name),
+					zap.Error(err))
+				continue
+			}
+			handler = providerService
+		}
+		for _, route := range provider.Routes {
+			mux.Handle(route, handler).Methods(provider.httpMethods()...).Name(provider.Name)
+		}
+	}
 
-	if url := app.config.RootRedirectURL; url != "" {
+	if url := configuration.RootRedirectURL; url != "" {
 		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, url, http.StatusFound)
-		}).Methods("GET")
+		}).Methods("GET").Name("root")
 	}
 	// return service-not-found badge for all unmatched routes
 	mux.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := serviceNotFound(w, app.config)
+		err := serviceNotFound(w, r, configuration)
 		if err != nil {
 			app.logger.Error("Failed to create error badge",
 				zap.String("url", r.URL.RequestURI()),
 				zap.Error(err))
 		}
-	}).Methods("GET")
+	}).Methods("GET").Name(unmatchedRouteName)
 
 	return mux
 }
 
+// reload re-reads the configuration & applies it, keeping the current configuration if it's invalid
+func (app *Application) reload() {
+	app.logger.Info("Reloading configuration...")
+	configuration, err := config.New()
+	if err == nil {
+		err = app.apply(configuration)
+	}
+	if err != nil {
+		app.logger.Error("Rejected configuration, keeping the current one", zap.Error(err))
+	}
+}
+
+// apply swaps the configuration of the running application, rebuilding the services of all providers
+func (app *Application) apply(configuration *config.Config) error {
+	if err := configuration.Validate(); err != nil {
+		return err
+	}
+	changes := config.Diff(app.config, configuration)
+	// badge assets are reloaded from their directories, even if the configuration is unchanged
+	hasAssetDirs := configuration.TemplateDir != "" || configuration.FontDir != "" || configuration.IconDir != ""
+	if len(changes) == 0 && !hasAssetDirs {
+		app.logger.Info("Configuration is unchanged")
+		return nil
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(configuration.LogLevel)); err != nil {
+		return err
+	}
+	next, err := app.build(configuration)
+	if err != nil {
+		return err
+	}
+
+	app.commit(next)
+	app.logLevel.SetLevel(level)
+	for _, change := range changes {
+		app.logger.Info("Configuration changed", zap.String("change", change))
+	}
+	if configuration.Port != app.config.Port || configuration.ReadTimeout != app.config.ReadTimeout ||
+		configuration.WriteTimeout != app.config.WriteTimeout {
+		app.logger.Warn("Changes to the port & timeouts require a restart to take effect")
+	}
+	app.config = configuration
+
+	return nil
+}
+
+// RegisterProvider registers a badge provider, must be called before starting the application
+func (app *Application) RegisterProvider(provider Provider) error {
+	return app.registry.Register(provider)
+}
+
 // Start starts the application
 func (app *Application) Start() error {
 	return app.rootCmd.Execute()
@@ -165,7 +414,8 @@ func (app *Application) Start() error {
 // New creates and returns a new instance of Application.
 func New(appInfo Info) (*Application, error) {
 	app := &Application{
-		info: appInfo,
+		info:     appInfo,
+		registry: NewRegistry(),
 	}
 
 	// Setup commands
@@ -184,18 +434,29 @@ func New(appInfo Info) (*Application, error) {
 			fmt.Printf("%s v%s (%s)\n", appInfo.ShortName, appInfo.Version, appInfo.GitHash)
 		},
 	}
-	rootCmd.AddCommand(versionCmd)
+	configCmd := &cobra.Command{
+		Use:  "config",
+		Long: "Print the effective configuration, with secrets redacted",
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, err := config.New(); err != nil {
+				log.Fatalf("Failed to get config: %v", err)
+			}
+			if err := config.Print(os.Stdout); err != nil {
+				log.Fatalf("Failed to print config: %v", err)
+			}
+		},
+	}
+	rootCmd.AddCommand(versionCmd, configCmd, newRenderCommand())
 
 	// Setup Flags
 	flagSet := new(flag.FlagSet)
 	addFlagsFns := []func(*flag.FlagSet){
 		config.Flags,
-		loggerFlags,
 	}
 	for _, addFlags := range addFlagsFns {
 		addFlags(flagSet)
 	}
-	rootCmd.Flags().AddGoFlagSet(flagSet)
+	rootCmd.PersistentFlags().AddGoFlagSet(flagSet)
 
 	app.rootCmd = rootCmd
 
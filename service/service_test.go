@@ -1,27 +1,71 @@
 package service
 
 import (
+	"maps"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/tohjustin/aegis/pkg/badge"
 	"github.com/tohjustin/aegis/service/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
 )
 
 type httpTestCase struct {
 	requestMethod   string
 	requestPath     string
+	requestHeaders  map[string]string
 	expectedHeaders map[string]string
 	expectedStatus  int
 	expectedBody    string
 }
 
+// newGitProviderRouter returns a router serving the git provider service at `/{method}/{owner}/{repo}`
+func newGitProviderRouter(service GitProviderService) http.Handler {
+	router := mux.NewRouter()
+	router.UseEncodedPath()
+	router.Handle(`/{method}/{owner}/{repo}`, service)
+	return router
+}
+
+// serveHTTPRequest serves a GET request with the given handler & returns the recorded response
+func serveHTTPRequest(t *testing.T, handler http.Handler, requestPath string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest("GET", requestPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+// newTestDir returns a temporary directory containing the given files (file name -> content)
+func newTestDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
 func runHTTPTest(t *testing.T, testCase httpTestCase) {
 	req, err := http.NewRequest(testCase.requestMethod, testCase.requestPath, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
+	for fieldName, fieldValue := range testCase.requestHeaders {
+		req.Header.Set(fieldName, fieldValue)
+	}
 
 	// TODO: Create proper mock dependencies & service generators
 	mockLogger := zaptest.NewLogger(t)
@@ -30,20 +74,34 @@ func runHTTPTest(t *testing.T, testCase httpTestCase) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	mockEndpointService, err := NewEndpointService(mockConfig, mockLogger)
+	if err != nil {
+		t.Fatal(err)
+	}
 	mockGitProviderService, err := NewGitlabService(mockConfig, mockLogger)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	registry := NewRegistry()
+	for _, provider := range []Provider{
+		{Name: "static", Routes: []string{`/static`, `/static.png`}, Handler: mockStaticService},
+		{Name: "endpoint", Routes: []string{`/endpoint`, `/endpoint.png`}, Handler: mockEndpointService},
+		{Name: "bitbucket", Routes: []string{`/bitbucket/{method}/{owner}/{repo}`}, Handler: mockGitProviderService},
+		{Name: "github", Routes: []string{`/github/{method}/{owner}/{repo}`}, Handler: mockGitProviderService},
+		{Name: "gitlab", Routes: []string{`/gitlab/{method}/{owner}/{repo}`}, Methods: mockGitProviderService.methods()},
+	} {
+		if err := registry.Register(provider); err != nil {
+			t.Fatal(err)
+		}
+	}
+
 	testServer := &Application{
-		info:             Info{},
-		logger:           mockLogger,
-		config:           mockConfig,
-		rootCmd:          nil,
-		staticService:    &mockStaticService,
-		bitbucketService: &mockGitProviderService,
-		githubService:    &mockGitProviderService,
-		gitlabService:    &mockGitProviderService,
+		info:     Info{},
+		logger:   mockLogger,
+		config:   mockConfig,
+		rootCmd:  nil,
+		registry: registry,
 	}
 	res := httptest.NewRecorder()
 	testServer.handler().ServeHTTP(res, req)
@@ -68,3 +126,216 @@ func runHTTPTest(t *testing.T, testCase httpTestCase) {
 			body, testCase.expectedBody)
 	}
 }
+
+func TestServiceNotFound(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/unknown-service",
+		expectedHeaders: map[string]string{
+			"Content-Type":            "image/svg+xml;utf-8",
+			"Content-Security-Policy": "default-src 'none'; img-src data:; style-src 'unsafe-inline'",
+			"Vary":                    "Accept",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "aegis",
+			Status:  "service not found",
+		}),
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/unknown-service?format=json",
+		expectedHeaders: map[string]string{
+			"Content-Type":            "application/json",
+			"Content-Security-Policy": "",
+		},
+		expectedStatus: 200,
+		expectedBody:   `{"subject":"aegis","status":"service not found","color":"#f7b137","style":"classic"}`,
+	})
+}
+
+func TestGitProviderServiceWithUnsupportedMethod(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/gitlab/unknown-method/owner/repo.png",
+		expectedHeaders: map[string]string{
+			"Content-Type":            "image/png",
+			"Content-Security-Policy": "",
+		},
+		expectedStatus: 200,
+		expectedBody: createPNGBadge(&badge.Params{
+			Subject: "aegis",
+			Status:  "not found",
+		}),
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/gitlab/unknown-method/owner/repo",
+		requestHeaders: map[string]string{
+			"Accept": "application/json",
+		},
+		expectedHeaders: map[string]string{
+			"Content-Type": "application/json",
+		},
+		expectedStatus: 200,
+		expectedBody:   `{"subject":"aegis","status":"not found","color":"#f7b137","style":"classic"}`,
+	})
+}
+
+func TestApplyConfiguration(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		logger:   zaptest.NewLogger(t),
+		logLevel: zap.NewAtomicLevel(),
+		config: &config.Config{
+			GithubAccessTokens: []string{"testToken"},
+			RootRedirectURL:    "https://a.example.com",
+		},
+		registry: NewRegistry(),
+	}
+	next, err := app.build(app.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.commit(next)
+	redirectURL := func() string {
+		return serveHTTPRequest(t, *app.current.Load(), "/").Header().Get("Location")
+	}
+	assert.Equal(t, "https://a.example.com", redirectURL())
+
+	assert.NoError(t, app.apply(&config.Config{
+		LogLevel:           "DEBUG",
+		GithubAccessTokens: []string{"testToken"},
+		RootRedirectURL:    "https://b.example.com",
+	}))
+	assert.Equal(t, "https://b.example.com", redirectURL())
+	assert.Equal(t, zapcore.DebugLevel, app.logLevel.Level())
+
+	// invalid configurations are rejected, keeping the current one
+	assert.Error(t, app.apply(&config.Config{
+		GithubAccessTokens:  []string{"testToken"},
+		RootRedirectURL:     "https://c.example.com",
+		CoverageUploadToken: "testToken",
+	}))
+	assert.Error(t, app.apply(&config.Config{RootRedirectURL: "https://c.example.com"}))
+	assert.Equal(t, "https://b.example.com", redirectURL())
+	assert.Equal(t, "https://b.example.com", app.config.RootRedirectURL)
+}
+
+func TestApplyConfigurationAssets(t *testing.T) {
+	t.Parallel()
+
+	icon := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><path d="M0 0h16v16H0z"/></svg>`
+	previousDir := newTestDir(t, map[string]string{"apply-previous.svg": icon, "apply-kept.svg": icon})
+	nextDir := newTestDir(t, map[string]string{"apply-next.svg": icon, "apply-kept.svg": icon})
+
+	app := &Application{
+		logger:   zaptest.NewLogger(t),
+		logLevel: zap.NewAtomicLevel(),
+		config:   &config.Config{GithubAccessTokens: []string{"testToken"}, IconDir: previousDir},
+		registry: NewRegistry(),
+	}
+	next, err := app.build(app.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, badge.HasIcon("custom/apply-previous"), "assets are registered when committed")
+	app.commit(next)
+	assert.True(t, badge.HasIcon("custom/apply-previous"))
+	assert.True(t, badge.HasIcon("custom/apply-kept"))
+
+	// assets of rejected configurations aren't registered
+	assert.Error(t, app.apply(&config.Config{IconDir: nextDir}))
+	assert.False(t, badge.HasIcon("custom/apply-next"))
+	assert.True(t, badge.HasIcon("custom/apply-previous"))
+
+	// assets removed from their directories are unregistered
+	assert.NoError(t, app.apply(&config.Config{GithubAccessTokens: []string{"testToken"}, IconDir: nextDir}))
+	assert.True(t, badge.HasIcon("custom/apply-next"))
+	assert.True(t, badge.HasIcon("custom/apply-kept"))
+	assert.False(t, badge.HasIcon("custom/apply-previous"))
+
+	// assets are reloaded from unchanged directories
+	if err := os.Remove(filepath.Join(nextDir, "apply-next.svg")); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, app.apply(&config.Config{GithubAccessTokens: []string{"testToken"}, IconDir: nextDir}))
+	assert.False(t, badge.HasIcon("custom/apply-next"))
+	assert.True(t, badge.HasIcon("custom/apply-kept"))
+}
+
+func TestApplyConfigurationState(t *testing.T) {
+	t.Parallel()
+
+	app := &Application{
+		logger:   zaptest.NewLogger(t),
+		logLevel: zap.NewAtomicLevel(),
+		config: &config.Config{
+			GithubAccessTokens: []string{"testToken"},
+			GithubCacheTTL:     time.Minute,
+			GitlabCacheTTL:     time.Minute,
+		},
+		registry: NewRegistry(),
+	}
+	next, err := app.build(app.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.commit(next)
+	carriedOver := func(previous map[string]interface{}) int {
+		count := 0
+		for key, value := range app.state.values {
+			if previousValue, ok := previous[key]; ok && previousValue == value {
+				count++
+			}
+		}
+		return count
+	}
+	// Bitbucket & GitLab response caches, GitHub response cache, token pool & batcher
+	assert.Len(t, app.state.values, 5)
+
+	// state is carried over if the configuration of the services is unchanged
+	previous := maps.Clone(app.state.values)
+	assert.NoError(t, app.apply(&config.Config{
+		GithubAccessTokens: []string{"testToken"},
+		GithubCacheTTL:     time.Minute,
+		GitlabCacheTTL:     time.Minute,
+		RootRedirectURL:    "https://example.com",
+	}))
+	assert.Len(t, app.state.values, 5)
+	assert.Equal(t, 5, carriedOver(previous))
+	assert.Nil(t, app.state.previous)
+
+	// state of services whose configuration changed is created anew
+	previous = maps.Clone(app.state.values)
+	assert.NoError(t, app.apply(&config.Config{
+		GithubAccessTokens: []string{"otherToken"},
+		GithubCacheTTL:     time.Minute,
+		GitlabCacheTTL:     time.Minute,
+		RootRedirectURL:    "https://example.com",
+	}))
+	assert.Len(t, app.state.values, 5)
+	assert.Equal(t, 2, carriedOver(previous))
+
+	// responses cached with other credentials aren't carried over
+	previous = maps.Clone(app.state.values)
+	assert.NoError(t, app.apply(&config.Config{
+		GithubAccessTokens:   []string{"otherToken"},
+		GithubCacheTTL:       time.Minute,
+		GitlabCacheTTL:       time.Minute,
+		BitbucketAccessToken: "bitbucketToken",
+		RootRedirectURL:      "https://example.com",
+	}))
+	assert.Len(t, app.state.values, 5)
+	assert.Equal(t, 4, carriedOver(previous))
+
+	// state of rejected configurations is discarded
+	previous = maps.Clone(app.state.values)
+	assert.Error(t, app.apply(&config.Config{GithubCacheTTL: time.Minute}))
+	assert.Equal(t, 5, carriedOver(previous))
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+)
+
+// serviceState holds the state of the services built for a configuration that is worth keeping across
+// configuration reloads (ie. response caches, GitHub token pools & batchers), keyed by the configuration values
+// it was created with. Values of the previous state are carried over when the same key is loaded again.
+type serviceState struct {
+	mutex    sync.Mutex
+	previous *serviceState
+	values   map[string]interface{}
+}
+
+// newServiceState returns a service state carrying over the values of `previous` (if not nil)
+func newServiceState(previous *serviceState) *serviceState {
+	return &serviceState{previous: previous, values: make(map[string]interface{})}
+}
+
+// load returns the value of a key, carrying it over from the previous state or calling `create` if needed
+func (state *serviceState) load(key string, create func() interface{}) interface{} {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	if value, ok := state.values[key]; ok {
+		return value
+	}
+	value, ok := state.previous.get(key)
+	if !ok {
+		value = create()
+	}
+	state.values[key] = value
+
+	return value
+}
+
+// get returns the value of a key, if it was loaded
+func (state *serviceState) get(key string) (interface{}, bool) {
+	if state == nil {
+		return nil, false
+	}
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	value, ok := state.values[key]
+	return value, ok
+}
+
+// release drops the previous state, once the values worth keeping have been loaded
+func (state *serviceState) release() {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	state.previous = nil
+}
+
+// carryOver returns the value of `create` for the given keys (eg. the name of a service & the configuration values the
+// value is created with), reusing the value of the previous state loaded with the same keys. Without state, a new value
+// is always created.
+func carryOver[T any](state *serviceState, create func() T, keys ...interface{}) T {
+	if state == nil {
+		return create()
+	}
+
+	return state.load(fmt.Sprintf("%#v", keys), func() interface{} {
+		return create()
+	}).(T)
+}
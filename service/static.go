@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -34,19 +36,57 @@ func NewStaticService(configuration *config.Config,
 }
 
 func (service *staticService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	generatedBadge, err := badge.Create(&badge.Params{
-		Style:   badge.Style(r.URL.Query().Get("style")),
-		Subject: r.URL.Query().Get("subject"),
-		Status:  r.URL.Query().Get("status"),
-		Color:   r.URL.Query().Get("color"),
-		Icon:    r.URL.Query().Get("icon"),
-	})
+	format, err := requestFormat(r)
+	if err != nil {
+		service.logger.Info("Unsupported format",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+		}
+		return
+	}
+
+	var generatedBadge []byte
+	var contentType string
+	params := queryParams(r.URL.Query(), badge.Params{})
+	if segments := r.URL.Query()["segment"]; len(segments) > 0 {
+		generatedBadge, contentType, err = renderSegmentedBadge(format, &badge.SegmentParams{
+			Segments:   parseSegments(segments),
+			Style:      params.Style,
+			Icon:       params.Icon,
+			Logo:       params.Logo,
+			FontFamily: params.FontFamily,
+			FontSize:   params.FontSize,
+			Link:       params.Link,
+		})
+	} else {
+		generatedBadge, contentType, err = renderBadge(format, params, nil)
+	}
+	var segmentsErr *badge.SegmentsError
+	if errors.As(err, &segmentsErr) {
+		service.logger.Info("Unsupported segments",
+			zap.String("url", r.URL.RequestURI()),
+			zap.String("service", service.name),
+			zap.Error(err))
+		if err := badRequest(w, r, service.config); err != nil {
+			service.logger.Error("Failed to create error badge",
+				zap.String("url", r.URL.RequestURI()),
+				zap.String("service", service.name),
+				zap.Error(err))
+		}
+		return
+	}
 	if err != nil {
 		service.logger.Error("Failed to create badge",
 			zap.String("url", r.URL.RequestURI()),
 			zap.String("service", service.name),
 			zap.Error(err))
-		if err := internalServerError(w, service.config); err != nil {
+		if err := internalServerError(w, r, service.config); err != nil {
 			service.logger.Error("Failed to create error badge",
 				zap.String("url", r.URL.RequestURI()),
 				zap.String("service", service.name),
@@ -60,8 +100,8 @@ func (service *staticService) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		// cache response in browser for 1 hour (3600), CDN for 1 hour (3600)
 		w.Header().Set("Cache-Control", "public, max-age=3600, s-maxage=3600")
 	}
-	w.Header().Set("Content-Type", "image/svg+xml;utf-8")
-	_, err = w.Write([]byte(generatedBadge))
+	setBadgeContentType(w, contentType)
+	_, err = w.Write(generatedBadge)
 	if err != nil {
 		service.logger.Error("Failed to write HTTP response",
 			zap.String("url", r.URL.RequestURI()),
@@ -69,3 +109,18 @@ func (service *staticService) ServeHTTP(w http.ResponseWriter, r *http.Request)
 			zap.Error(err))
 	}
 }
+
+// parseSegments converts `segment` query parameters ("<TEXT>[|<COLOR>]") into badge segments, where segments
+// without colors are labels
+func parseSegments(values []string) []badge.Segment {
+	segments := make([]badge.Segment, len(values))
+	for i, value := range values {
+		if separator := strings.LastIndex(value, "|"); separator >= 0 {
+			segments[i] = badge.Segment{Text: value[:separator], Color: value[separator+1:]}
+		} else {
+			segments[i] = badge.Segment{Text: value}
+		}
+	}
+
+	return segments
+}
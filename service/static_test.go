@@ -1,7 +1,9 @@
 package service
 
 import (
+	"encoding/base64"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/tohjustin/aegis/pkg/badge"
@@ -16,6 +18,15 @@ func createBadge(params *badge.Params) string {
 	return generatedBadge
 }
 
+func createPNGBadge(params *badge.Params) string {
+	generatedBadge, err := badge.CreatePNG(params)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(generatedBadge)
+}
+
 func TestStaticBadgeService(t *testing.T) {
 	t.Parallel()
 
@@ -167,6 +178,252 @@ func TestStaticBadgeServiceWithBadStyleQuery(t *testing.T) {
 	})
 }
 
+func TestStaticBadgeServiceWithFontQuery(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&font-family=Verdana&font-size=13",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject:  "testSubject",
+			Status:   "testStatus",
+			FontSize: 13,
+		}),
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&font-family=badFont&font-size=badSize",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "testSubject",
+			Status:  "testStatus",
+		}),
+	})
+}
+
+func TestStaticBadgeServiceWithLogoQuery(t *testing.T) {
+	t.Parallel()
+
+	logo := "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(
+		[]byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><path d="M0 0h16v16H0z"/></svg>`))
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&icon=brands/docker&logo=" + url.QueryEscape(logo),
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "testSubject",
+			Status:  "testStatus",
+			Logo:    logo,
+		}),
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&icon=brands/docker&logo=https://example.com/logo.svg",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "testSubject",
+			Status:  "testStatus",
+			Icon:    "brands/docker",
+		}),
+	})
+}
+
+func TestStaticBadgeServiceWithLinkQuery(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath: "/static?subject=testSubject&status=testStatus&link=https://example.com" +
+			"&subject-link=https://example.com/subject&status-link=javascript:alert(1)",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject:     "testSubject",
+			Status:      "testStatus",
+			Link:        "https://example.com",
+			SubjectLink: "https://example.com/subject",
+		}),
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&status-link=https://example.com/status&format=json",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "application/json",
+		},
+		expectedStatus: 200,
+		expectedBody: `{"subject":"testSubject","status":"testStatus","color":"#f7b137","style":"classic",` +
+			`"statusLink":"https://example.com/status"}`,
+	})
+}
+
+func TestStaticBadgeServiceWithPNGPathSuffix(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static.png?subject=testSubject&status=testStatus&color=ff0000",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/png",
+		},
+		expectedStatus: 200,
+		expectedBody: createPNGBadge(&badge.Params{
+			Subject: "testSubject",
+			Status:  "testStatus",
+			Color:   "ff0000",
+		}),
+	})
+}
+
+func TestStaticBadgeServiceWithFormatQuery(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&icon=brands/docker&format=png",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/png",
+		},
+		expectedStatus: 200,
+		expectedBody: createPNGBadge(&badge.Params{
+			Subject: "testSubject",
+			Status:  "testStatus",
+			Icon:    "brands/docker",
+		}),
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&format=svg",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "testSubject",
+			Status:  "testStatus",
+		}),
+	})
+}
+
+func TestStaticBadgeServiceWithJSONFormat(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&color=RED&icon=brands/docker&style=flat&format=json",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "application/json",
+		},
+		expectedStatus: 200,
+		expectedBody:   `{"subject":"testSubject","status":"testStatus","color":"red","style":"flat","icon":"brands/docker"}`,
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&color=badColor&style=semaphoreci",
+		requestHeaders: map[string]string{
+			"Accept": "text/html;q=0.9, application/json;q=0.8",
+		},
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "application/json",
+		},
+		expectedStatus: 200,
+		expectedBody:   `{"subject":"TESTSUBJECT","status":"TESTSTATUS","color":"#f7b137","style":"semaphoreci"}`,
+	})
+}
+
+func TestStaticBadgeServiceWithBadFormatQuery(t *testing.T) {
+	t.Parallel()
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?subject=testSubject&status=testStatus&format=gif",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "aegis",
+			Status:  "bad request",
+		}),
+	})
+}
+
+func TestStaticBadgeServiceWithSegmentQuery(t *testing.T) {
+	t.Parallel()
+
+	segments := []badge.Segment{
+		{Text: "build"},
+		{Text: "passing", Color: "green"},
+		{Text: "coverage"},
+		{Text: "87%", Color: "#dfb317"},
+	}
+	generatedBadge, err := badge.CreateSegments(&badge.SegmentParams{Segments: segments, Icon: "brands/docker"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?segment=build&segment=passing%7Cgreen&segment=coverage&segment=87%25%7C%23dfb317&icon=brands/docker",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody:   generatedBadge,
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?segment=build&segment=passing%7Cgreen&segment=go%7C&segment=1.22%7CbadColor&format=json",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "application/json",
+		},
+		expectedStatus: 200,
+		expectedBody: `{"segments":[{"text":"build"},{"text":"passing","color":"green"},{"text":"go"},` +
+			`{"text":"1.22","color":"#f7b137"}],"style":"classic"}`,
+	})
+	runHTTPTest(t, httpTestCase{
+		requestMethod: "GET",
+		requestPath:   "/static?segment=build&segment=passing%7Cgreen&style=social",
+		expectedHeaders: map[string]string{
+			"Cache-Control": "public, max-age=3600, s-maxage=3600",
+			"Content-Type":  "image/svg+xml;utf-8",
+		},
+		expectedStatus: 200,
+		expectedBody: createBadge(&badge.Params{
+			Subject: "aegis",
+			Status:  "bad request",
+		}),
+	})
+}
+
 func TestStaticBadgeServiceWithBadHTTPMethods(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+// templateExt is the file extension of badge templates
+const templateExt = ".tmpl"
+
+// loadTemplates validates the SVG templates in the given directory as badge styles named after their files
+// (eg. "<DIR>/corporate.tmpl" as the "corporate" style), returning the valid templates by style
+func loadTemplates(dir string) (map[badge.Style]string, error) {
+	templates := make(map[badge.Style]string)
+	err := registerDir(dir, "*"+templateExt, func(path string, content []byte) error {
+		style := badge.Style(strings.TrimSuffix(filepath.Base(path), templateExt))
+		if err := badge.ValidateStyle(style, string(content)); err != nil {
+			return err
+		}
+		templates[style] = string(content)
+		return nil
+	})
+
+	return templates, err
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tohjustin/aegis/pkg/badge"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	t.Parallel()
+
+	dir := newTestDir(t, map[string]string{
+		"service-corporate.tmpl": `<svg xmlns="http://www.w3.org/2000/svg" width="{{.TotalWidth}}"><g><path id="fill" fill="{{.Color}}"/></g><g><text id="subject">{{.Subject}}</text><text id="status">{{.Status}}</text></g></svg>`,
+		"service-invalid.tmpl":   `<svg xmlns="http://www.w3.org/2000/svg"><g><text id="subject">{{.Subject}}</text></g></svg>`,
+		"README.md":              "not a template",
+	})
+
+	styles, err := loadTemplates(dir)
+	assert.ErrorContains(t, err, filepath.Join(dir, "service-invalid.tmpl")+": Invalid template of badge style service-invalid")
+	assert.Equal(t, []badge.Style{"service-corporate"}, slices.Collect(maps.Keys(styles)))
+	assert.NotContains(t, badge.Styles(), badge.Style("service-corporate"))
+
+	_, err = loadTemplates(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
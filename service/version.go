@@ -0,0 +1,237 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// errNoReleases is returned when a repository has no (matching) releases
+	errNoReleases = errors.New("no releases found")
+	// errNoTags is returned when a repository has no tags
+	errNoTags = errors.New("no tags found")
+)
+
+// Badge colors of version badges
+const (
+	stableVersionColor     = "#007ec6"
+	prereleaseVersionColor = "#fe7d37"
+)
+
+// semanticVersionPattern matches semantic versions (https://semver.org) with an optional "v" prefix,
+// tolerating missing minor & patch versions (eg. "v1", "1.2")
+var semanticVersionPattern = regexp.MustCompile(`^[vV]?(0|[1-9]\d*)(?:\.(0|[1-9]\d*))?(?:\.(0|[1-9]\d*))?` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`)
+
+// semanticVersion represents a parsed semantic version
+type semanticVersion struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease []string
+}
+
+// parseSemanticVersion parses a semantic version, returns false if the string is not a semantic version
+func parseSemanticVersion(str string) (semanticVersion, bool) {
+	matches := semanticVersionPattern.FindStringSubmatch(str)
+	if matches == nil {
+		return semanticVersion{}, false
+	}
+
+	var numbers [3]int
+	for i, match := range matches[1:4] {
+		if match == "" {
+			continue
+		}
+		number, err := strconv.Atoi(match)
+		if err != nil {
+			return semanticVersion{}, false
+		}
+		numbers[i] = number
+	}
+	var prerelease []string
+	if matches[4] != "" {
+		prerelease = strings.Split(matches[4], ".")
+	}
+
+	return semanticVersion{
+		major:      numbers[0],
+		minor:      numbers[1],
+		patch:      numbers[2],
+		prerelease: prerelease,
+	}, true
+}
+
+func (version semanticVersion) isPrerelease() bool {
+	return len(version.prerelease) > 0
+}
+
+// compare returns -1, 0 or 1 if the version has lower, equal or higher precedence than the other version
+func (version semanticVersion) compare(other semanticVersion) int {
+	for _, pair := range [][2]int{
+		{version.major, other.major},
+		{version.minor, other.minor},
+		{version.patch, other.patch},
+	} {
+		if pair[0] != pair[1] {
+			return compareInts(pair[0], pair[1])
+		}
+	}
+
+	// A pre-release version has lower precedence than its associated normal version
+	switch {
+	case !version.isPrerelease() && !other.isPrerelease():
+		return 0
+	case !version.isPrerelease():
+		return 1
+	case !other.isPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(version.prerelease) && i < len(other.prerelease); i++ {
+		a, b := version.prerelease[i], other.prerelease[i]
+		if a == b {
+			continue
+		}
+		aNumber, aErr := strconv.Atoi(a)
+		bNumber, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			return compareInts(aNumber, bNumber)
+		case aErr == nil:
+			// Numeric identifiers have lower precedence than alphanumeric identifiers
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			return strings.Compare(a, b)
+		}
+	}
+
+	return compareInts(len(version.prerelease), len(other.prerelease))
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// latestVersion returns the tag with the highest semantic version, returns false if there are no
+// (matching) semantic versions
+func latestVersion(tags []string, includePrereleases bool) (string, bool) {
+	var latestTag string
+	var latest semanticVersion
+	found := false
+	for _, tag := range tags {
+		version, ok := parseSemanticVersion(tag)
+		if !ok || (version.isPrerelease() && !includePrereleases) {
+			continue
+		}
+		if !found || version.compare(latest) > 0 {
+			latestTag, latest, found = tag, version, true
+		}
+	}
+
+	return latestTag, found
+}
+
+// versionQuery holds the query parameters of version badges
+type versionQuery struct {
+	includePrereleases bool
+	prefix             string
+}
+
+// parseVersionQuery parses the `include_prereleases` & `prefix` query parameters of version badges
+func parseVersionQuery(query url.Values) (versionQuery, error) {
+	var result versionQuery
+
+	if _, ok := query["include_prereleases"]; ok {
+		switch value := query.Get("include_prereleases"); value {
+		case "", "true", "1":
+			result.includePrereleases = true
+		case "false", "0":
+		default:
+			return result, fmt.Errorf("invalid include_prereleases value: %s", value)
+		}
+	}
+	switch prefix := query.Get("prefix"); prefix {
+	case "", "v", "none":
+		result.prefix = prefix
+	default:
+		return result, fmt.Errorf("invalid prefix value: %s", prefix)
+	}
+
+	return result, nil
+}
+
+// formatVersion formats a version with the given prefix option ("v" adds a "v" prefix to semantic
+// versions, "none" strips it & "" leaves the version as is)
+func formatVersion(version string, prefix string) string {
+	if _, ok := parseSemanticVersion(version); !ok {
+		return version
+	}
+
+	switch prefix {
+	case "v":
+		return "v" + strings.TrimLeft(version, "vV")
+	case "none":
+		return strings.TrimLeft(version, "vV")
+	default:
+		return version
+	}
+}
+
+// versionColor returns the badge color for a version, distinguishing pre-releases from stable versions
+func versionColor(version string) string {
+	if semver, ok := parseSemanticVersion(version); ok && semver.isPrerelease() {
+		return prereleaseVersionColor
+	}
+
+	return stableVersionColor
+}
+
+// repositoryRelease represents a release of a repository
+type repositoryRelease struct {
+	tagName      string
+	isDraft      bool
+	isPrerelease bool
+}
+
+// latestRelease returns the tag of the first published release, expects releases to be sorted from
+// newest to oldest
+func latestRelease(releases []repositoryRelease, includePrereleases bool) (string, error) {
+	for _, release := range releases {
+		if release.isDraft || (release.isPrerelease && !includePrereleases) {
+			continue
+		}
+		return release.tagName, nil
+	}
+
+	return "", errNoReleases
+}
+
+// latestTag returns the tag with the highest semantic version, falling back to pre-releases & then to
+// the first tag if there are no matching semantic versions. Expects tags to be sorted from newest to oldest
+func latestTag(tags []string, includePrereleases bool) (string, error) {
+	if len(tags) == 0 {
+		return "", errNoTags
+	}
+	if tag, ok := latestVersion(tags, includePrereleases); ok {
+		return tag, nil
+	}
+	if tag, ok := latestVersion(tags, true); ok {
+		return tag, nil
+	}
+
+	return tags[0], nil
+}
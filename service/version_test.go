@@ -0,0 +1,139 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSemanticVersion(t *testing.T) {
+	t.Parallel()
+
+	version, ok := parseSemanticVersion("v1.2.3-rc.1+build.5")
+	assert.True(t, ok)
+	assert.Equal(t, semanticVersion{major: 1, minor: 2, patch: 3, prerelease: []string{"rc", "1"}}, version)
+
+	version, ok = parseSemanticVersion("2.1")
+	assert.True(t, ok)
+	assert.Equal(t, semanticVersion{major: 2, minor: 1}, version)
+
+	for _, str := range []string{"", "nightly", "v1.2.3.4", "1.02.3", "release-1.0"} {
+		_, ok := parseSemanticVersion(str)
+		assert.False(t, ok, str)
+	}
+}
+
+func TestCompareSemanticVersions(t *testing.T) {
+	t.Parallel()
+
+	// Ordered by increasing precedence, see https://semver.org/#spec-item-11
+	versions := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"v1.9.0",
+		"1.10.0",
+		"2.0.0",
+	}
+	for i := 0; i < len(versions)-1; i++ {
+		a, _ := parseSemanticVersion(versions[i])
+		b, _ := parseSemanticVersion(versions[i+1])
+		assert.Equal(t, -1, a.compare(b), "%s < %s", versions[i], versions[i+1])
+		assert.Equal(t, 1, b.compare(a), "%s > %s", versions[i+1], versions[i])
+		assert.Equal(t, 0, a.compare(a), "%s = %s", versions[i], versions[i])
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	t.Parallel()
+
+	tag, err := latestTag([]string{"v1.9.0", "v1.10.0-rc.1", "v1.10.0-beta", "nightly"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.9.0", tag)
+
+	tag, err = latestTag([]string{"v1.9.0", "v1.10.0-rc.1", "v1.10.0-beta", "nightly"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10.0-rc.1", tag)
+
+	tag, err = latestTag([]string{"v1.0.0-beta", "nightly"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.0-beta", tag)
+
+	tag, err = latestTag([]string{"nightly", "stable"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "nightly", tag)
+
+	_, err = latestTag(nil, false)
+	assert.Equal(t, errNoTags, err)
+}
+
+func TestLatestRelease(t *testing.T) {
+	t.Parallel()
+
+	releases := []repositoryRelease{
+		{tagName: "v3.0.0", isDraft: true},
+		{tagName: "v2.0.0-rc.1", isPrerelease: true},
+		{tagName: "v1.1.0"},
+	}
+
+	release, err := latestRelease(releases, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1.0", release)
+
+	release, err = latestRelease(releases, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0-rc.1", release)
+
+	_, err = latestRelease(releases[:2], false)
+	assert.Equal(t, errNoReleases, err)
+}
+
+func TestParseVersionQuery(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		query    string
+		expected versionQuery
+		isValid  bool
+	}{
+		{query: "", expected: versionQuery{}, isValid: true},
+		{query: "include_prereleases", expected: versionQuery{includePrereleases: true}, isValid: true},
+		{query: "include_prereleases=false&prefix=v", expected: versionQuery{prefix: "v"}, isValid: true},
+		{query: "prefix=none", expected: versionQuery{prefix: "none"}, isValid: true},
+		{query: "prefix=x", isValid: false},
+		{query: "include_prereleases=maybe", isValid: false},
+	}
+
+	for _, testCase := range testCases {
+		query, err := url.ParseQuery(testCase.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := parseVersionQuery(query)
+		if !testCase.isValid {
+			assert.Error(t, err, testCase.query)
+			continue
+		}
+		assert.NoError(t, err, testCase.query)
+		assert.Equal(t, testCase.expected, result, testCase.query)
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "v1.2.3", formatVersion("1.2.3", "v"))
+	assert.Equal(t, "v1.2.3", formatVersion("v1.2.3", "v"))
+	assert.Equal(t, "1.2.3", formatVersion("v1.2.3", "none"))
+	assert.Equal(t, "v1.2.3", formatVersion("v1.2.3", ""))
+	assert.Equal(t, "nightly", formatVersion("nightly", "v"))
+	assert.Equal(t, "#007ec6", versionColor("v1.2.3"))
+	assert.Equal(t, "#fe7d37", versionColor("v1.2.3-rc.1"))
+	assert.Equal(t, "#007ec6", versionColor("nightly"))
+}